@@ -0,0 +1,119 @@
+package http
+
+import (
+	"strings"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type APIKeyHandler struct {
+	service ports.APIKeyService
+}
+
+func NewAPIKeyHandler(service ports.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// APIKeyAuth: ใช้แทน jwtMiddleware ได้ทั้งหมด
+// ถ้ามี X-API-Key หรือ "Authorization: Bearer brms_..." จะตรวจเป็น API Key แล้วแปะ claims แบบเดียวกับ JWT
+// ไว้ใน Locals("user") (Handler เดิมอ่าน user_id / role ได้เหมือนเดิม) ไม่เช่นนั้นส่งต่อให้ jwt
+func APIKeyAuth(keys ports.APIKeyService, jwtMiddleware fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		raw := strings.TrimSpace(c.Get("X-API-Key"))
+		if raw == "" {
+			if auth := c.Get(fiber.HeaderAuthorization); strings.HasPrefix(auth, "Bearer "+domain.APIKeyPrefix) {
+				raw = strings.TrimPrefix(auth, "Bearer ")
+			}
+		}
+		if raw == "" {
+			return jwtMiddleware(c)
+		}
+
+		key, user, err := keys.Authenticate(raw, clientInfo(c))
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		c.Locals("user", &jwt.Token{Valid: true, Claims: jwt.MapClaims{
+			"user_id":    float64(user.ID),
+			"username":   user.Username,
+			"role":       user.Role,
+			"api_key_id": float64(key.ID),
+		}})
+		c.Locals("api_key", key)
+		return c.Next()
+	}
+}
+
+// currentAPIKey: Key ที่ใช้เรียก Request นี้ (nil = Login ด้วย JWT ปกติ)
+func currentAPIKey(c *fiber.Ctx) *domain.APIKey {
+	key, _ := c.Locals("api_key").(*domain.APIKey)
+	return key
+}
+
+// GET /api/me/api-keys
+func (h *APIKeyHandler) GetMine(c *fiber.Ctx) error {
+	userID, _ := currentUserID(c)
+	keys, err := h.service.GetMine(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(keys)
+}
+
+// POST /api/me/api-keys
+// {"name": "timetable-sync", "scopes": ["bookings:read"], "expires_in_days": 90}
+// ตัว Key จริงแสดงครั้งเดียวในคำตอบนี้
+func (h *APIKeyHandler) Create(c *fiber.Ctx) error {
+	var input ports.APIKeyInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	userID, _ := currentUserID(c)
+	key, raw, err := h.service.Create(userID, input, clientInfo(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "API key created. Copy it now, it will not be shown again.",
+		"key":     raw,
+		"api_key": key,
+	})
+}
+
+// DELETE /api/me/api-keys/:id
+func (h *APIKeyHandler) RevokeMine(c *fiber.Ctx) error {
+	return h.revoke(c, false)
+}
+
+// GET /api/api-keys
+// ผู้ดูแลดู API Key ของผู้ใช้ทุกคน
+func (h *APIKeyHandler) GetAll(c *fiber.Ctx) error {
+	keys, err := h.service.GetAll()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(keys)
+}
+
+// DELETE /api/api-keys/:id
+func (h *APIKeyHandler) Revoke(c *fiber.Ctx) error {
+	return h.revoke(c, true)
+}
+
+func (h *APIKeyHandler) revoke(c *fiber.Ctx, asAdmin bool) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	actorID, _ := currentUserID(c)
+	if err := h.service.Revoke(uint(id), actorID, asAdmin, clientInfo(c)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "API key revoked"})
+}
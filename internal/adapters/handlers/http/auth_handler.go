@@ -1,6 +1,9 @@
 package http
 
 import (
+	"errors"
+	"math"
+	"strconv"
 	"tunorth-brms-backend/internal/core/domain"
 	"tunorth-brms-backend/internal/core/ports"
 
@@ -10,12 +13,14 @@ import (
 
 type AuthHandler struct {
 	service        ports.AuthService
+	tokens         ports.TokenService
 	logService     ports.LogService
 	settingService ports.SettingService
+	impersonation  ports.ImpersonationService
 }
 
-func NewAuthHandler(service ports.AuthService, logService ports.LogService, settingService ports.SettingService) *AuthHandler {
-	return &AuthHandler{service: service, logService: logService, settingService: settingService}
+func NewAuthHandler(service ports.AuthService, tokens ports.TokenService, logService ports.LogService, settingService ports.SettingService, impersonation ports.ImpersonationService) *AuthHandler {
+	return &AuthHandler{service: service, tokens: tokens, logService: logService, settingService: settingService, impersonation: impersonation}
 }
 
 // POST /api/register
@@ -32,7 +37,6 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		Password   string `json:"password"` // ตรงนี้ไม่มี - แล้ว รับค่าได้ปกติ
 		FullName   string `json:"full_name"`
 		Department string `json:"department"`
-		Role       string `json:"role"`
 		Email      string `json:"email"`
 		Tel        string `json:"tel"`
 	}
@@ -44,13 +48,12 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
 	}
 
-	// 3. ย้ายข้อมูลจาก Request ไปใส่ใน domain.User
+	// 3. ย้ายข้อมูลจาก Request ไปใส่ใน domain.User (ไม่รับ role จากผู้สมัคร Service จะกำหนดเป็น user เสมอ)
 	user := domain.User{
 		Username:   req.Username,
 		Password:   req.Password, // ส่งรหัสผ่านไปให้ Service Hash ต่อ
 		FullName:   req.FullName,
 		Department: req.Department,
-		Role:       req.Role,
 		Email:      req.Email,
 		Tel:        req.Tel,
 	}
@@ -60,7 +63,15 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "User registered successfully"})
+	message := "User registered successfully"
+	switch user.Status {
+	case domain.UserStatusPendingVerification:
+		message = "Registration successful, please check your email to verify your account"
+	case domain.UserStatusPendingApproval:
+		message = "Registration successful, your account is awaiting administrator approval"
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": message, "status": user.Status})
 }
 
 // POST /api/login
@@ -74,7 +85,20 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
 	}
 
-	token, userID, err := h.service.Login(input.Username, input.Password)
+	tokens, userID, err := h.service.Login(input.Username, input.Password, clientInfo(c))
+	var twoFactor *ports.TwoFactorRequiredError
+	if errors.As(err, &twoFactor) {
+		// รหัสผ่านถูกต้อง: ส่ง challenge_token พร้อมรหัส 6 หลักไปที่ POST /api/login/2fa
+		return c.JSON(fiber.Map{
+			"two_factor_required": true,
+			"enroll_required":     twoFactor.EnrollRequired,
+			"challenge_token":     twoFactor.ChallengeToken,
+			"expires_in":          twoFactor.ExpiresIn,
+		})
+	}
+	if locked, resp := loginLocked(c, err); locked {
+		return resp
+	}
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -83,11 +107,62 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	go h.logService.LogAction(userID, "LOGIN", "เข้าสู่ระบบสำเร็จ", c.IP(), c.Get("User-Agent"))
 
 	return c.JSON(fiber.Map{
-		"message": "Login successful",
-		"token":   token,
+		"message":            "Login successful",
+		"token":              tokens.AccessToken,
+		"refresh_token":      tokens.RefreshToken,
+		"expires_in":         tokens.ExpiresIn,
+		"refresh_expires_at": tokens.RefreshExpiresAt,
 	})
 }
 
+// loginLocked: ล้มเหลวบ่อยเกินไป ตอบ 429 พร้อม Retry-After (วินาที)
+func loginLocked(c *fiber.Ctx, err error) (bool, error) {
+	var locked *ports.LoginLockedError
+	if !errors.As(err, &locked) {
+		return false, nil
+	}
+	seconds := int(math.Ceil(locked.RetryAfter.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return true, c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": locked.Error(), "retry_after": seconds})
+}
+
+// POST /api/token/refresh
+// ส่ง refresh_token เดิมมา จะได้ Token คู่ใหม่ (refresh_token เดิมใช้ซ้ำไม่ได้อีก)
+func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
+	var input struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.BodyParser(&input); err != nil || input.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "refresh_token is required"})
+	}
+
+	tokens, err := h.tokens.Refresh(input.RefreshToken, clientInfo(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(tokens)
+}
+
+// POST /api/logout
+// เพิกถอน refresh_token (และทุกตัวที่หมุนต่อจาก Login ครั้งเดียวกัน)
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var input struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.BodyParser(&input); err != nil || input.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "refresh_token is required"})
+	}
+
+	userID, err := h.tokens.Revoke(input.RefreshToken)
+	if err != nil {
+		// ไม่บอกว่า token ผิด เพื่อให้ Logout ซ้ำได้โดยไม่ error
+		return c.JSON(fiber.Map{"message": "Logged out"})
+	}
+
+	go h.logService.LogAction(userID, "LOGOUT", "ออกจากระบบ", c.IP(), c.Get("User-Agent"))
+	return c.JSON(fiber.Map{"message": "Logged out"})
+}
+
 // GET /api/me
 func (h *AuthHandler) GetMe(c *fiber.Ctx) error {
 	// Extract user_id from Claims (middleware should set this)
@@ -103,7 +178,32 @@ func (h *AuthHandler) GetMe(c *fiber.Ctx) error {
     // Hide password
     u.Password = ""
 
-	return c.JSON(u)
+	// ผู้ดูแลกำลังสวมสิทธิ์: ให้ Frontend แสดงแถบเตือน / ปุ่มออกจากการสวมสิทธิ์
+	if impersonatorID := currentImpersonatorID(c); impersonatorID != 0 {
+		u.ImpersonatedBy, _ = h.impersonation.GetImpersonator(currentSessionID(c), impersonatorID)
+	}
+
+	return c.JSON(meResponse{User: u, TelegramChatID: u.TelegramChatID, LineUserID: u.LineUserID, PendingEmail: u.PendingEmail})
+}
+
+// meResponse ข้อมูลติดต่อส่วนตัวที่ domain.User ไม่ส่งออก (เห็นได้เฉพาะเจ้าของบัญชี)
+type meResponse struct {
+	*domain.User
+	TelegramChatID string `json:"telegram_chat_id"`
+	LineUserID     string `json:"line_user_id"`
+	PendingEmail   string `json:"pending_email,omitempty"`
+}
+
+// updateMeRequest ฟิลด์ที่ผู้ใช้แก้ไขเองได้ (domain.User ไม่รับ password จาก JSON)
+type updateMeRequest struct {
+	Password       string `json:"password"` // ว่าง = ไม่เปลี่ยน
+	FullName       string `json:"full_name"`
+	Department     string `json:"department"`
+	Email          string `json:"email"`
+	Tel            string `json:"tel"`
+	TelegramChatID string `json:"telegram_chat_id"`
+	LineUserID     string `json:"line_user_id"`
+	Language       string `json:"language"`
 }
 
 // PUT /api/me
@@ -112,14 +212,28 @@ func (h *AuthHandler) UpdateMe(c *fiber.Ctx) error {
 	claims := user.Claims.(jwt.MapClaims)
 	userID := uint(claims["user_id"].(float64))
 
-	var req domain.User
+	var req updateMeRequest
 	if err := c.BodyParser(&req); err != nil {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
-    }
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
 
-	if err := h.service.UpdateMe(userID, &req); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	updated, err := h.service.UpdateMe(userID, &domain.User{
+		Password:       req.Password,
+		FullName:       req.FullName,
+		Department:     req.Department,
+		Email:          req.Email,
+		Tel:            req.Tel,
+		TelegramChatID: req.TelegramChatID,
+		LineUserID:     req.LineUserID,
+		Language:       req.Language,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	// อีเมลใหม่ยังไม่เปลี่ยนจนกว่าจะกดลิงก์ในอีเมล
+	if updated.PendingEmail != "" {
+		return c.JSON(fiber.Map{"message": "Profile updated, check your new email address to confirm the change", "pending_email": updated.PendingEmail})
+	}
 	return c.JSON(fiber.Map{"message": "Profile updated successfully"})
 }
\ No newline at end of file
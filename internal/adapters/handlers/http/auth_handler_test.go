@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeProfileService เก็บค่าที่ Handler ส่งต่อให้ Service
+type fakeProfileService struct {
+	ports.AuthService
+	ports.UserService
+	received *domain.User
+}
+
+func (s *fakeProfileService) UpdateMe(userID uint, user *domain.User) (*domain.User, error) {
+	s.received = user
+	return user, nil
+}
+
+func (s *fakeProfileService) UpdateUser(id uint, user *domain.User) error {
+	s.received = user
+	return nil
+}
+
+func TestProfileUpdatesPassPasswordThrough(t *testing.T) {
+	service := &fakeProfileService{}
+	app := fiber.New()
+	asUser := func(c *fiber.Ctx) error {
+		c.Locals("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": float64(1), "role": domain.RoleAdmin}})
+		return c.Next()
+	}
+	app.Put("/me", asUser, NewAuthHandler(service, nil, nil, nil, nil).UpdateMe)
+	app.Put("/users/:id", asUser, NewUserHandler(service).UpdateUser)
+
+	for _, path := range []string{"/me", "/users/2"} {
+		service.received = nil
+		req := httptest.NewRequest(fiber.MethodPut, path, strings.NewReader(`{"full_name":"Somchai","password":"new-password","telegram_chat_id":"42"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("%s: status %d", path, resp.StatusCode)
+		}
+		if service.received == nil || service.received.Password != "new-password" {
+			t.Fatalf("%s: password not passed to the service: %+v", path, service.received)
+		}
+	}
+}
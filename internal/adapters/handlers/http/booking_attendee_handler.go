@@ -0,0 +1,105 @@
+package http
+
+import (
+	"errors"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BookingAttendeeHandler struct {
+	service ports.BookingAttendeeService
+}
+
+func NewBookingAttendeeHandler(service ports.BookingAttendeeService) *BookingAttendeeHandler {
+	return &BookingAttendeeHandler{service: service}
+}
+
+// GET /api/bookings/:id/attendees
+func (h *BookingAttendeeHandler) GetAttendees(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	attendees, err := h.service.GetAttendees(uint(id), userID)
+	if errors.Is(err, ports.ErrAttendeesForbidden) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(attendees)
+}
+
+// PUT /api/bookings/:id/attendees {"user_ids": [2, 5]} (แทนที่รายชื่อทั้งหมด)
+func (h *BookingAttendeeHandler) SetAttendees(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var req struct {
+		UserIDs []uint `json:"user_ids"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	if err := h.service.SetAttendees(uint(id), req.UserIDs, userID, clientInfo(c)); err != nil {
+		if err.Error() == "unauthorized" || err.Error() == "you do not have permission to edit this booking" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Attendees updated successfully"})
+}
+
+// POST /api/bookings/:id/rsvp {"status": "accepted" | "declined"}
+func (h *BookingAttendeeHandler) Respond(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	if err := h.service.Respond(uint(id), userID, req.Status, clientInfo(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Response recorded"})
+}
+
+// GET /api/me/invitations
+func (h *BookingAttendeeHandler) GetInvitations(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	invitations, err := h.service.GetInvitations(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(invitations)
+}
@@ -13,12 +13,12 @@ import (
 )
 
 type BookingHandler struct {
-	service        ports.BookingService
-	settingService ports.SettingService
+	service    ports.BookingService
+	cloudinary *storage.CloudinaryProvider
 }
 
-func NewBookingHandler(service ports.BookingService, settingService ports.SettingService) *BookingHandler {
-	return &BookingHandler{service: service, settingService: settingService}
+func NewBookingHandler(service ports.BookingService, cloudinary *storage.CloudinaryProvider) *BookingHandler {
+	return &BookingHandler{service: service, cloudinary: cloudinary}
 }
 
 // [GET] /api/bookings?start=...&end=...
@@ -44,10 +44,10 @@ func (h *BookingHandler) GetBookings(c *fiber.Ctx) error {
 					filtered = append(filtered, b)
 				}
 			}
-			return c.JSON(filtered)
+			return c.JSON(publicBookings(filtered))
 		}
 
-		return c.JSON(bookings)
+		return c.JSON(publicBookings(bookings))
 	}
 
 	// 2. ดึงข้อมูลทั้งหมดมาก่อน (เพื่อเตรียมกรอง)
@@ -72,7 +72,7 @@ func (h *BookingHandler) GetBookings(c *fiber.Ctx) error {
 				myBookings = append(myBookings, b)
 			}
 		}
-		return c.JSON(myBookings)
+		return c.JSON(publicBookings(myBookings))
 	}
 
 	// 4. กรณี Admin หรือไม่ส่งอะไรมาเลย -> คืนค่าทั้งหมด
@@ -85,10 +85,36 @@ func (h *BookingHandler) GetBookings(c *fiber.Ctx) error {
 				filtered = append(filtered, b)
 			}
 		}
-		return c.JSON(filtered)
+		return c.JSON(publicBookings(filtered))
 	}
 
-	return c.JSON(bookings)
+	return c.JSON(publicBookings(bookings))
+}
+
+// publicBookings: GET /api/bookings ไม่ต้อง Login จึงตัดข้อมูลบัญชีของผู้จอง/ผู้อนุมัติให้เหลือเท่าที่ปฏิทินใช้
+func publicBookings(bookings []domain.Booking) []domain.Booking {
+	for i := range bookings {
+		bookings[i].User = bookings[i].User.Public()
+		if bookings[i].Approver != nil {
+			approver := bookings[i].Approver.Public()
+			bookings[i].Approver = &approver
+		}
+	}
+	return bookings
+}
+
+// GET /api/bookings/:id
+func (h *BookingHandler) GetBooking(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	booking, err := h.service.GetBookingByID(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Booking not found"})
+	}
+	return c.JSON(booking)
 }
 
 // POST /api/bookings (รองรับ File Upload)
@@ -103,10 +129,15 @@ func (h *BookingHandler) CreateBooking(c *fiber.Ctx) error {
 	startTime, _ := time.Parse(layout, c.FormValue("start_time"))
 	endTime, _ := time.Parse(layout, c.FormValue("end_time"))
 
+	// ผู้จองคือเจ้าของ Token เสมอ (ไม่รับ user_id จาก Form เพื่อกันการจองแทนผู้อื่น)
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
 	// สร้าง Object Booking
 	booking := domain.Booking{
-		// UserID จะรับจาก Form หรือ Token ก็ได้ (ในที่นี้รับจาก Form เพื่อความง่ายตาม Code เดิม)
-		UserID:      uint(1), // Default ไว้ก่อน หรือแปลง c.FormValue("user_id")
+		UserID:      userID,
 		RoomID:      uint(roomID),
 		Subject:     c.FormValue("subject"),
 		Department:  c.FormValue("department"),
@@ -118,34 +149,23 @@ func (h *BookingHandler) CreateBooking(c *fiber.Ctx) error {
 		ResourceText: c.FormValue("resource_text"),
 		Status:      "pending",
 	}
-	
-	// แก้ UserID ให้ถูกต้อง (ถ้าส่งมา)
-	if uid, err := strconv.Atoi(c.FormValue("user_id")); err == nil {
-		booking.UserID = uint(uid)
-	}
 
 	// 2. จัดการไฟล์อัปโหลด (Layout Image)
 	file, err := c.FormFile("layout_image")
 	if err == nil {
-		// Retrieve Cloudinary Settings
-		cloudName := h.settingService.GetSettingValue("cloudinary_cloud_name")
-		apiKey := h.settingService.GetSettingValue("cloudinary_api_key")
-		apiSecret := h.settingService.GetSettingValue("cloudinary_api_secret")
-
 		// Check if Cloudinary is configured
-		if cloudName != "" && apiKey != "" && apiSecret != "" {
-			adapter, err := storage.NewCloudinaryAdapter(cloudName, apiKey, apiSecret)
+		adapter, err := h.cloudinary.Adapter()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to init Cloudinary: " + err.Error()})
+		}
+		if adapter != nil {
+			// Upload to Cloudinary
+			url, err := adapter.Upload(file, fmt.Sprintf("booking_%d", time.Now().UnixNano()))
 			if err == nil {
-				// Upload to Cloudinary
-				url, err := adapter.Upload(file, fmt.Sprintf("booking_%d", time.Now().UnixNano()))
-				if err == nil {
-					booking.LayoutImage = url
-				} else {
-					// Join error if upload fails, or fallback? For safety let's return error
-					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cloudinary Upload Failed: " + err.Error()})
-				}
+				booking.LayoutImage = url
 			} else {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to init Cloudinary: " + err.Error()})
+				// Join error if upload fails, or fallback? For safety let's return error
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cloudinary Upload Failed: " + err.Error()})
 			}
 		} else {
 			// Fallback: Local Storage
@@ -165,7 +185,7 @@ func (h *BookingHandler) CreateBooking(c *fiber.Ctx) error {
 	}
 
 	// 3. เรียก Service บันทึกข้อมูล
-	if err := h.service.CreateBooking(&booking); err != nil {
+	if err := h.service.CreateBooking(&booking, clientInfo(c)); err != nil {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -192,13 +212,13 @@ func (h *BookingHandler) UpdateStatus(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid status"})
 	}
 
-	// ดึง User ID ของคนกด (Admin) จาก Token
-	// (ใน Workshop นี้เราสมมติว่า Middleware แปะ user_id มาให้ หรือเราจะใช้จาก Claims ก็ได้)
-	// เพื่อความง่ายตอนนี้เราจะ Hardcode หรือดึงจาก Token ถ้าทำ Middleware แล้ว
-	// สมมติ admin_id = 1 ไปก่อนสำหรับการทดสอบ
-	adminID := uint(1)
+	// ผู้อนุมัติคือเจ้าของ Token
+	approverID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
 
-	if err := h.service.UpdateBookingStatus(uint(id), input.Status, adminID); err != nil {
+	if err := h.service.UpdateBookingStatus(uint(id), input.Status, approverID, clientInfo(c)); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -241,8 +261,6 @@ func (h *BookingHandler) UpdateBooking(c *fiber.Ctx) error {
 
     // Let's try direct mapping to domain.Booking struct first, usually easier.
     // But domain.Booking has many fields.
-    
-    fmt.Printf("Updating Booking ID: %d with data: %+v\n", id, input) // Debug Log
 
     // Use domain booking for simplicity
     booking := domain.Booking{
@@ -267,7 +285,10 @@ func (h *BookingHandler) UpdateBooking(c *fiber.Ctx) error {
         }
     }
 
-    if err := h.service.UpdateBooking(uint(id), &booking, actorID); err != nil {
+    if err := h.service.UpdateBooking(uint(id), &booking, actorID, clientInfo(c)); err != nil {
+		if err.Error() == "unauthorized" || err.Error() == "you do not have permission to edit this booking" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
     }
     
@@ -302,7 +323,7 @@ func (h *BookingHandler) DeleteBooking(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token claims"})
 	}
 
-    if err := h.service.DeleteBooking(uint(id), actorID); err != nil {
+    if err := h.service.DeleteBooking(uint(id), actorID, clientInfo(c)); err != nil {
 		if err.Error() == "unauthorized" || err.Error() == "you do not have permission to delete this booking" {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
 		}
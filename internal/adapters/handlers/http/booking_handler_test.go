@@ -0,0 +1,91 @@
+package http
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type fakeBookingService struct {
+	ports.BookingService
+	bookings []domain.Booking
+	client   ports.ClientInfo
+}
+
+func (s *fakeBookingService) GetAllBookings() ([]domain.Booking, error) {
+	return s.bookings, nil
+}
+
+func (s *fakeBookingService) DeleteBooking(id uint, actorID uint, client ports.ClientInfo) error {
+	s.client = client
+	return nil
+}
+
+func TestGetBookingsHidesAccountDetails(t *testing.T) {
+	organiser := domain.User{
+		ID:             1,
+		FullName:       "Somchai Jaidee",
+		Email:          "somchai@tu.ac.th",
+		TelegramChatID: "tg-123456",
+		LineUserID:     "U-line-abcdef",
+		PendingEmail:   "new@tu.ac.th",
+		AuthSource:     domain.AuthSourceLDAP,
+		Status:         domain.UserStatusActive,
+	}
+	approver := organiser
+	service := &fakeBookingService{bookings: []domain.Booking{{ID: 1, UserID: 1, User: organiser, Approver: &approver, Subject: "Meeting"}}}
+	app := fiber.New()
+	app.Get("/bookings", NewBookingHandler(service, nil).GetBookings)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/bookings", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	body := string(raw)
+
+	if !strings.Contains(body, "Somchai Jaidee") {
+		t.Fatalf("organiser name missing from %s", body)
+	}
+	for _, private := range []string{"tg-123456", "U-line-abcdef", "new@tu.ac.th", `"auth_source":"ldap"`, `"status":"active"`} {
+		if strings.Contains(body, private) {
+			t.Errorf("GET /bookings exposes %s: %s", private, body)
+		}
+	}
+}
+
+func TestDeleteBookingPassesImpersonatorToService(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		claims jwt.MapClaims
+		want   uint
+	}{
+		{name: "own token", claims: jwt.MapClaims{"user_id": float64(5), "role": domain.RoleUser}},
+		{name: "impersonation token", claims: jwt.MapClaims{"user_id": float64(5), "role": domain.RoleUser, "imp": float64(1)}, want: 1},
+	} {
+		service := &fakeBookingService{}
+		app := fiber.New()
+		app.Delete("/bookings/:id", func(c *fiber.Ctx) error {
+			c.Locals("user", &jwt.Token{Claims: tc.claims})
+			return c.Next()
+		}, NewBookingHandler(service, nil).DeleteBooking)
+
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodDelete, "/bookings/7", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("%s: status = %d", tc.name, resp.StatusCode)
+		}
+		// Service ใช้ ImpersonatorID นี้บันทึก Log ของการลบ
+		if service.client.ImpersonatorID != tc.want {
+			t.Errorf("%s: impersonator = %d, want %d", tc.name, service.client.ImpersonatorID, tc.want)
+		}
+	}
+}
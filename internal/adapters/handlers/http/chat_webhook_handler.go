@@ -0,0 +1,106 @@
+package http
+
+import (
+	"errors"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ChatWebhookHandler struct {
+	service ports.ChatWebhookService
+}
+
+func NewChatWebhookHandler(service ports.ChatWebhookService) *ChatWebhookHandler {
+	return &ChatWebhookHandler{service: service}
+}
+
+// GET /api/chat-webhooks
+func (h *ChatWebhookHandler) GetWebhooks(c *fiber.Ctx) error {
+	webhooks, err := h.service.GetWebhooks()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(webhooks)
+}
+
+// chatWebhookWithURL: domain.ChatWebhook ไม่ส่ง URL ออกทาง JSON ใช้รับค่าตอนสร้าง/แก้ และส่งกลับตอนสร้างครั้งเดียว
+type chatWebhookWithURL struct {
+	domain.ChatWebhook
+	URL string `json:"url"`
+}
+
+// POST /api/chat-webhooks
+func (h *ChatWebhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	req := chatWebhookWithURL{ChatWebhook: domain.ChatWebhook{IsActive: true}}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	webhook := req.ChatWebhook
+	webhook.URL = req.URL
+	actorID, _ := currentUserID(c)
+	if err := h.service.CreateWebhook(&webhook, actorID); err != nil {
+		return c.Status(chatWebhookErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(chatWebhookWithURL{ChatWebhook: webhook, URL: webhook.URL})
+}
+
+// PUT /api/chat-webhooks/:id
+func (h *ChatWebhookHandler) UpdateWebhook(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var req chatWebhookWithURL
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	webhook := req.ChatWebhook
+	webhook.URL = req.URL // ว่าง = ใช้ URL เดิม
+
+	actorID, _ := currentUserID(c)
+	if err := h.service.UpdateWebhook(uint(id), &webhook, actorID); err != nil {
+		return c.Status(chatWebhookErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Chat webhook updated successfully"})
+}
+
+// DELETE /api/chat-webhooks/:id
+func (h *ChatWebhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	actorID, _ := currentUserID(c)
+	if err := h.service.DeleteWebhook(uint(id), actorID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Chat webhook deleted successfully"})
+}
+
+// POST /api/chat-webhooks/:id/test
+func (h *ChatWebhookHandler) Test(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	actorID, _ := currentUserID(c)
+	if err := h.service.Test(uint(id), actorID); err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Test message sent"})
+}
+
+// chatWebhookErrorStatus: ไม่ได้ตั้งกุญแจเข้ารหัสบนเซิร์ฟเวอร์ = 409 นอกนั้นเป็นข้อมูลไม่ถูกต้อง
+func chatWebhookErrorStatus(err error) int {
+	if errors.Is(err, ports.ErrChatWebhookEncryptionKeyMissing) {
+		return fiber.StatusConflict
+	}
+	return fiber.StatusBadRequest
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type fakeChatWebhookService struct {
+	ports.ChatWebhookService
+	webhooks map[uint]*domain.ChatWebhook
+}
+
+func (s *fakeChatWebhookService) CreateWebhook(webhook *domain.ChatWebhook, actorID uint) error {
+	webhook.ID = uint(len(s.webhooks) + 1)
+	webhook.Redact(webhook.URL)
+	stored := *webhook
+	s.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+func (s *fakeChatWebhookService) GetWebhooks() ([]domain.ChatWebhook, error) {
+	var webhooks []domain.ChatWebhook
+	for _, w := range s.webhooks {
+		webhook := *w
+		webhook.Redact(webhook.URL)
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+func TestChatWebhookURLOnlyReturnedOnce(t *testing.T) {
+	const hookURL = "https://hooks.slack.com/services/T000/B000/XXXX"
+	service := &fakeChatWebhookService{webhooks: map[uint]*domain.ChatWebhook{}}
+	handler := NewChatWebhookHandler(service)
+	app := fiber.New()
+	app.Post("/chat-webhooks", handler.CreateWebhook)
+	app.Get("/chat-webhooks", handler.GetWebhooks)
+
+	req := httptest.NewRequest(fiber.MethodPost, "/chat-webhooks", strings.NewReader(`{"name":"Ops","platform":"slack","url":"`+hookURL+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created map[string]interface{}
+	raw, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(raw, &created); err != nil {
+		t.Fatal(err)
+	}
+	if created["url"] != hookURL {
+		t.Fatalf("create response = %s, want the URL", raw)
+	}
+	if service.webhooks[1].URL != hookURL {
+		t.Fatalf("stored URL = %q", service.webhooks[1].URL)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/chat-webhooks", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ = io.ReadAll(resp.Body)
+	if strings.Contains(string(raw), "XXXX") {
+		t.Fatalf("list response leaks the URL: %s", raw)
+	}
+	var list []map[string]interface{}
+	if err := json.Unmarshal(raw, &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0]["has_url"] != true || list[0]["url_host"] != "hooks.slack.com" {
+		t.Fatalf("list response = %s, want has_url and url_host", raw)
+	}
+}
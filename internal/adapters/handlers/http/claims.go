@@ -0,0 +1,63 @@
+package http
+
+import (
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// currentUserID ดึง user_id จาก JWT ที่ jwtMiddleware แปะไว้ใน Locals("user")
+func currentUserID(c *fiber.Ctx) (uint, bool) {
+	userCtx, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return 0, false
+	}
+	claims, ok := userCtx.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, false
+	}
+	idFloat, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, false
+	}
+	return uint(idFloat), true
+}
+
+// currentSessionID ดึง sid (Session ของการ Login นี้) จาก JWT
+func currentSessionID(c *fiber.Ctx) string {
+	userCtx, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return ""
+	}
+	claims, _ := userCtx.Claims.(jwt.MapClaims)
+	sid, _ := claims["sid"].(string)
+	return sid
+}
+
+// clientInfo IP และ User-Agent ของผู้เรียก (copy ค่าออกมา เพราะ Fiber ใช้ buffer ซ้ำหลังจบ request)
+// พร้อมผู้ดูแลที่สวมสิทธิ์อยู่ เพื่อให้ Log ของ Service บันทึกผู้ทำรายการจริง
+func clientInfo(c *fiber.Ctx) ports.ClientInfo {
+	return ports.ClientInfo{IP: utils.CopyString(c.IP()), UserAgent: utils.CopyString(c.Get(fiber.HeaderUserAgent)), ImpersonatorID: currentImpersonatorID(c)}
+}
+
+// ActiveTokenHandler ใช้เป็น SuccessHandler ของ jwtMiddleware
+// ลายเซ็นถูกต้องแล้วยังต้องตรวจว่าผู้ใช้ยังอยู่ และ Token ไม่ถูกเพิกถอน (เปลี่ยนรหัสผ่าน/role/ถูกลบ/Session ถูกออกจากระบบ)
+func ActiveTokenHandler(tokens ports.TokenService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userCtx, ok := c.Locals("user").(*jwt.Token)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+		claims, _ := userCtx.Claims.(jwt.MapClaims)
+		idFloat, _ := claims["user_id"].(float64)
+		version, _ := claims["ver"].(float64)
+		sessionID, _ := claims["sid"].(string)
+
+		if err := tokens.ValidateAccess(uint(idFloat), int(version), sessionID); err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Next()
+	}
+}
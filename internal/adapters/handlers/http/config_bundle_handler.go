@@ -0,0 +1,113 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+	"gopkg.in/yaml.v3"
+)
+
+type ConfigBundleHandler struct {
+	service ports.ConfigBundleService
+}
+
+func NewConfigBundleHandler(service ports.ConfigBundleService) *ConfigBundleHandler {
+	return &ConfigBundleHandler{service: service}
+}
+
+// GET /api/settings/export?format=json|yaml&include=rooms,resources
+// ดาวน์โหลดค่าตั้งค่า (ไม่รวม secret) ไว้นำเข้าที่ instance อื่น
+func (h *ConfigBundleHandler) Export(c *fiber.Ctx) error {
+	include := map[string]bool{}
+	for _, part := range strings.Split(c.Query("include"), ",") {
+		include[strings.TrimSpace(part)] = true
+	}
+
+	actorID, _ := currentUserID(c)
+	bundle, err := h.service.Export(include["rooms"], include["resources"], actorID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	filename := "brms-config-" + time.Now().Format("20060102")
+	if c.Query("format") == "yaml" {
+		out, err := yaml.Marshal(bundle)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		c.Set(fiber.HeaderContentType, "application/yaml; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.yaml"`, filename))
+		return c.Send(out)
+	}
+
+	out, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+	return c.Send(out)
+}
+
+// POST /api/settings/import?dry_run=true
+// รับไฟล์ทาง multipart (field "file") หรือเนื้อหา JSON/YAML ตรงๆ ใน Body
+// dry_run=true คืนรายการที่จะเปลี่ยนโดยไม่บันทึก ไม่ผ่านการตรวจ = 422 พร้อม fields และ diff
+func (h *ConfigBundleHandler) Import(c *fiber.Ctx) error {
+	data, isYAML, err := readBundleBody(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var bundle domain.ConfigBundle
+	if isYAML {
+		err = yaml.Unmarshal(data, &bundle)
+	} else {
+		err = json.Unmarshal(data, &bundle)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid bundle: " + err.Error()})
+	}
+
+	actorID, _ := currentUserID(c)
+	diff, err := h.service.Import(&bundle, c.QueryBool("dry_run"), actorID)
+	if err != nil {
+		var invalid *ports.SettingValidationError
+		if errors.As(err, &invalid) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error(), "fields": invalid.Fields, "diff": diff})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(diff)
+}
+
+// readBundleBody เนื้อหา Bundle และชนิด (YAML ดูจากนามสกุลไฟล์ / Content-Type / ?format=yaml)
+func readBundleBody(c *fiber.Ctx) ([]byte, bool, error) {
+	isYAML := c.Query("format") == "yaml"
+
+	if file, err := c.FormFile("file"); err == nil {
+		src, err := file.Open()
+		if err != nil {
+			return nil, false, err
+		}
+		defer src.Close()
+		data, err := io.ReadAll(src)
+		if err != nil {
+			return nil, false, err
+		}
+		ext := strings.ToLower(filepath.Ext(file.Filename))
+		return data, isYAML || ext == ".yaml" || ext == ".yml", nil
+	}
+
+	if len(c.Body()) == 0 {
+		return nil, false, errors.New("bundle file is required")
+	}
+	return c.Body(), isYAML || strings.Contains(string(c.Request().Header.ContentType()), "yaml"), nil
+}
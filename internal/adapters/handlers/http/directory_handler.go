@@ -0,0 +1,41 @@
+package http
+
+import (
+	"errors"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DirectoryHandler struct {
+	directory ports.DirectoryAuthenticator
+}
+
+func NewDirectoryHandler(directory ports.DirectoryAuthenticator) *DirectoryHandler {
+	return &DirectoryHandler{directory: directory}
+}
+
+// POST /api/ldap/test
+// ทดสอบการตั้งค่า LDAP ด้วยบัญชีจริง: คืนข้อมูลและ role ที่จะได้ (ไม่สร้างบัญชีในระบบ)
+func (h *DirectoryHandler) Test(c *fiber.Ctx) error {
+	var input struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := c.BodyParser(&input); err != nil || input.Username == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "username and password are required"})
+	}
+	if !h.directory.Enabled() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "LDAP authentication is not enabled"})
+	}
+
+	user, err := h.directory.Authenticate(input.Username, input.Password)
+	if err != nil {
+		status := fiber.StatusBadGateway
+		if errors.Is(err, ports.ErrDirectoryInvalidCredentials) || errors.Is(err, ports.ErrDirectoryAccessDenied) {
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(user)
+}
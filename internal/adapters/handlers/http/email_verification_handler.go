@@ -0,0 +1,52 @@
+package http
+
+import (
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type EmailVerificationHandler struct {
+	service ports.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(service ports.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{service: service}
+}
+
+// POST /api/register/verify
+func (h *EmailVerificationHandler) Verify(c *fiber.Ctx) error {
+	var input struct {
+		Token string `json:"token"`
+	}
+	if err := c.BodyParser(&input); err != nil || input.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token is required"})
+	}
+
+	user, err := h.service.Verify(input.Token, clientInfo(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	message := "Email verified, you can now log in"
+	if user.Status == domain.UserStatusPendingApproval {
+		message = "Email verified, your account is awaiting administrator approval"
+	}
+	return c.JSON(fiber.Map{"message": message, "status": user.Status})
+}
+
+// POST /api/register/resend
+// ตอบเหมือนกันทุกกรณี และส่งอีเมลเบื้องหลัง (เหมือน /api/password/forgot)
+func (h *EmailVerificationHandler) Resend(c *fiber.Ctx) error {
+	var input struct {
+		Email string `json:"email"`
+	}
+	if err := c.BodyParser(&input); err != nil || input.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email is required"})
+	}
+
+	go h.service.Resend(input.Email, clientInfo(c))
+
+	return c.JSON(fiber.Map{"message": "If an unverified account with that email exists, a new verification link has been sent"})
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+const streamHeartbeat = 15 * time.Second
+
+type EventStreamHandler struct {
+	stream ports.EventStreamService
+	tokens ports.TokenService
+}
+
+func NewEventStreamHandler(stream ports.EventStreamService, tokens ports.TokenService) *EventStreamHandler {
+	return &EventStreamHandler{stream: stream, tokens: tokens}
+}
+
+// streamViewer ผู้ชมที่เชื่อมต่ออยู่ (ไม่ Login = userID 0)
+type streamViewer struct {
+	userID uint
+	role   string
+}
+
+// canSeePrivate: แอดมิน/ผู้อนุมัติเห็นทุกการจอง ผู้ใช้ทั่วไปเห็นรายละเอียดเฉพาะของตัวเอง
+func (v streamViewer) canSeePrivate(b domain.BookingEvent) bool {
+	if v.userID == 0 {
+		return false
+	}
+	return v.role == "admin" || v.role == "approver" || b.UserID == v.userID
+}
+
+// streamFilter เงื่อนไขจาก query string
+type streamFilter struct {
+	roomID uint
+	start  time.Time
+	end    time.Time
+}
+
+func (f streamFilter) match(data interface{}) bool {
+	switch d := data.(type) {
+	case domain.BookingEvent:
+		if f.roomID != 0 && d.RoomID != f.roomID {
+			return false
+		}
+		if !f.start.IsZero() && d.EndTime.Before(f.start) {
+			return false
+		}
+		if !f.end.IsZero() && d.StartTime.After(f.end) {
+			return false
+		}
+		return true
+	case domain.Room:
+		return f.roomID == 0 || d.ID == f.roomID
+	default:
+		return f.roomID == 0
+	}
+}
+
+// [GET] /api/bookings/stream?room_id=1&start=2026-10-01&end=2026-10-31&token=<jwt>
+// Server-Sent Events: ส่งเหตุการณ์ booking.* และ room.* ทันทีที่เกิดขึ้น
+// EventSource ของ Browser ใส่ Header ไม่ได้ จึงรับ JWT ทาง query "token" ได้ด้วย
+// ไม่ส่ง token = เห็นเฉพาะข้อมูลสาธารณะ (หัวข้อ ห้อง เวลา สถานะ)
+func (h *EventStreamHandler) Stream(c *fiber.Ctx) error {
+	var filter streamFilter
+	if roomID := c.QueryInt("room_id", 0); roomID > 0 {
+		filter.roomID = uint(roomID)
+	}
+	var err error
+	if filter.start, err = parseStreamTime(c.Query("start")); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid start date format"})
+	}
+	if filter.end, err = parseStreamTime(c.Query("end")); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid end date format"})
+	}
+
+	viewer, err := h.viewer(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // ปิด buffer ของ nginx
+
+	events, unsubscribe := h.stream.Subscribe()
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		fmt.Fprintf(w, "retry: 5000\nevent: ready\ndata: {}\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if !filter.match(event.Data) {
+					continue
+				}
+
+				data := event.Data
+				if b, isBooking := data.(domain.BookingEvent); isBooking && !viewer.canSeePrivate(b) {
+					data = b.Public()
+				}
+				payload, err := json.Marshal(data)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, payload)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+
+			// Flush ไม่ผ่าน = ผู้ใช้ปิดหน้าไปแล้ว
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}))
+
+	return nil
+}
+
+// viewer: อ่าน JWT จาก Authorization header หรือ query "token" (ไม่บังคับ)
+func (h *EventStreamHandler) viewer(c *fiber.Ctx) (streamViewer, error) {
+	tokenString := c.Query("token")
+	if auth := c.Get(fiber.HeaderAuthorization); strings.HasPrefix(auth, "Bearer ") {
+		tokenString = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if tokenString == "" {
+		return streamViewer{}, nil
+	}
+
+	claims, err := h.tokens.ParseAccessToken(tokenString)
+	if err != nil {
+		return streamViewer{}, err
+	}
+	return streamViewer{userID: claims.UserID, role: claims.Role}, nil
+}
+
+// parseStreamTime รองรับ RFC3339 (FullCalendar) และ YYYY-MM-DD เหมือน GET /api/bookings
+func parseStreamTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"fmt"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type ImpersonationHandler struct {
+	service ports.ImpersonationService
+}
+
+func NewImpersonationHandler(service ports.ImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{service: service}
+}
+
+// ImpersonationBlocked Route ที่ห้ามเรียกระหว่างสวมสิทธิ์ (เปลี่ยนข้อมูลบัญชี / ความปลอดภัยของผู้ใช้จริง)
+var ImpersonationBlocked = map[string]bool{
+	"PUT /api/me":                          true,
+	"PUT /api/me/notification-preferences": true,
+	"POST /api/me/2fa/setup":               true,
+	"POST /api/me/2fa/enable":              true,
+	"POST /api/me/2fa/disable":             true,
+	"POST /api/me/2fa/recovery-codes":      true,
+	"POST /api/me/api-keys":                true,
+	"DELETE /api/me/api-keys/:id":          true,
+	"DELETE /api/me/sessions/:id":          true,
+}
+
+// currentImpersonatorID ผู้ดูแลที่สวมสิทธิ์ (claim "imp") ไม่มี = 0
+func currentImpersonatorID(c *fiber.Ctx) uint {
+	userCtx, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return 0
+	}
+	claims, _ := userCtx.Claims.(jwt.MapClaims)
+	imp, _ := claims["imp"].(float64)
+	return uint(imp)
+}
+
+// ImpersonationAudit: ครอบ jwtMiddleware ทั้งหมด (ทำงานหลัง Handler จบ)
+// Request ที่ใช้ Token สวมสิทธิ์ทุกครั้งถูกบันทึกพร้อมทั้งผู้ใช้และผู้ดูแล รวมถึงที่ถูกปฏิเสธ
+func ImpersonationAudit(logService ports.LogService, next fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := next(c)
+
+		if impersonatorID := currentImpersonatorID(c); impersonatorID != 0 {
+			userID, _ := currentUserID(c)
+			status := c.Response().StatusCode()
+			if fiberErr, ok := err.(*fiber.Error); ok {
+				status = fiberErr.Code
+			}
+			client := clientInfo(c)
+			description := fmt.Sprintf("%s %s -> %d", c.Method(), c.OriginalURL(), status)
+			go logService.LogImpersonatedAction(userID, impersonatorID, "IMPERSONATED_REQUEST", description, client.IP, client.UserAgent)
+		}
+		return err
+	}
+}
+
+// POST /api/users/:id/impersonate
+// {"reason": "ticket #123"} คืน Access Token ในนามผู้ใช้ (ไม่มี Refresh Token หมดอายุตาม impersonation_ttl_minutes)
+func (h *ImpersonationHandler) Start(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	actorID, _ := currentUserID(c)
+	tokens, user, err := h.service.Start(uint(id), actorID, input.Reason, clientInfo(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"message":    fmt.Sprintf("Impersonating %s", user.Username),
+		"token":      tokens.AccessToken,
+		"expires_in": tokens.ExpiresIn,
+		"user":       user,
+	})
+}
+
+// POST /api/impersonation/end
+// เรียกด้วย Token สวมสิทธิ์ (Token ใช้ไม่ได้อีกทันที) Frontend กลับไปใช้ Token เดิมของผู้ดูแล
+func (h *ImpersonationHandler) End(c *fiber.Ctx) error {
+	impersonatorID := currentImpersonatorID(c)
+	if impersonatorID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Not impersonating"})
+	}
+
+	userID, _ := currentUserID(c)
+	if err := h.service.End(currentSessionID(c), userID, impersonatorID, clientInfo(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Impersonation ended"})
+}
@@ -22,26 +22,3 @@ func (h *LogHandler) GetLogs(c *fiber.Ctx) error {
 	}
 	return c.JSON(logs)
 }
-
-// [POST] /api/logs/test (For manual testing)
-func (h *LogHandler) CreateTestLog(c *fiber.Ctx) error {
-	var input struct {
-		UserID      uint   `json:"user_id"`
-		Action      string `json:"action"`
-		Description string `json:"description"`
-	}
-
-	if err := c.BodyParser(&input); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
-	}
-
-	// Capture IP and User Agent
-	ip := c.IP()
-	userAgent := c.Get("User-Agent")
-
-	if err := h.service.LogAction(input.UserID, input.Action, input.Description, ip, userAgent); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	return c.JSON(fiber.Map{"message": "Log created successfully"})
-}
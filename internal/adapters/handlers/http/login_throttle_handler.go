@@ -0,0 +1,40 @@
+package http
+
+import (
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LoginThrottleHandler struct {
+	service ports.LoginThrottleService
+}
+
+func NewLoginThrottleHandler(service ports.LoginThrottleService) *LoginThrottleHandler {
+	return &LoginThrottleHandler{service: service}
+}
+
+// GET /api/login-lockouts
+// บัญชี / IP ที่ถูกล็อกอยู่ หรือมี Login ล้มเหลวในช่วงเวลาล่าสุด
+func (h *LoginThrottleHandler) GetLockouts(c *fiber.Ctx) error {
+	lockouts, err := h.service.GetLockouts()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(lockouts)
+}
+
+// DELETE /api/login-lockouts/:id
+// ปลดล็อกและล้างตัวนับ (เช่น ผู้ใช้ยืนยันตัวตนกับผู้ดูแลแล้ว)
+func (h *LoginThrottleHandler) Clear(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	actorID, _ := currentUserID(c)
+	if err := h.service.Clear(uint(id), actorID, clientInfo(c)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Lockout cleared"})
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationTemplateHandler struct {
+	service ports.NotificationTemplateService
+}
+
+func NewNotificationTemplateHandler(service ports.NotificationTemplateService) *NotificationTemplateHandler {
+	return &NotificationTemplateHandler{service: service}
+}
+
+// GET /api/notification-templates?event_type=&channel=&language=
+func (h *NotificationTemplateHandler) GetTemplates(c *fiber.Ctx) error {
+	templates, err := h.service.GetTemplates(c.Query("event_type"), c.Query("channel"), c.Query("language"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(templates)
+}
+
+// POST /api/notification-templates
+func (h *NotificationTemplateHandler) CreateTemplate(c *fiber.Ctx) error {
+	var tpl domain.NotificationTemplate
+	if err := c.BodyParser(&tpl); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	actorID, _ := currentUserID(c)
+	if err := h.service.CreateTemplate(&tpl, actorID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(tpl)
+}
+
+// PUT /api/notification-templates/:id
+func (h *NotificationTemplateHandler) UpdateTemplate(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var tpl domain.NotificationTemplate
+	if err := c.BodyParser(&tpl); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	actorID, _ := currentUserID(c)
+	if err := h.service.UpdateTemplate(uint(id), &tpl, actorID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Template updated successfully"})
+}
+
+// POST /api/notification-templates/preview
+// ส่ง subject/body มาเพื่อดูผลก่อนบันทึก หรือไม่ส่งเพื่อดู Template ที่บันทึกไว้
+func (h *NotificationTemplateHandler) Preview(c *fiber.Ctx) error {
+	var tpl domain.NotificationTemplate
+	if err := c.BodyParser(&tpl); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	rendered, err := h.service.Preview(&tpl)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(rendered)
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	oidcBrowserCookie    = "brms_oidc"
+	oidcBrowserCookieTTL = 10 * time.Minute // เท่ากับอายุของ state
+)
+
+type OIDCHandler struct {
+	service        ports.OIDCService
+	logService     ports.LogService
+	settingService ports.SettingService
+}
+
+func NewOIDCHandler(service ports.OIDCService, logService ports.LogService, settingService ports.SettingService) *OIDCHandler {
+	return &OIDCHandler{service: service, logService: logService, settingService: settingService}
+}
+
+// GET /api/oidc/login
+// พาไปหน้า Login ของผู้ให้บริการ (Google Workspace / Microsoft 365 ฯลฯ)
+func (h *OIDCHandler) Login(c *fiber.Ctx) error {
+	authURL, browser, err := h.service.LoginURL(clientInfo(c))
+	if err != nil {
+		return h.redirectError(c, err.Error())
+	}
+	h.setBrowserCookie(c, browser, time.Now().Add(oidcBrowserCookieTTL))
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// GET /api/oidc/callback?code=...&state=...
+// สำเร็จ: กลับไปหน้า Frontend /auth/callback?code=<รหัสใช้ครั้งเดียว> เพื่อเรียก POST /api/oidc/exchange
+func (h *OIDCHandler) Callback(c *fiber.Ctx) error {
+	if errCode := c.Query("error"); errCode != "" {
+		message := c.Query("error_description")
+		if message == "" {
+			message = errCode
+		}
+		return h.redirectError(c, message)
+	}
+	if c.Query("state") == "" || c.Query("code") == "" {
+		return h.redirectError(c, "missing state or code")
+	}
+
+	browser := c.Cookies(oidcBrowserCookie)
+	h.setBrowserCookie(c, "", time.Unix(0, 0)) // ใช้ครั้งเดียว
+	code, err := h.service.Callback(c.Query("state"), c.Query("code"), browser, clientInfo(c))
+	if err != nil {
+		return h.redirectError(c, err.Error())
+	}
+	return c.Redirect(h.frontendURL("/auth/callback")+"?code="+url.QueryEscape(code), fiber.StatusFound)
+}
+
+// POST /api/oidc/exchange
+// ตอบกลับรูปแบบเดียวกับ POST /api/login
+func (h *OIDCHandler) Exchange(c *fiber.Ctx) error {
+	var input struct {
+		Code string `json:"code"`
+	}
+	if err := c.BodyParser(&input); err != nil || input.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "code is required"})
+	}
+
+	tokens, userID, err := h.service.Exchange(input.Code, clientInfo(c))
+	var twoFactor *ports.TwoFactorRequiredError
+	if errors.As(err, &twoFactor) {
+		// ผู้ให้บริการยืนยันตัวตนแล้ว แต่ยังต้องผ่าน 2FA ของระบบ (เหมือน POST /api/login)
+		return c.JSON(fiber.Map{
+			"two_factor_required": true,
+			"enroll_required":     twoFactor.EnrollRequired,
+			"challenge_token":     twoFactor.ChallengeToken,
+			"expires_in":          twoFactor.ExpiresIn,
+		})
+	}
+	if locked, resp := loginLocked(c, err); locked {
+		return resp
+	}
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	go h.logService.LogAction(userID, "LOGIN", "เข้าสู่ระบบสำเร็จ (SSO)", c.IP(), c.Get("User-Agent"))
+
+	return c.JSON(fiber.Map{
+		"message":            "Login successful",
+		"token":              tokens.AccessToken,
+		"refresh_token":      tokens.RefreshToken,
+		"expires_in":         tokens.ExpiresIn,
+		"refresh_expires_at": tokens.RefreshExpiresAt,
+	})
+}
+
+// setBrowserCookie: SameSite=Lax ยังถูกส่งมากับการ Redirect จากผู้ให้บริการกลับมาที่ /api/oidc/callback
+func (h *OIDCHandler) setBrowserCookie(c *fiber.Ctx, value string, expires time.Time) {
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcBrowserCookie,
+		Value:    value,
+		Path:     "/api/oidc",
+		Expires:  expires,
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+func (h *OIDCHandler) redirectError(c *fiber.Ctx, message string) error {
+	return c.Redirect(h.frontendURL("/login")+"?sso_error="+url.QueryEscape(message), fiber.StatusFound)
+}
+
+func (h *OIDCHandler) frontendURL(path string) string {
+	return strings.TrimRight(h.settingService.GetSettingValue("public_base_url"), "/") + path
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type fakeOIDCService struct {
+	ports.OIDCService
+	browser string // ค่า Cookie ที่ Callback ได้รับ
+}
+
+func (s *fakeOIDCService) LoginURL(client ports.ClientInfo) (string, string, error) {
+	return "https://idp.example.com/authorize?state=abc", "browser-binder", nil
+}
+
+func (s *fakeOIDCService) Callback(state, code, browser string, client ports.ClientInfo) (string, error) {
+	s.browser = browser
+	return "exchange-code", nil
+}
+
+type fakeSettingValues struct {
+	ports.SettingService
+}
+
+func (fakeSettingValues) GetSettingValue(name string) string { return "https://booking.example.com" }
+
+func TestOIDCLoginBindsCallbackToBrowser(t *testing.T) {
+	service := &fakeOIDCService{}
+	handler := NewOIDCHandler(service, nil, fakeSettingValues{})
+	app := fiber.New()
+	app.Get("/api/oidc/login", handler.Login)
+	app.Get("/api/oidc/callback", handler.Callback)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/oidc/login", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookie := resp.Header.Get(fiber.HeaderSetCookie)
+	for _, want := range []string{oidcBrowserCookie + "=browser-binder", "HttpOnly", "SameSite=Lax", "path=/api/oidc"} {
+		if !strings.Contains(cookie, want) {
+			t.Errorf("Set-Cookie %q has no %s", cookie, want)
+		}
+	}
+
+	req := httptest.NewRequest(fiber.MethodGet, "/api/oidc/callback?state=abc&code=xyz", nil)
+	req.Header.Set(fiber.HeaderCookie, oidcBrowserCookie+"=browser-binder")
+	if _, err := app.Test(req); err != nil {
+		t.Fatal(err)
+	}
+	if service.browser != "browser-binder" {
+		t.Fatalf("Callback got browser %q, want the cookie value", service.browser)
+	}
+}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type OutboxHandler struct {
+	service ports.OutboxService
+}
+
+func NewOutboxHandler(service ports.OutboxService) *OutboxHandler {
+	return &OutboxHandler{service: service}
+}
+
+// GET /api/outbox?status=dead&limit=100
+func (h *OutboxHandler) GetMessages(c *fiber.Ctx) error {
+	msgs, err := h.service.GetMessages(c.Query("status"), c.QueryInt("limit", 100))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(msgs)
+}
+
+// POST /api/outbox/:id/retry
+func (h *OutboxHandler) Retry(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	actorID, _ := currentUserID(c)
+	if err := h.service.Retry(uint(id), actorID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Notification queued for retry"})
+}
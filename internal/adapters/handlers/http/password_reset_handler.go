@@ -0,0 +1,46 @@
+package http
+
+import (
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PasswordResetHandler struct {
+	service ports.PasswordResetService
+}
+
+func NewPasswordResetHandler(service ports.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{service: service}
+}
+
+// POST /api/password/forgot
+// ตอบเหมือนกันทุกกรณี (มี/ไม่มีบัญชี) และส่งอีเมลเบื้องหลัง เพื่อไม่ให้เวลาตอบกลับบอกใบ้ได้
+func (h *PasswordResetHandler) Forgot(c *fiber.Ctx) error {
+	var input struct {
+		Email string `json:"email"`
+	}
+	if err := c.BodyParser(&input); err != nil || input.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email is required"})
+	}
+
+	go h.service.RequestReset(input.Email, clientInfo(c))
+
+	return c.JSON(fiber.Map{"message": "If an account with that email exists, a password reset link has been sent"})
+}
+
+// POST /api/password/reset
+func (h *PasswordResetHandler) Reset(c *fiber.Ctx) error {
+	var input struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := c.BodyParser(&input); err != nil || input.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token and password are required"})
+	}
+
+	if err := h.service.ResetPassword(input.Token, input.Password, clientInfo(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Password has been reset, please log in again"})
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"strings"
+	"tunorth-brms-backend/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RoutePermissions สิทธิ์ที่ต้องมีของแต่ละ Route ("METHOD /path" ตามที่ลงทะเบียนใน RegisterRoutes)
+// Route ที่ใช้ Authorize แต่ไม่อยู่ในตารางนี้จะถูกปฏิเสธเสมอ (fail closed)
+var RoutePermissions = map[string]string{
+	// ข้อมูลส่วนตัว
+	"GET /api/me":                          domain.PermProfile,
+	"PUT /api/me":                          domain.PermProfile,
+	"GET /api/me/notification-preferences": domain.PermProfile,
+	"PUT /api/me/notification-preferences": domain.PermProfile,
+	"GET /api/me/2fa":                      domain.PermProfile,
+	"POST /api/me/2fa/setup":               domain.PermProfile,
+	"POST /api/me/2fa/enable":              domain.PermProfile,
+	"POST /api/me/2fa/disable":             domain.PermProfile,
+	"POST /api/me/2fa/recovery-codes":      domain.PermProfile,
+	"POST /api/impersonation/end":          domain.PermProfile,
+	"GET /api/me/sessions":                 domain.PermProfile,
+	"DELETE /api/me/sessions/:id":          domain.PermProfile,
+	"GET /api/me/invitations":              domain.PermProfile,
+	"GET /api/me/api-keys":                 domain.PermProfile,
+	"POST /api/me/api-keys":                domain.PermProfile,
+	"DELETE /api/me/api-keys/:id":          domain.PermProfile,
+	"GET /api/notifications":               domain.PermProfile,
+	"PATCH /api/notifications/:id/read":    domain.PermProfile,
+	"POST /api/notifications/read-all":     domain.PermProfile,
+
+	// การจอง
+	"GET /api/bookings/:id":           domain.PermBookingsView,
+	"GET /api/bookings/:id/attendees": domain.PermBookingsView,
+	"PUT /api/bookings/:id/attendees": domain.PermBookingsCreate,
+	"POST /api/bookings/:id/rsvp":     domain.PermProfile,
+	"POST /api/bookings":              domain.PermBookingsCreate,
+	"PUT /api/bookings/:id":           domain.PermBookingsCreate,
+	"DELETE /api/bookings/:id":        domain.PermBookingsCreate,
+	"PATCH /api/bookings/:id/status":  domain.PermBookingsApprove,
+
+	// ห้อง / อุปกรณ์
+	"POST /api/rooms":           domain.PermRoomsManage,
+	"PUT /api/rooms/:id":        domain.PermRoomsManage,
+	"DELETE /api/rooms/:id":     domain.PermRoomsManage,
+	"GET /api/resources":        domain.PermResourcesView,
+	"POST /api/resources":       domain.PermResourcesManage,
+	"PUT /api/resources/:id":    domain.PermResourcesManage,
+	"DELETE /api/resources/:id": domain.PermResourcesManage,
+
+	// ผู้ใช้
+	"GET /api/users":                  domain.PermUsersManage,
+	"PUT /api/users/:id":              domain.PermUsersManage,
+	"PATCH /api/users/:id/status":     domain.PermUsersManage,
+	"GET /api/users/:id/sessions":     domain.PermUsersManage,
+	"DELETE /api/users/:id/sessions":  domain.PermUsersManage,
+	"POST /api/users/:id/impersonate": domain.PermUsersManage,
+	"DELETE /api/users/:id/2fa":       domain.PermUsersManage,
+	"DELETE /api/users/:id":           domain.PermUsersManage,
+	"POST /api/users/import":          domain.PermUsersManage,
+	"GET /api/api-keys":               domain.PermUsersManage,
+	"DELETE /api/api-keys/:id":        domain.PermUsersManage,
+	"GET /api/login-lockouts":         domain.PermUsersManage,
+	"DELETE /api/login-lockouts/:id":  domain.PermUsersManage,
+
+	// ตั้งค่า / รายงาน / Log
+	"GET /api/settings":                       domain.PermSettingsManage,
+	"GET /api/settings/schema":                domain.PermSettingsManage,
+	"GET /api/settings/history":               domain.PermSettingsManage,
+	"POST /api/settings/history/:id/rollback": domain.PermSettingsManage,
+	"GET /api/settings/export":                domain.PermSettingsManage,
+	"POST /api/settings/import":               domain.PermSettingsManage,
+	"PUT /api/settings":                       domain.PermSettingsManage,
+	"POST /api/settings/upload":               domain.PermSettingsManage,
+	"POST /api/ldap/test":                     domain.PermSettingsManage,
+	"GET /api/reports/dashboard":              domain.PermReportsView,
+	"GET /api/logs":                           domain.PermLogsView,
+
+	// การแจ้งเตือน
+	"GET /api/notification-templates":          domain.PermNotificationsManage,
+	"POST /api/notification-templates":         domain.PermNotificationsManage,
+	"POST /api/notification-templates/preview": domain.PermNotificationsManage,
+	"PUT /api/notification-templates/:id":      domain.PermNotificationsManage,
+	"GET /api/outbox":                          domain.PermNotificationsManage,
+	"POST /api/outbox/:id/retry":               domain.PermNotificationsManage,
+
+	// การเชื่อมต่อระบบภายนอก
+	"GET /api/chat-webhooks":                  domain.PermIntegrationsManage,
+	"POST /api/chat-webhooks":                 domain.PermIntegrationsManage,
+	"PUT /api/chat-webhooks/:id":              domain.PermIntegrationsManage,
+	"DELETE /api/chat-webhooks/:id":           domain.PermIntegrationsManage,
+	"POST /api/chat-webhooks/:id/test":        domain.PermIntegrationsManage,
+	"GET /api/webhooks":                       domain.PermIntegrationsManage,
+	"GET /api/webhooks/events":                domain.PermIntegrationsManage,
+	"POST /api/webhooks":                      domain.PermIntegrationsManage,
+	"POST /api/webhooks/deliveries/:id/retry": domain.PermIntegrationsManage,
+	"GET /api/webhooks/:id":                   domain.PermIntegrationsManage,
+	"PUT /api/webhooks/:id":                   domain.PermIntegrationsManage,
+	"DELETE /api/webhooks/:id":                domain.PermIntegrationsManage,
+	"GET /api/webhooks/:id/deliveries":        domain.PermIntegrationsManage,
+	"POST /api/webhooks/:id/test":             domain.PermIntegrationsManage,
+	"POST /api/webhooks/:id/rotate-secret":    domain.PermIntegrationsManage,
+}
+
+// Authorize: ตรวจสิทธิ์จาก role ใน JWT ตาม RoutePermissions (ต้องวางต่อจาก jwtMiddleware)
+func Authorize(c *fiber.Ctx) error {
+	permission, ok := RoutePermissions[routeKey(c)]
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Forbidden"})
+	}
+
+	if currentImpersonatorID(c) != 0 && ImpersonationBlocked[routeKey(c)] {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "This action is not allowed while impersonating a user"})
+	}
+
+	if !domain.HasPermission(currentRole(c), permission) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Forbidden", "required_permission": permission})
+	}
+	// เรียกด้วย API Key: scope ของ Key ต้องครอบคลุมสิทธิ์นี้ด้วย
+	if key := currentAPIKey(c); key != nil && !key.Allows(permission) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "API key scope does not allow this request", "required_permission": permission})
+	}
+	return c.Next()
+}
+
+// routeKey: Route ของ Group ที่ลงทะเบียนด้วย "/" จะมี / ต่อท้าย (เช่น /api/rooms/) ตัดออกให้ตรงกับตาราง
+func routeKey(c *fiber.Ctx) string {
+	path := c.Route().Path
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return c.Method() + " " + path
+}
+
+// currentRole ดึง role จาก JWT ที่ jwtMiddleware แปะไว้ใน Locals("user")
+func currentRole(c *fiber.Ctx) string {
+	userCtx, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return ""
+	}
+	claims, ok := userCtx.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	role, _ := claims["role"].(string)
+	return role
+}
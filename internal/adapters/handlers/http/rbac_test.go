@@ -0,0 +1,258 @@
+package http
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"tunorth-brms-backend/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// caller ผู้เรียกแต่ละแบบ: role ใน JWT และ scope ของ API Key (nil = Login ด้วย JWT ปกติ)
+type caller struct {
+	name   string
+	role   string // "" = ไม่ได้ Login
+	scopes []string
+}
+
+var callers = []caller{
+	{name: "admin", role: domain.RoleAdmin},
+	{name: "approver", role: domain.RoleApprover},
+	{name: "user", role: domain.RoleUser},
+	{name: "anonymous"},
+	// API Key ของผู้ดูแล: สิทธิ์ถูกจำกัดด้วย scope เท่านั้น
+	{name: "key:bookings:read", role: domain.RoleAdmin, scopes: []string{domain.ScopeBookingsRead}},
+	{name: "key:bookings:write", role: domain.RoleAdmin, scopes: []string{domain.ScopeBookingsWrite}},
+	{name: "key:reports:read", role: domain.RoleAdmin, scopes: []string{domain.ScopeReportsRead}},
+	// API Key ของผู้ใช้ทั่วไป: scope ไม่ให้สิทธิ์เกิน role ของเจ้าของ
+	{name: "user-key:reports:read", role: domain.RoleUser, scopes: []string{domain.ScopeReportsRead}},
+}
+
+// allowedCallers ผู้เรียกที่ต้องได้ 200 ของแต่ละสิทธิ์ (นอกนั้นต้องได้ 403)
+var allowedCallers = map[string][]string{
+	domain.PermProfile:             {"admin", "approver", "user"},
+	domain.PermBookingsView:        {"admin", "approver", "user", "key:bookings:read", "key:bookings:write"},
+	domain.PermBookingsCreate:      {"admin", "approver", "user", "key:bookings:write"},
+	domain.PermBookingsApprove:     {"admin", "approver"},
+	domain.PermResourcesView:       {"admin", "approver", "user", "key:bookings:read", "key:bookings:write"},
+	domain.PermReportsView:         {"admin", "approver", "key:reports:read"},
+	domain.PermRoomsManage:         {"admin"},
+	domain.PermResourcesManage:     {"admin"},
+	domain.PermUsersManage:         {"admin"},
+	domain.PermSettingsManage:      {"admin"},
+	domain.PermLogsView:            {"admin"},
+	domain.PermNotificationsManage: {"admin"},
+	domain.PermIntegrationsManage:  {"admin"},
+}
+
+// identifyAs แทน jwtMiddleware: แปะ Token / API Key ของ caller แทนการตรวจ JWT จริง
+func identifyAs(who caller) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if who.role != "" {
+			c.Locals("user", &jwt.Token{Claims: jwt.MapClaims{"user_id": float64(1), "role": who.role}})
+		}
+		if who.scopes != nil {
+			c.Locals("api_key", &domain.APIKey{ID: 1, UserID: 1, Scopes: strings.Join(who.scopes, ",")})
+		}
+		return c.Next()
+	}
+}
+
+// newAuthorizeApp ลงทะเบียน Route ชุดเดียวกับ main.go ผ่าน RegisterRoutes
+// Handler ทุกตัวเป็น nil: Request ที่ผ่าน Authorize ไปแล้วจะ panic แล้วถูก recover เป็น 500 ซึ่งพอแยกจาก 403 ได้
+func newAuthorizeApp(who caller) *fiber.App {
+	app := fiber.New()
+	app.Use(recover.New())
+	RegisterRoutes(app.Group("/api"), Handlers{}, identifyAs(who))
+	return app
+}
+
+// publicRoutes Route ที่ตั้งใจให้เรียกได้โดยไม่ผ่าน Authorize
+var publicRoutes = map[string]bool{
+	"GET /api/settings/public":  true,
+	"GET /api/rooms":            true,
+	"GET /api/rooms/:id":        true,
+	"GET /api/bookings":         true,
+	"GET /api/bookings/stream":  true,
+	"POST /api/register":        true,
+	"POST /api/login":           true,
+	"POST /api/token/refresh":   true,
+	"POST /api/logout":          true,
+	"GET /api/oidc/login":       true,
+	"GET /api/oidc/callback":    true,
+	"POST /api/oidc/exchange":   true,
+	"POST /api/password/forgot": true,
+	"POST /api/password/reset":  true,
+	"POST /api/register/verify": true,
+	"POST /api/register/resend": true,
+	"POST /api/login/2fa":       true,
+	"POST /api/login/2fa/setup": true,
+}
+
+// registeredRoutes Route ที่ RegisterRoutes ลงทะเบียนจริง แยกตามว่ามี Authorize ใน chain หรือไม่
+func registeredRoutes(t *testing.T) (protected, public []string) {
+	t.Helper()
+	app := fiber.New()
+	RegisterRoutes(app.Group("/api"), Handlers{}, identifyAs(caller{}))
+
+	authorize := reflect.ValueOf(Authorize).Pointer()
+	seen := map[string]bool{}
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == fiber.MethodHead {
+			continue // Fiber ลงทะเบียน HEAD คู่กับ GET ให้อัตโนมัติ
+		}
+		path := route.Path
+		if len(path) > 1 {
+			path = strings.TrimSuffix(path, "/")
+		}
+		key := route.Method + " " + path
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		authorized := false
+		for _, handler := range route.Handlers {
+			if reflect.ValueOf(handler).Pointer() == authorize {
+				authorized = true
+			}
+		}
+		if authorized {
+			protected = append(protected, key)
+		} else {
+			public = append(public, key)
+		}
+	}
+	return protected, public
+}
+
+func TestRegisteredRoutesAreAuthorized(t *testing.T) {
+	protected, public := registeredRoutes(t)
+
+	for _, route := range public {
+		if !publicRoutes[route] {
+			t.Errorf("%s is registered without Authorize", route)
+		}
+	}
+	registered := map[string]bool{}
+	for _, route := range protected {
+		registered[route] = true
+		if _, ok := RoutePermissions[route]; !ok {
+			t.Errorf("%s uses Authorize but has no entry in RoutePermissions", route)
+		}
+	}
+	for route := range RoutePermissions {
+		if !registered[route] {
+			t.Errorf("RoutePermissions has %s, which RegisterRoutes does not register", route)
+		}
+	}
+}
+
+// requestPath แทน :param ด้วยค่าตัวอย่าง
+func requestPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") {
+			parts[i] = "1"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func TestAuthorizeRoutePermissions(t *testing.T) {
+	routes, _ := registeredRoutes(t)
+	for _, route := range routes {
+		if permission, ok := RoutePermissions[route]; ok {
+			if _, ok := allowedCallers[permission]; !ok {
+				t.Fatalf("%s: permission %q has no expected callers in allowedCallers", route, permission)
+			}
+		}
+	}
+
+	for _, who := range callers {
+		app := newAuthorizeApp(who)
+		for _, route := range routes {
+			allowed := false
+			for _, name := range allowedCallers[RoutePermissions[route]] {
+				if name == who.name {
+					allowed = true
+				}
+			}
+
+			method, path, _ := strings.Cut(route, " ")
+			resp, err := app.Test(httptest.NewRequest(method, requestPath(path), nil))
+			if err != nil {
+				t.Fatalf("%s as %s: %v", route, who.name, err)
+			}
+			if allowed && resp.StatusCode == fiber.StatusForbidden {
+				t.Errorf("%s as %s: got 403, want the request to reach the handler", route, who.name)
+			}
+			if !allowed && resp.StatusCode != fiber.StatusForbidden {
+				t.Errorf("%s as %s: got %d, want 403", route, who.name, resp.StatusCode)
+			}
+		}
+	}
+}
+
+func TestAuthorizeUnknownRouteFailsClosed(t *testing.T) {
+	const route = "GET /api/not-in-route-permissions"
+	if _, ok := RoutePermissions[route]; ok {
+		t.Fatalf("%s must not be in RoutePermissions", route)
+	}
+
+	for _, who := range callers {
+		app := fiber.New()
+		app.Get("/api/not-in-route-permissions", identifyAs(who), Authorize, func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/not-in-route-permissions", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusForbidden {
+			t.Errorf("as %s: got %d, want 403", who.name, resp.StatusCode)
+		}
+	}
+}
+
+// scopeRoutes Route ที่แต่ละ scope ต้องเรียกได้ (ตามชื่อ scope)
+var scopeRoutes = map[string][]string{
+	domain.ScopeBookingsRead:  {"GET /api/bookings/:id", "GET /api/resources"},
+	domain.ScopeBookingsWrite: {"GET /api/bookings/:id", "POST /api/bookings", "PUT /api/bookings/:id", "DELETE /api/bookings/:id"},
+	domain.ScopeReportsRead:   {"GET /api/reports/dashboard"},
+}
+
+func TestAPIKeyScopesMatchRoutes(t *testing.T) {
+	used := map[string]bool{}
+	for _, permission := range RoutePermissions {
+		used[permission] = true
+	}
+
+	for scope, permissions := range domain.APIKeyScopePermissions {
+		// ทุกสิทธิ์ของ scope ต้องมี Route ใช้งานจริง
+		for _, permission := range permissions {
+			if !used[permission] {
+				t.Errorf("scope %s grants %s, which no route in RoutePermissions uses", scope, permission)
+			}
+		}
+
+		routes, ok := scopeRoutes[scope]
+		if !ok {
+			t.Errorf("scope %s has no expected routes in scopeRoutes", scope)
+			continue
+		}
+		key := &domain.APIKey{Scopes: scope}
+		for _, route := range routes {
+			permission, ok := RoutePermissions[route]
+			if !ok {
+				t.Errorf("scope %s: route %s is not in RoutePermissions", scope, route)
+				continue
+			}
+			if !key.Allows(permission) {
+				t.Errorf("scope %s cannot reach %s (needs %s)", scope, route, permission)
+			}
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// Handlers รวม Handler ทุกตัวที่ประกอบใน main.go เพื่อส่งให้ RegisterRoutes
+type Handlers struct {
+	Setting                *SettingHandler
+	Room                   *RoomHandler
+	Booking                *BookingHandler
+	BookingAttendee        *BookingAttendeeHandler
+	EventStream            *EventStreamHandler
+	Auth                   *AuthHandler
+	OIDC                   *OIDCHandler
+	PasswordReset          *PasswordResetHandler
+	EmailVerification      *EmailVerificationHandler
+	TwoFactor              *TwoFactorHandler
+	Impersonation          *ImpersonationHandler
+	Session                *SessionHandler
+	APIKey                 *APIKeyHandler
+	NotificationPreference *NotificationPreferenceHandler
+	ConfigBundle           *ConfigBundleHandler
+	Directory              *DirectoryHandler
+	User                   *UserHandler
+	LoginThrottle          *LoginThrottleHandler
+	Resource               *ResourceHandler
+	Report                 *ReportHandler
+	UserNotification       *UserNotificationHandler
+	NotificationTemplate   *NotificationTemplateHandler
+	Outbox                 *OutboxHandler
+	ChatWebhook            *ChatWebhookHandler
+	Webhook                *WebhookHandler
+	Log                    *LogHandler
+}
+
+// RegisterRoutes ลงทะเบียน Route ทั้งหมดใต้ /api (main.go และเทสต์สิทธิ์ใช้ชุดเดียวกัน)
+// jwtMiddleware ตรวจ Token / API Key แล้ว Authorize ตรวจสิทธิ์ตาม RoutePermissions
+func RegisterRoutes(api fiber.Router, h Handlers, jwtMiddleware fiber.Handler) {
+	// Public Settings (ไม่ต้อง Login ก็ได้ จะได้โหลด Logo ได้)
+	api.Get("/settings/public", h.Setting.GetPublicSettings)
+
+	// Room Routes
+	rooms := api.Group("/rooms")
+	rooms.Post("/", jwtMiddleware, Authorize, h.Room.CreateRoom)      // สร้างห้อง
+	rooms.Get("/", h.Room.GetAllRooms)                                // ดูห้องทั้งหมด
+	rooms.Get("/:id", h.Room.GetRoom)                                 // ดูห้องรายตัว
+	rooms.Put("/:id", jwtMiddleware, Authorize, h.Room.UpdateRoom)    // แก้ไขห้อง
+	rooms.Delete("/:id", jwtMiddleware, Authorize, h.Room.DeleteRoom) // ลบห้อง
+
+	// Booking Routes
+	bookings := api.Group("/bookings")
+	bookings.Get("/", h.Booking.GetBookings)      // Public for Calendar View?
+	bookings.Get("/stream", h.EventStream.Stream) // Live updates (JWT ไม่บังคับ ใช้ดูข้อมูลส่วนตัว)
+	// Protected Booking Routes
+	bookings.Get("/:id", jwtMiddleware, Authorize, h.Booking.GetBooking)
+	bookings.Get("/:id/attendees", jwtMiddleware, Authorize, h.BookingAttendee.GetAttendees)
+	bookings.Put("/:id/attendees", jwtMiddleware, Authorize, h.BookingAttendee.SetAttendees)
+	bookings.Post("/:id/rsvp", jwtMiddleware, Authorize, h.BookingAttendee.Respond)
+	bookings.Post("/", jwtMiddleware, Authorize, h.Booking.CreateBooking)
+	bookings.Patch("/:id/status", jwtMiddleware, Authorize, h.Booking.UpdateStatus)
+	bookings.Put("/:id", jwtMiddleware, Authorize, h.Booking.UpdateBooking)
+	bookings.Delete("/:id", jwtMiddleware, Authorize, h.Booking.DeleteBooking)
+
+	// Auth Routes
+	api.Post("/register", h.Auth.Register)
+	api.Post("/login", h.Auth.Login)
+	api.Post("/token/refresh", h.Auth.RefreshToken)
+	api.Post("/logout", h.Auth.Logout)
+
+	// OpenID Connect SSO (Authorization Code + PKCE)
+	api.Get("/oidc/login", h.OIDC.Login)
+	api.Get("/oidc/callback", h.OIDC.Callback)
+	api.Post("/oidc/exchange", h.OIDC.Exchange)
+
+	// Password Reset / Email Verification / 2FA (จำกัดจำนวนครั้งต่อ IP กันการสุ่ม token / รหัส / ยิงอีเมล)
+	newTokenLimiter := func(max int) fiber.Handler {
+		return limiter.New(limiter.Config{
+			Max:        max,
+			Expiration: 15 * time.Minute,
+			LimitReached: func(c *fiber.Ctx) error {
+				return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "Too many requests, please try again later"})
+			},
+		})
+	}
+	passwordLimiter := newTokenLimiter(5)
+	api.Post("/password/forgot", passwordLimiter, h.PasswordReset.Forgot)
+	api.Post("/password/reset", passwordLimiter, h.PasswordReset.Reset)
+	verifyLimiter := newTokenLimiter(5)
+	api.Post("/register/verify", verifyLimiter, h.EmailVerification.Verify)
+	api.Post("/register/resend", verifyLimiter, h.EmailVerification.Resend)
+	twoFactorLimiter := newTokenLimiter(10)
+	api.Post("/login/2fa", twoFactorLimiter, h.TwoFactor.Verify)
+	api.Post("/login/2fa/setup", twoFactorLimiter, h.TwoFactor.ChallengeSetup)
+
+	// Protected Routes (jwtMiddleware ตรวจ Token แล้ว Authorize ตรวจสิทธิ์ตาม role - ดู RoutePermissions)
+	api.Get("/me", jwtMiddleware, Authorize, h.Auth.GetMe)
+	api.Put("/me", jwtMiddleware, Authorize, h.Auth.UpdateMe)
+	api.Get("/me/notification-preferences", jwtMiddleware, Authorize, h.NotificationPreference.GetPreferences)
+	api.Put("/me/notification-preferences", jwtMiddleware, Authorize, h.NotificationPreference.UpdatePreferences)
+	api.Get("/me/2fa", jwtMiddleware, Authorize, h.TwoFactor.GetStatus)
+	api.Post("/me/2fa/setup", jwtMiddleware, Authorize, h.TwoFactor.Setup)
+	api.Post("/me/2fa/enable", jwtMiddleware, Authorize, h.TwoFactor.Enable)
+	api.Post("/me/2fa/disable", jwtMiddleware, Authorize, h.TwoFactor.Disable)
+	api.Post("/me/2fa/recovery-codes", jwtMiddleware, Authorize, h.TwoFactor.RegenerateRecoveryCodes)
+	api.Post("/impersonation/end", jwtMiddleware, Authorize, h.Impersonation.End)
+	api.Get("/me/sessions", jwtMiddleware, Authorize, h.Session.GetMine)
+	api.Delete("/me/sessions/:id", jwtMiddleware, Authorize, h.Session.RevokeMine)
+	api.Get("/me/invitations", jwtMiddleware, Authorize, h.BookingAttendee.GetInvitations)
+	api.Get("/me/api-keys", jwtMiddleware, Authorize, h.APIKey.GetMine)
+	api.Post("/me/api-keys", jwtMiddleware, Authorize, h.APIKey.Create)
+	api.Delete("/me/api-keys/:id", jwtMiddleware, Authorize, h.APIKey.RevokeMine)
+
+	// Settings Protected
+	api.Get("/settings", jwtMiddleware, Authorize, h.Setting.GetAllSettings)
+	api.Put("/settings", jwtMiddleware, Authorize, h.Setting.UpdateSettings)
+	api.Get("/settings/schema", jwtMiddleware, Authorize, h.Setting.GetSchema)
+	api.Get("/settings/history", jwtMiddleware, Authorize, h.Setting.GetHistory)
+	api.Post("/settings/history/:id/rollback", jwtMiddleware, Authorize, h.Setting.Rollback)
+	api.Get("/settings/export", jwtMiddleware, Authorize, h.ConfigBundle.Export)
+	api.Post("/settings/import", jwtMiddleware, Authorize, h.ConfigBundle.Import)
+	api.Post("/settings/upload", jwtMiddleware, Authorize, h.Setting.UploadImage)
+	api.Post("/ldap/test", jwtMiddleware, Authorize, h.Directory.Test)
+
+	// User Routes
+	users := api.Group("/users")
+	users.Get("/", jwtMiddleware, Authorize, h.User.GetAllUsers)
+	users.Put("/:id", jwtMiddleware, Authorize, h.User.UpdateUser)
+	users.Patch("/:id/status", jwtMiddleware, Authorize, h.User.UpdateStatus)
+	users.Get("/:id/sessions", jwtMiddleware, Authorize, h.Session.GetUserSessions)
+	users.Delete("/:id/sessions", jwtMiddleware, Authorize, h.Session.RevokeUserSessions)
+	users.Post("/:id/impersonate", jwtMiddleware, Authorize, h.Impersonation.Start)
+	users.Delete("/:id/2fa", jwtMiddleware, Authorize, h.TwoFactor.Reset)
+	users.Delete("/:id", jwtMiddleware, Authorize, h.User.DeleteUser)
+	users.Post("/import", jwtMiddleware, Authorize, h.User.ImportUsers)
+	api.Get("/api-keys", jwtMiddleware, Authorize, h.APIKey.GetAll)
+	api.Delete("/api-keys/:id", jwtMiddleware, Authorize, h.APIKey.Revoke)
+	api.Get("/login-lockouts", jwtMiddleware, Authorize, h.LoginThrottle.GetLockouts)
+	api.Delete("/login-lockouts/:id", jwtMiddleware, Authorize, h.LoginThrottle.Clear)
+
+	// Resource Routes
+	resources := api.Group("/resources")
+	resources.Get("/", jwtMiddleware, Authorize, h.Resource.GetAllResources)
+	resources.Post("/", jwtMiddleware, Authorize, h.Resource.CreateResource)
+	resources.Put("/:id", jwtMiddleware, Authorize, h.Resource.UpdateResource)
+	resources.Delete("/:id", jwtMiddleware, Authorize, h.Resource.DeleteResource)
+
+	// Report Routes
+	api.Get("/reports/dashboard", jwtMiddleware, Authorize, h.Report.GetDashboardStats)
+
+	// In-app Notifications (กระดิ่งแจ้งเตือน)
+	api.Get("/notifications", jwtMiddleware, Authorize, h.UserNotification.GetNotifications)
+	api.Patch("/notifications/:id/read", jwtMiddleware, Authorize, h.UserNotification.MarkRead)
+	api.Post("/notifications/read-all", jwtMiddleware, Authorize, h.UserNotification.MarkAllRead)
+
+	// Notification Templates (แก้ไข/ดูตัวอย่างข้อความแจ้งเตือน)
+	api.Get("/notification-templates", jwtMiddleware, Authorize, h.NotificationTemplate.GetTemplates)
+	api.Post("/notification-templates", jwtMiddleware, Authorize, h.NotificationTemplate.CreateTemplate)
+	api.Post("/notification-templates/preview", jwtMiddleware, Authorize, h.NotificationTemplate.Preview)
+	api.Put("/notification-templates/:id", jwtMiddleware, Authorize, h.NotificationTemplate.UpdateTemplate)
+
+	// Notification Outbox (ดู/ส่งซ้ำ การแจ้งเตือนที่ล้มเหลว)
+	api.Get("/outbox", jwtMiddleware, Authorize, h.Outbox.GetMessages)
+	api.Post("/outbox/:id/retry", jwtMiddleware, Authorize, h.Outbox.Retry)
+
+	// Chat Webhooks (Slack / Discord / Teams ต่อห้องหรือหน่วยงาน)
+	api.Get("/chat-webhooks", jwtMiddleware, Authorize, h.ChatWebhook.GetWebhooks)
+	api.Post("/chat-webhooks", jwtMiddleware, Authorize, h.ChatWebhook.CreateWebhook)
+	api.Put("/chat-webhooks/:id", jwtMiddleware, Authorize, h.ChatWebhook.UpdateWebhook)
+	api.Delete("/chat-webhooks/:id", jwtMiddleware, Authorize, h.ChatWebhook.DeleteWebhook)
+	api.Post("/chat-webhooks/:id/test", jwtMiddleware, Authorize, h.ChatWebhook.Test)
+
+	// Webhooks (ลงทะเบียนปลายทาง / ดูประวัติการส่ง / ทดสอบยิง)
+	api.Get("/webhooks", jwtMiddleware, Authorize, h.Webhook.GetWebhooks)
+	api.Get("/webhooks/events", jwtMiddleware, Authorize, h.Webhook.GetEvents)
+	api.Post("/webhooks", jwtMiddleware, Authorize, h.Webhook.CreateWebhook)
+	api.Post("/webhooks/deliveries/:id/retry", jwtMiddleware, Authorize, h.Webhook.RetryDelivery)
+	api.Get("/webhooks/:id", jwtMiddleware, Authorize, h.Webhook.GetWebhook)
+	api.Put("/webhooks/:id", jwtMiddleware, Authorize, h.Webhook.UpdateWebhook)
+	api.Delete("/webhooks/:id", jwtMiddleware, Authorize, h.Webhook.DeleteWebhook)
+	api.Get("/webhooks/:id/deliveries", jwtMiddleware, Authorize, h.Webhook.GetDeliveries)
+	api.Post("/webhooks/:id/test", jwtMiddleware, Authorize, h.Webhook.Test)
+	api.Post("/webhooks/:id/rotate-secret", jwtMiddleware, Authorize, h.Webhook.RotateSecret)
+
+	// Log Routes
+	api.Get("/logs", jwtMiddleware, Authorize, h.Log.GetLogs)
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SessionHandler struct {
+	service ports.SessionService
+}
+
+func NewSessionHandler(service ports.SessionService) *SessionHandler {
+	return &SessionHandler{service: service}
+}
+
+// GET /api/me/sessions
+// เครื่องที่ Login อยู่ (current = เครื่องที่เรียก API นี้)
+func (h *SessionHandler) GetMine(c *fiber.Ctx) error {
+	userID, _ := currentUserID(c)
+	sessions, err := h.service.GetSessions(userID, currentSessionID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(sessions)
+}
+
+// DELETE /api/me/sessions/:id
+// ออกจากระบบเครื่องอื่น (หรือเครื่องนี้) ทันที
+func (h *SessionHandler) RevokeMine(c *fiber.Ctx) error {
+	userID, _ := currentUserID(c)
+	if err := h.service.Revoke(userID, c.Params("id"), clientInfo(c)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Session logged out"})
+}
+
+// GET /api/users/:id/sessions
+func (h *SessionHandler) GetUserSessions(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	sessions, err := h.service.GetSessions(uint(id), currentSessionID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(sessions)
+}
+
+// DELETE /api/users/:id/sessions
+// ผู้ดูแลบังคับให้ผู้ใช้ออกจากระบบทุกเครื่อง
+func (h *SessionHandler) RevokeUserSessions(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	actorID, _ := currentUserID(c)
+	if err := h.service.RevokeAll(uint(id), actorID, clientInfo(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "All sessions of the user have been logged out"})
+}
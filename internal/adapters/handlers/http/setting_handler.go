@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"time"
@@ -13,16 +14,17 @@ import (
 )
 
 type SettingHandler struct {
-	service ports.SettingService
+	service    ports.SettingService
+	cloudinary *storage.CloudinaryProvider
 }
 
-func NewSettingHandler(service ports.SettingService) *SettingHandler {
-	return &SettingHandler{service: service}
+func NewSettingHandler(service ports.SettingService, cloudinary *storage.CloudinaryProvider) *SettingHandler {
+	return &SettingHandler{service: service, cloudinary: cloudinary}
 }
 
 // GET /api/settings
 func (h *SettingHandler) GetAllSettings(c *fiber.Ctx) error {
-	_, list, err := h.service.GetAllSettings()
+	list, err := h.service.GetAllSettings()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -30,15 +32,20 @@ func (h *SettingHandler) GetAllSettings(c *fiber.Ctx) error {
 	return c.JSON(list)
 }
 
+// GET /api/settings/schema
+// ชนิด ตัวเลือก ช่วงตัวเลข และรูปแบบของทุกค่าตั้งค่า (ใช้ render ฟอร์มในหน้าผู้ดูแล)
+func (h *SettingHandler) GetSchema(c *fiber.Ctx) error {
+	return c.JSON(h.service.GetSchema())
+}
+
 // GET /api/settings/public (สำหรับ Frontend เรียกไปใช้ render ทั่วไป ไม่ต้อง login ก็ได้ หรือ login ก็ได้)
 func (h *SettingHandler) GetPublicSettings(c *fiber.Ctx) error {
-	dict, _, err := h.service.GetAllSettings()
+	dict, err := h.service.GetPublicSettings()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 	// Return map for easy access: { "site_name": "...", "logo": "..." }
-	// Filter strict secrets if needed
-	delete(dict, "telegram_bot_token") // ซ่อน Token
+	// เฉพาะค่าที่ Visibility = public (Token / รหัสผ่าน / การตั้งค่าภายในไม่ถูกส่งออกไป)
 	return c.JSON(dict)
 }
 
@@ -61,12 +68,60 @@ func (h *SettingHandler) UpdateSettings(c *fiber.Ctx) error {
 	}
 
 	if err := h.service.UpdateSettings(updates, actorID); err != nil {
+		var invalid *ports.SettingValidationError
+		if errors.As(err, &invalid) {
+			// แสดงข้อความใต้ช่องที่ผิด: {"fields": {"theme_color": "hex color, e.g. #db2777"}}
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error(), "fields": invalid.Fields})
+		}
+		if errors.Is(err, ports.ErrSettingsEncryptionKeyMissing) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.JSON(fiber.Map{"message": "Settings updated successfully"})
 }
 
+// GET /api/settings/history?setting=...&limit=50
+// ประวัติการแก้ไขล่าสุดก่อน พร้อมค่าเดิม/ค่าใหม่ (ค่า secret แสดงเป็น ********)
+func (h *SettingHandler) GetHistory(c *fiber.Ctx) error {
+	history, err := h.service.GetHistory(c.Query("setting"), c.QueryInt("limit", 50))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(history)
+}
+
+// POST /api/settings/history/:id/rollback
+// Body (ไม่บังคับ): {"setting_name": "theme_color"} ย้อนกลับเฉพาะค่านี้ ไม่ส่ง = ย้อนกลับทั้งชุด
+func (h *SettingHandler) Rollback(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+	var input struct {
+		SettingName string `json:"setting_name"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+		}
+	}
+
+	actorID, _ := currentUserID(c)
+	changeSet, err := h.service.Rollback(uint(id), input.SettingName, actorID)
+	if err != nil {
+		var invalid *ports.SettingValidationError
+		if errors.As(err, &invalid) {
+			// ค่าเดิมไม่ผ่านข้อกำหนดปัจจุบันแล้ว
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error(), "fields": invalid.Fields})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Settings rolled back successfully", "change_set": changeSet})
+}
+
 // POST /api/settings/upload
 func (h *SettingHandler) UploadImage(c *fiber.Ctx) error {
 	// 1. รับไฟล์
@@ -75,19 +130,12 @@ func (h *SettingHandler) UploadImage(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Image is required"})
 	}
 
-	// Retrieve Cloudinary Settings
-	cloudName := h.service.GetSettingValue("cloudinary_cloud_name")
-	apiKey := h.service.GetSettingValue("cloudinary_api_key")
-	apiSecret := h.service.GetSettingValue("cloudinary_api_secret")
-
 	// 2. ถ้ามี Config ครบ ให้ใช้ Cloudinary
-	if cloudName != "" && apiKey != "" && apiSecret != "" {
-		// Init Adapter
-		adapter, err := storage.NewCloudinaryAdapter(cloudName, apiKey, apiSecret)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to init Cloudinary: " + err.Error()})
-		}
-
+	adapter, err := h.cloudinary.Adapter()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to init Cloudinary: " + err.Error()})
+	}
+	if adapter != nil {
 		// Upload
 		url, err := adapter.Upload(file, "setting_"+fmt.Sprint(time.Now().UnixNano()))
 		if err != nil {
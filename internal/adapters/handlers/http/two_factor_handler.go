@@ -0,0 +1,154 @@
+package http
+
+import (
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TwoFactorHandler struct {
+	service    ports.TwoFactorService
+	tokens     ports.TokenService
+	logService ports.LogService
+}
+
+func NewTwoFactorHandler(service ports.TwoFactorService, tokens ports.TokenService, logService ports.LogService) *TwoFactorHandler {
+	return &TwoFactorHandler{service: service, tokens: tokens, logService: logService}
+}
+
+type twoFactorCodeInput struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+// POST /api/login/2fa
+// ขั้นที่สองของการ Login: รหัส 6 หลักจากแอป หรือ Recovery Code
+func (h *TwoFactorHandler) Verify(c *fiber.Ctx) error {
+	var input twoFactorCodeInput
+	if err := c.BodyParser(&input); err != nil || input.ChallengeToken == "" || input.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "challenge_token and code are required"})
+	}
+
+	user, recoveryCodes, err := h.service.CompleteChallenge(input.ChallengeToken, input.Code, clientInfo(c))
+	if locked, resp := loginLocked(c, err); locked {
+		return resp
+	}
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	tokens, err := h.tokens.Issue(user, clientInfo(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	go h.logService.LogAction(user.ID, "LOGIN", "เข้าสู่ระบบสำเร็จ (ยืนยันตัวตนสองขั้นตอน)", c.IP(), c.Get("User-Agent"))
+
+	response := fiber.Map{
+		"message":            "Login successful",
+		"token":              tokens.AccessToken,
+		"refresh_token":      tokens.RefreshToken,
+		"expires_in":         tokens.ExpiresIn,
+		"refresh_expires_at": tokens.RefreshExpiresAt,
+	}
+	if recoveryCodes != nil {
+		// เพิ่งตั้งค่า 2FA ระหว่าง Login: แสดง Recovery Code ได้ครั้งเดียว
+		response["recovery_codes"] = recoveryCodes
+	}
+	return c.JSON(response)
+}
+
+// POST /api/login/2fa/setup
+// role ที่ถูกบังคับใช้ 2FA แต่ยังไม่ได้ตั้งค่า (enroll_required) ขอ QR Code ด้วย challenge_token
+func (h *TwoFactorHandler) ChallengeSetup(c *fiber.Ctx) error {
+	var input twoFactorCodeInput
+	if err := c.BodyParser(&input); err != nil || input.ChallengeToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "challenge_token is required"})
+	}
+
+	setup, err := h.service.ChallengeSetup(input.ChallengeToken)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(setup)
+}
+
+// GET /api/me/2fa
+func (h *TwoFactorHandler) GetStatus(c *fiber.Ctx) error {
+	userID, _ := currentUserID(c)
+	status, err := h.service.Status(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(status)
+}
+
+// POST /api/me/2fa/setup
+func (h *TwoFactorHandler) Setup(c *fiber.Ctx) error {
+	userID, _ := currentUserID(c)
+	setup, err := h.service.BeginSetup(userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(setup)
+}
+
+// POST /api/me/2fa/enable
+func (h *TwoFactorHandler) Enable(c *fiber.Ctx) error {
+	var input twoFactorCodeInput
+	if err := c.BodyParser(&input); err != nil || input.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "code is required"})
+	}
+
+	userID, _ := currentUserID(c)
+	codes, err := h.service.Enable(userID, input.Code, clientInfo(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Two-factor authentication enabled", "recovery_codes": codes})
+}
+
+// POST /api/me/2fa/disable
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	var input twoFactorCodeInput
+	if err := c.BodyParser(&input); err != nil || input.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "code is required"})
+	}
+
+	userID, _ := currentUserID(c)
+	if err := h.service.Disable(userID, input.Code, clientInfo(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Two-factor authentication disabled"})
+}
+
+// POST /api/me/2fa/recovery-codes
+// สร้างชุดใหม่ (ชุดเดิมใช้ไม่ได้อีก)
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	var input twoFactorCodeInput
+	if err := c.BodyParser(&input); err != nil || input.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "code is required"})
+	}
+
+	userID, _ := currentUserID(c)
+	codes, err := h.service.RegenerateRecoveryCodes(userID, input.Code, clientInfo(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"recovery_codes": codes})
+}
+
+// DELETE /api/users/:id/2fa
+// ผู้ดูแลล้าง 2FA ให้ผู้ใช้ที่ทำโทรศัพท์หาย (Login ครั้งถัดไปต้องตั้งค่าใหม่ถ้า role ถูกบังคับ)
+func (h *TwoFactorHandler) Reset(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	actorID, _ := currentUserID(c)
+	if err := h.service.Reset(uint(id), actorID, clientInfo(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Two-factor authentication has been reset"})
+}
@@ -17,15 +17,48 @@ func NewUserHandler(service ports.UserService) *UserHandler {
 	return &UserHandler{service: service}
 }
 
-// GET /api/users
+// GET /api/users?status=pending_approval
 func (h *UserHandler) GetAllUsers(c *fiber.Ctx) error {
 	users, err := h.service.GetAllUsers()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+
+	if status := c.Query("status"); status != "" {
+		filtered := []domain.User{}
+		for _, u := range users {
+			if u.Status == status || (status == domain.UserStatusActive && u.IsActive()) {
+				filtered = append(filtered, u)
+			}
+		}
+		users = filtered
+	}
 	return c.JSON(users)
 }
 
+// PATCH /api/users/:id/status
+// อนุมัติบัญชีที่สมัครเอง ({"status": "active"}) หรือระงับการใช้งาน ({"status": "disabled"})
+func (h *UserHandler) UpdateStatus(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var input struct {
+		Status string `json:"status"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	actorID, _ := currentUserID(c)
+	if err := h.service.UpdateStatus(uint(id), input.Status, actorID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "User status updated successfully"})
+}
+
 // PUT /api/users/:id
 func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
@@ -33,11 +66,29 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
 	}
 
-	var user domain.User
-	if err := c.BodyParser(&user); err != nil {
+	// domain.User ไม่รับ password จาก JSON จึงต้องมี Struct สำหรับรับข้อมูลโดยเฉพาะ
+	var input struct {
+		FullName   string `json:"full_name"`
+		Department string `json:"department"`
+		Role       string `json:"role"`
+		Email      string `json:"email"`
+		Tel        string `json:"tel"`
+		Language   string `json:"language"`
+		Password   string `json:"password"` // ว่าง = ไม่เปลี่ยน
+	}
+	if err := c.BodyParser(&input); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
 	}
 
+	user := domain.User{
+		FullName:   input.FullName,
+		Department: input.Department,
+		Role:       input.Role,
+		Email:      input.Email,
+		Tel:        input.Tel,
+		Language:   input.Language,
+		Password:   input.Password,
+	}
 	if err := h.service.UpdateUser(uint(id), &user); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
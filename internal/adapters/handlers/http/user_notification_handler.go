@@ -0,0 +1,61 @@
+package http
+
+import (
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type UserNotificationHandler struct {
+	service ports.UserNotificationService
+}
+
+func NewUserNotificationHandler(service ports.UserNotificationService) *UserNotificationHandler {
+	return &UserNotificationHandler{service: service}
+}
+
+// GET /api/notifications?page=1&limit=20&unread=true
+func (h *UserNotificationHandler) GetNotifications(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	page, err := h.service.GetNotifications(userID, c.QueryBool("unread"), c.QueryInt("page", 1), c.QueryInt("limit", 20))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(page)
+}
+
+// PATCH /api/notifications/:id/read
+func (h *UserNotificationHandler) MarkRead(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	if err := h.service.MarkRead(userID, uint(id)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Notification marked as read"})
+}
+
+// POST /api/notifications/read-all
+func (h *UserNotificationHandler) MarkAllRead(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	count, err := h.service.MarkAllRead(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "All notifications marked as read", "updated": count})
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"errors"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookHandler struct {
+	service ports.WebhookService
+}
+
+func NewWebhookHandler(service ports.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// GET /api/webhooks
+func (h *WebhookHandler) GetWebhooks(c *fiber.Ctx) error {
+	webhooks, err := h.service.GetWebhooks()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(webhooks)
+}
+
+// GET /api/webhooks/events
+func (h *WebhookHandler) GetEvents(c *fiber.Ctx) error {
+	return c.JSON(domain.WebhookEvents)
+}
+
+// GET /api/webhooks/:id
+func (h *WebhookHandler) GetWebhook(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	webhook, err := h.service.GetWebhook(uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Webhook not found"})
+	}
+	return c.JSON(webhook)
+}
+
+// webhookWithSecret: domain.Webhook ไม่ส่ง secret ออกทาง JSON ใช้รับค่าตอนสร้าง/แก้ และส่งกลับตอนสร้างครั้งเดียว
+type webhookWithSecret struct {
+	domain.Webhook
+	Secret string `json:"secret"`
+}
+
+// POST /api/webhooks
+func (h *WebhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	req := webhookWithSecret{Webhook: domain.Webhook{IsActive: true}}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	webhook := req.Webhook
+	webhook.Secret = req.Secret
+	actorID, _ := currentUserID(c)
+	if err := h.service.CreateWebhook(&webhook, actorID); err != nil {
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	// ครั้งเดียวที่เห็น secret: หลังจากนี้ดูได้แค่ has_secret
+	return c.Status(fiber.StatusCreated).JSON(webhookWithSecret{Webhook: webhook, Secret: webhook.Secret})
+}
+
+// PUT /api/webhooks/:id
+func (h *WebhookHandler) UpdateWebhook(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	var req webhookWithSecret
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
+	}
+
+	webhook := req.Webhook
+	webhook.Secret = req.Secret // ว่าง = ใช้ค่าเดิม
+	actorID, _ := currentUserID(c)
+	if err := h.service.UpdateWebhook(uint(id), &webhook, actorID); err != nil {
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Webhook updated successfully"})
+}
+
+// DELETE /api/webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	actorID, _ := currentUserID(c)
+	if err := h.service.DeleteWebhook(uint(id), actorID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Webhook deleted successfully"})
+}
+
+// GET /api/webhooks/:id/deliveries?status=failed&limit=100
+func (h *WebhookHandler) GetDeliveries(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	deliveries, err := h.service.GetDeliveries(uint(id), c.Query("status"), c.QueryInt("limit", 100))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(deliveries)
+}
+
+// POST /api/webhooks/:id/rotate-secret
+func (h *WebhookHandler) RotateSecret(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	actorID, _ := currentUserID(c)
+	secret, err := h.service.RotateSecret(uint(id), actorID)
+	if err != nil {
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"secret": secret})
+}
+
+// POST /api/webhooks/:id/test
+func (h *WebhookHandler) Test(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	actorID, _ := currentUserID(c)
+	delivery, err := h.service.Test(uint(id), actorID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(delivery)
+}
+
+// POST /api/webhooks/deliveries/:id/retry
+func (h *WebhookHandler) RetryDelivery(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid ID"})
+	}
+
+	actorID, _ := currentUserID(c)
+	if err := h.service.RetryDelivery(uint(id), actorID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Delivery queued for retry"})
+}
+
+// webhookErrorStatus: ไม่ได้ตั้งกุญแจเข้ารหัสบนเซิร์ฟเวอร์ = 409 (เหมือนการบันทึกค่าตั้งค่าลับ) นอกนั้นเป็นข้อมูลไม่ถูกต้อง
+func webhookErrorStatus(err error) int {
+	if errors.Is(err, ports.ErrWebhookEncryptionKeyMissing) {
+		return fiber.StatusConflict
+	}
+	return fiber.StatusBadRequest
+}
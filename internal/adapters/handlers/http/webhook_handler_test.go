@@ -0,0 +1,86 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeWebhookService เก็บ Webhook ในหน่วยความจำ (เมธอดที่ไม่ได้ใช้ในเทสต์มาจาก interface ที่ฝังไว้)
+type fakeWebhookService struct {
+	ports.WebhookService
+	webhooks map[uint]*domain.Webhook
+}
+
+func (s *fakeWebhookService) CreateWebhook(webhook *domain.Webhook, actorID uint) error {
+	if webhook.Secret == "" {
+		webhook.Secret = "generated-secret"
+	}
+	webhook.ID = uint(len(s.webhooks) + 1)
+	webhook.HasSecret = true
+	stored := *webhook
+	s.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+func (s *fakeWebhookService) GetWebhook(id uint) (*domain.Webhook, error) {
+	webhook := *s.webhooks[id]
+	webhook.HasSecret = webhook.Secret != ""
+	return &webhook, nil
+}
+
+func (s *fakeWebhookService) RotateSecret(id uint, actorID uint) (string, error) {
+	s.webhooks[id].Secret = "rotated-secret"
+	return "rotated-secret", nil
+}
+
+func TestWebhookSecretOnlyReturnedOnce(t *testing.T) {
+	service := &fakeWebhookService{webhooks: map[uint]*domain.Webhook{}}
+	handler := NewWebhookHandler(service)
+	app := fiber.New()
+	app.Post("/webhooks", handler.CreateWebhook)
+	app.Get("/webhooks/:id", handler.GetWebhook)
+	app.Post("/webhooks/:id/rotate-secret", handler.RotateSecret)
+
+	call := func(method, path, body string) map[string]interface{} {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := io.ReadAll(resp.Body)
+		var out map[string]interface{}
+		if err := json.Unmarshal(raw, &out); err != nil {
+			t.Fatalf("%s %s: %v (%s)", method, path, err, raw)
+		}
+		return out
+	}
+
+	created := call(fiber.MethodPost, "/webhooks", `{"name":"ERP","url":"https://erp.example.com/hook","secret":"my-secret"}`)
+	if created["secret"] != "my-secret" || created["has_secret"] != true {
+		t.Fatalf("create response = %v, want the secret and has_secret", created)
+	}
+	if service.webhooks[1].Secret != "my-secret" {
+		t.Fatalf("stored secret = %q, want the one from the request", service.webhooks[1].Secret)
+	}
+
+	fetched := call(fiber.MethodGet, "/webhooks/1", "")
+	if _, ok := fetched["secret"]; ok {
+		t.Fatalf("GET response leaks the secret: %v", fetched)
+	}
+	if fetched["has_secret"] != true {
+		t.Fatalf("GET response = %v, want has_secret", fetched)
+	}
+
+	rotated := call(fiber.MethodPost, "/webhooks/1/rotate-secret", "")
+	if rotated["secret"] != "rotated-secret" {
+		t.Fatalf("rotate response = %v, want the new secret", rotated)
+	}
+}
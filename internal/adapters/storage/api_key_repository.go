@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"gorm.io/gorm"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) ports.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(key *domain.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepository) GetByHash(hash string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.Where("key_hash = ?", hash).First(&key).Error
+	return &key, err
+}
+
+func (r *apiKeyRepository) GetByID(id uint) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.First(&key, id).Error
+	return &key, err
+}
+
+func (r *apiKeyRepository) GetByUser(userID uint) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) GetAll() ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	err := r.db.Preload("User").Order("created_at desc").Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) Revoke(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&domain.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *apiKeyRepository) Touch(id uint, at, since time.Time, ip string) error {
+	return r.db.Model(&domain.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ? OR last_used_ip <> ?)", id, since, ip).
+		Updates(map[string]interface{}{"last_used_at": at, "last_used_ip": ip}).Error
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type bookingAttendeeRepository struct {
+	db *gorm.DB
+}
+
+func NewBookingAttendeeRepository(db *gorm.DB) ports.BookingAttendeeRepository {
+	return &bookingAttendeeRepository{db: db}
+}
+
+func (r *bookingAttendeeRepository) GetByBooking(bookingID uint) ([]domain.BookingAttendee, error) {
+	var attendees []domain.BookingAttendee
+	err := r.db.Preload("User").Where("booking_id = ?", bookingID).Order("created_at asc, user_id asc").Find(&attendees).Error
+	return attendees, err
+}
+
+func (r *bookingAttendeeRepository) GetByUser(userID uint) ([]domain.BookingAttendee, error) {
+	var attendees []domain.BookingAttendee
+	err := r.db.Preload("Booking").Preload("Booking.Room").Preload("Booking.User").
+		Where("user_id = ?", userID).Order("created_at desc").Find(&attendees).Error
+	return attendees, err
+}
+
+func (r *bookingAttendeeRepository) GetAccepted(bookingIDs []uint) (map[uint][]uint, error) {
+	accepted := make(map[uint][]uint)
+	if len(bookingIDs) == 0 {
+		return accepted, nil
+	}
+	var attendees []domain.BookingAttendee
+	if err := r.db.Where("booking_id IN ? AND status = ?", bookingIDs, domain.AttendeeStatusAccepted).Find(&attendees).Error; err != nil {
+		return nil, err
+	}
+	for _, a := range attendees {
+		accepted[a.BookingID] = append(accepted[a.BookingID], a.UserID)
+	}
+	return accepted, nil
+}
+
+func (r *bookingAttendeeRepository) Replace(bookingID uint, userIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		remove := tx.Where("booking_id = ?", bookingID)
+		if len(userIDs) > 0 {
+			remove = remove.Where("user_id NOT IN ?", userIDs)
+		}
+		if err := remove.Delete(&domain.BookingAttendee{}).Error; err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+
+		attendees := make([]domain.BookingAttendee, len(userIDs))
+		for i, userID := range userIDs {
+			attendees[i] = domain.BookingAttendee{BookingID: bookingID, UserID: userID, Status: domain.AttendeeStatusInvited}
+		}
+		// คนที่มีอยู่แล้วคงสถานะเดิม
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&attendees).Error
+	})
+}
+
+func (r *bookingAttendeeRepository) Respond(bookingID, userID uint, status string, at time.Time) (bool, error) {
+	result := r.db.Model(&domain.BookingAttendee{}).
+		Where("booking_id = ? AND user_id = ?", bookingID, userID).
+		Updates(map[string]interface{}{"status": status, "responded_at": at})
+	return result.RowsAffected > 0, result.Error
+}
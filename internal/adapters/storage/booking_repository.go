@@ -16,8 +16,13 @@ func NewBookingRepository(db *gorm.DB) ports.BookingRepository {
 	return &bookingRepository{db: db}
 }
 
-func (r *bookingRepository) Create(booking *domain.Booking) error {
-	return r.db.Create(booking).Error
+func (r *bookingRepository) Create(booking *domain.Booking, outbox ...domain.OutboxMessage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(booking).Error; err != nil {
+			return err
+		}
+		return createOutbox(tx, booking.ID, outbox)
+	})
 }
 
 func (r *bookingRepository) GetAll() ([]domain.Booking, error) {
@@ -33,6 +38,12 @@ func (r *bookingRepository) GetByID(id uint) (*domain.Booking, error) {
 	return &booking, err
 }
 
+func (r *bookingRepository) GetByIDWithDeleted(id uint) (*domain.Booking, error) {
+	var booking domain.Booking
+	err := r.db.Unscoped().Preload("Room").Preload("User").First(&booking, id).Error
+	return &booking, err
+}
+
 // GetByDateRange: ดึงข้อมูลเฉพาะช่วงวันที่กำหนด (เช่น ดึงทีละเดือน)
 func (r *bookingRepository) GetByDateRange(start, end time.Time) ([]domain.Booking, error) {
 	var bookings []domain.Booking
@@ -42,6 +53,15 @@ func (r *bookingRepository) GetByDateRange(start, end time.Time) ([]domain.Booki
 	return bookings, err
 }
 
+func (r *bookingRepository) GetByStatus(status string) ([]domain.Booking, error) {
+	var bookings []domain.Booking
+	err := r.db.Preload("Room").Preload("User").
+		Where("status = ?", status).
+		Order("start_time ASC").
+		Find(&bookings).Error
+	return bookings, err
+}
+
 // CountOverlapping: นับจำนวนการจองที่เวลาทับซ้อนกัน
 // Logic: (StartA < EndB) AND (EndA > StartB)
 func (r *bookingRepository) CountOverlapping(roomID uint, start, end time.Time) (int64, error) {
@@ -60,10 +80,31 @@ func (r *bookingRepository) CountOverlappingExcludingID(roomID uint, start, end
 	return count, err
 }
 
-func (r *bookingRepository) Update(booking *domain.Booking) error {
-	return r.db.Save(booking).Error
+func (r *bookingRepository) Update(booking *domain.Booking, outbox ...domain.OutboxMessage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(booking).Error; err != nil {
+			return err
+		}
+		return createOutbox(tx, booking.ID, outbox)
+	})
 }
 
-func (r *bookingRepository) Delete(id uint) error {
-	return r.db.Delete(&domain.Booking{}, id).Error
-}
\ No newline at end of file
+func (r *bookingRepository) Delete(id uint, outbox ...domain.OutboxMessage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.Booking{}, id).Error; err != nil {
+			return err
+		}
+		return createOutbox(tx, id, outbox)
+	})
+}
+
+// createOutbox: บันทึกข้อความแจ้งเตือนผูกกับ Booking ภายใน Transaction เดียวกัน
+func createOutbox(tx *gorm.DB, bookingID uint, outbox []domain.OutboxMessage) error {
+	for i := range outbox {
+		outbox[i].BookingID = &bookingID
+		if err := tx.Create(&outbox[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"gorm.io/gorm"
+)
+
+type chatWebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewChatWebhookRepository(db *gorm.DB) ports.ChatWebhookRepository {
+	return &chatWebhookRepository{db: db}
+}
+
+func (r *chatWebhookRepository) GetAll() ([]domain.ChatWebhook, error) {
+	var webhooks []domain.ChatWebhook
+	err := r.db.Order("id asc").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *chatWebhookRepository) GetActive() ([]domain.ChatWebhook, error) {
+	var webhooks []domain.ChatWebhook
+	err := r.db.Where("is_active = ?", true).Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *chatWebhookRepository) GetByID(id uint) (*domain.ChatWebhook, error) {
+	var webhook domain.ChatWebhook
+	err := r.db.First(&webhook, id).Error
+	return &webhook, err
+}
+
+func (r *chatWebhookRepository) Create(webhook *domain.ChatWebhook) error {
+	return r.db.Create(webhook).Error
+}
+
+func (r *chatWebhookRepository) Update(webhook *domain.ChatWebhook) error {
+	return r.db.Save(webhook).Error
+}
+
+func (r *chatWebhookRepository) Delete(id uint) error {
+	return r.db.Delete(&domain.ChatWebhook{}, id).Error
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"mime/multipart"
+	"sync"
+	"tunorth-brms-backend/internal/core/ports"
 
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
@@ -28,6 +30,51 @@ func NewCloudinaryAdapter(cloudName, apiKey, apiSecret string) (*CloudinaryAdapt
 	return &CloudinaryAdapter{cld: cld}, nil
 }
 
+// CloudinaryProvider keeps one client per credential set and rebuilds it
+// when any cloudinary_* setting changes (on this or another instance)
+type CloudinaryProvider struct {
+	settings ports.SettingService
+
+	mu      sync.Mutex
+	adapter *CloudinaryAdapter
+}
+
+func NewCloudinaryProvider(settings ports.SettingService) *CloudinaryProvider {
+	p := &CloudinaryProvider{settings: settings}
+	settings.Subscribe(func(keys []string) {
+		if ports.SettingsAffected(keys, "cloudinary_") {
+			p.mu.Lock()
+			p.adapter = nil
+			p.mu.Unlock()
+		}
+	})
+	return p
+}
+
+// Adapter returns the shared client, or nil when Cloudinary is not configured
+// (callers fall back to local storage)
+func (p *CloudinaryProvider) Adapter() (*CloudinaryAdapter, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.adapter != nil {
+		return p.adapter, nil
+	}
+
+	cloudName := p.settings.GetSettingValue("cloudinary_cloud_name")
+	apiKey := p.settings.GetSettingValue("cloudinary_api_key")
+	apiSecret := p.settings.GetSettingValue("cloudinary_api_secret")
+	if cloudName == "" || apiKey == "" || apiSecret == "" {
+		return nil, nil
+	}
+
+	adapter, err := NewCloudinaryAdapter(cloudName, apiKey, apiSecret)
+	if err != nil {
+		return nil, err
+	}
+	p.adapter = adapter
+	return adapter, nil
+}
+
 // Upload sends the file to Cloudinary and returns the secure URL
 func (a *CloudinaryAdapter) Upload(file *multipart.FileHeader, filename string) (string, error) {
 	ctx := context.Background()
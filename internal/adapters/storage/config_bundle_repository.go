@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"gorm.io/gorm"
+)
+
+type configBundleRepository struct {
+	db *gorm.DB
+}
+
+func NewConfigBundleRepository(db *gorm.DB) ports.ConfigBundleRepository {
+	return &configBundleRepository{db: db}
+}
+
+func (r *configBundleRepository) Apply(settings []domain.Setting, changeSet *domain.SettingChangeSet, rooms []domain.Room, resources []domain.Resource) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateSettings(tx, settings, changeSet); err != nil {
+			return err
+		}
+		for i := range rooms {
+			if err := tx.Save(&rooms[i]).Error; err != nil {
+				return err
+			}
+		}
+		for i := range resources {
+			if err := tx.Save(&resources[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
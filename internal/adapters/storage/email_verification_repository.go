@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"gorm.io/gorm"
+)
+
+type emailVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationRepository(db *gorm.DB) ports.EmailVerificationRepository {
+	return &emailVerificationRepository{db: db}
+}
+
+func (r *emailVerificationRepository) Create(token *domain.EmailVerificationToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *emailVerificationRepository) GetByHash(hash string) (*domain.EmailVerificationToken, error) {
+	var token domain.EmailVerificationToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	return &token, err
+}
+
+func (r *emailVerificationRepository) CountSince(userID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.EmailVerificationToken{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *emailVerificationRepository) MarkUsed(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&domain.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *emailVerificationRepository) InvalidateUser(userID uint, at time.Time) error {
+	return r.db.Model(&domain.EmailVerificationToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", at).Error
+}
@@ -22,6 +22,6 @@ func (r *logRepository) Create(log *domain.Log) error {
 func (r *logRepository) GetAll(limit int) ([]domain.Log, error) {
 	var logs []domain.Log
 	// Preload User to show who did the action
-	err := r.db.Preload("User").Order("created_at desc").Limit(limit).Find(&logs).Error
+	err := r.db.Preload("User").Preload("Impersonator").Order("created_at desc").Limit(limit).Find(&logs).Error
 	return logs, err
 }
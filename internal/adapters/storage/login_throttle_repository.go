@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type loginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) ports.LoginThrottleRepository {
+	return &loginThrottleRepository{db: db}
+}
+
+func (r *loginThrottleRepository) Get(scope, key string) (*domain.LoginThrottle, error) {
+	var entry domain.LoginThrottle
+	err := r.db.Where("scope = ? AND key = ?", scope, key).First(&entry).Error
+	return &entry, err
+}
+
+// RecordFailure: upsert ในคำสั่งเดียว กันการนับหายเมื่อมีหลาย request พร้อมกัน
+func (r *loginThrottleRepository) RecordFailure(entry *domain.LoginThrottle, windowStart time.Time) (*domain.LoginThrottle, error) {
+	entry.Failures = 1
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "scope"}, {Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":        gorm.Expr("CASE WHEN login_throttles.last_failed_at < ? THEN 1 ELSE login_throttles.failures + 1 END", windowStart),
+			"last_failed_at":  entry.LastFailedAt,
+			"last_ip":         entry.LastIP,
+			"last_user_agent": entry.LastUserAgent,
+			"user_id":         entry.UserID,
+			"updated_at":      entry.LastFailedAt,
+		}),
+	}).Create(entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(entry.Scope, entry.Key)
+}
+
+func (r *loginThrottleRepository) SetLockedUntil(id uint, until time.Time) error {
+	return r.db.Model(&domain.LoginThrottle{}).Where("id = ?", id).Update("locked_until", until).Error
+}
+
+func (r *loginThrottleRepository) Reset(scope, key string) error {
+	return r.db.Where("scope = ? AND key = ?", scope, key).Delete(&domain.LoginThrottle{}).Error
+}
+
+func (r *loginThrottleRepository) GetByID(id uint) (*domain.LoginThrottle, error) {
+	var entry domain.LoginThrottle
+	err := r.db.First(&entry, id).Error
+	return &entry, err
+}
+
+func (r *loginThrottleRepository) GetRecent(since time.Time) ([]domain.LoginThrottle, error) {
+	var entries []domain.LoginThrottle
+	err := r.db.Where("locked_until > ? OR last_failed_at > ?", time.Now(), since).
+		Order("last_failed_at desc").
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *loginThrottleRepository) Delete(id uint) error {
+	return r.db.Delete(&domain.LoginThrottle{}, id).Error
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"gorm.io/gorm"
+)
+
+type notificationPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationPreferenceRepository(db *gorm.DB) ports.NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{db: db}
+}
+
+func (r *notificationPreferenceRepository) GetByUser(userID uint) ([]domain.NotificationPreference, error) {
+	var prefs []domain.NotificationPreference
+	err := r.db.Where("user_id = ?", userID).Find(&prefs).Error
+	return prefs, err
+}
+
+func (r *notificationPreferenceRepository) GetQuietHours(userID uint) (*domain.NotificationQuietHours, error) {
+	var quiet domain.NotificationQuietHours
+	err := r.db.First(&quiet, "user_id = ?", userID).Error
+	return &quiet, err
+}
+
+func (r *notificationPreferenceRepository) Save(userID uint, prefs []domain.NotificationPreference, quiet *domain.NotificationQuietHours) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.NotificationPreference{}).Error; err != nil {
+			return err
+		}
+		if len(prefs) > 0 {
+			if err := tx.Create(&prefs).Error; err != nil {
+				return err
+			}
+		}
+		if quiet != nil {
+			quiet.UserID = userID
+			if err := tx.Save(quiet).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"gorm.io/gorm"
+)
+
+type notificationTemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationTemplateRepository(db *gorm.DB) ports.NotificationTemplateRepository {
+	return &notificationTemplateRepository{db: db}
+}
+
+func (r *notificationTemplateRepository) GetAll() ([]domain.NotificationTemplate, error) {
+	var templates []domain.NotificationTemplate
+	err := r.db.Order("event_type, channel, language").Find(&templates).Error
+	return templates, err
+}
+
+func (r *notificationTemplateRepository) GetByID(id uint) (*domain.NotificationTemplate, error) {
+	var tpl domain.NotificationTemplate
+	err := r.db.First(&tpl, id).Error
+	return &tpl, err
+}
+
+func (r *notificationTemplateRepository) Find(eventType, channel, language string) (*domain.NotificationTemplate, error) {
+	var tpl domain.NotificationTemplate
+	err := r.db.Where("event_type = ? AND channel = ? AND language = ?", eventType, channel, language).First(&tpl).Error
+	return &tpl, err
+}
+
+func (r *notificationTemplateRepository) Create(tpl *domain.NotificationTemplate) error {
+	return r.db.Create(tpl).Error
+}
+
+func (r *notificationTemplateRepository) Update(tpl *domain.NotificationTemplate) error {
+	return r.db.Save(tpl).Error
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"errors"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"gorm.io/gorm"
+)
+
+type oidcLoginRepository struct {
+	db *gorm.DB
+}
+
+func NewOIDCLoginRepository(db *gorm.DB) ports.OIDCLoginRepository {
+	return &oidcLoginRepository{db: db}
+}
+
+func (r *oidcLoginRepository) Create(login *domain.OIDCLogin) error {
+	return r.db.Create(login).Error
+}
+
+func (r *oidcLoginRepository) GetByStateHash(hash string) (*domain.OIDCLogin, error) {
+	var login domain.OIDCLogin
+	err := r.db.Where("state_hash = ?", hash).First(&login).Error
+	return &login, err
+}
+
+func (r *oidcLoginRepository) Complete(id uint, exchangeHash string, userID uint, expiresAt time.Time) (bool, error) {
+	result := r.db.Model(&domain.OIDCLogin{}).
+		Where("id = ? AND exchange_hash IS NULL", id).
+		Updates(map[string]interface{}{"exchange_hash": exchangeHash, "user_id": userID, "expires_at": expiresAt})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *oidcLoginRepository) ConsumeExchange(exchangeHash string, at time.Time) (*domain.OIDCLogin, error) {
+	var login domain.OIDCLogin
+	if err := r.db.Where("exchange_hash = ?", exchangeHash).First(&login).Error; err != nil {
+		return nil, err
+	}
+
+	result := r.db.Model(&domain.OIDCLogin{}).
+		Where("id = ? AND consumed_at IS NULL AND expires_at > ?", login.ID, at).
+		Update("consumed_at", at)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("exchange code already used or expired")
+	}
+	return &login, nil
+}
+
+func (r *oidcLoginRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&domain.OIDCLogin{}).Error
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) ports.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Create(msg *domain.OutboxMessage) error {
+	return r.db.Create(msg).Error
+}
+
+func (r *outboxRepository) CreateUnique(msg *domain.OutboxMessage) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "dedup_key"}}, DoNothing: true}).Create(msg)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *outboxRepository) GetByID(id uint) (*domain.OutboxMessage, error) {
+	var msg domain.OutboxMessage
+	err := r.db.First(&msg, id).Error
+	return &msg, err
+}
+
+// ClaimDue: ใช้ FOR UPDATE SKIP LOCKED แล้วเลื่อน next_attempt_at ออกไป (lease)
+// ถ้า process ตายระหว่างส่ง ข้อความจะถูกดึงไปส่งใหม่เมื่อ lease หมดอายุ
+func (r *outboxRepository) ClaimDue(now time.Time, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	var msgs []domain.OutboxMessage
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND next_attempt_at <= ?", []string{domain.OutboxStatusPending, domain.OutboxStatusFailed}, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&msgs).Error
+		if err != nil || len(msgs) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(msgs))
+		for i, m := range msgs {
+			ids[i] = m.ID
+		}
+		return tx.Model(&domain.OutboxMessage{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return msgs, err
+}
+
+func (r *outboxRepository) List(status string, limit int) ([]domain.OutboxMessage, error) {
+	var msgs []domain.OutboxMessage
+	query := r.db.Order("created_at desc").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&msgs).Error
+	return msgs, err
+}
+
+func (r *outboxRepository) Update(msg *domain.OutboxMessage) error {
+	return r.db.Save(msg).Error
+}
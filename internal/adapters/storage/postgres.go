@@ -11,7 +11,8 @@ import (
 )
 
 type Database struct {
-	DB *gorm.DB
+	DB  *gorm.DB
+	DSN string // ใช้เปิด connection แยกสำหรับ LISTEN (SettingChangeBus)
 }
 
 // NewDatabase ทำหน้าที่เชื่อมต่อ Database และ Return connection กลับไป
@@ -49,5 +50,5 @@ func NewDatabase(host, user, password, dbName, port, sslMode string) *Database {
 	}
 	log.Println("Migrations completed!")
 
-	return &Database{DB: db}
+	return &Database{DB: db, DSN: dsn}
 }
\ No newline at end of file
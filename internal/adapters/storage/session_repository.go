@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"gorm.io/gorm"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) ports.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(session *domain.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepository) GetByID(id string) (*domain.Session, error) {
+	var session domain.Session
+	err := r.db.Where("id = ?", id).First(&session).Error
+	return &session, err
+}
+
+func (r *sessionRepository) GetActiveByUser(userID uint, now time.Time) ([]domain.Session, error) {
+	var sessions []domain.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at desc").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *sessionRepository) Touch(id string, at, since time.Time) error {
+	return r.db.Model(&domain.Session{}).
+		Where("id = ? AND last_seen_at < ?", id, since).
+		Update("last_seen_at", at).Error
+}
+
+func (r *sessionRepository) Refreshed(id, device string, client ports.ClientInfo, at, expiresAt time.Time) error {
+	return r.db.Model(&domain.Session{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"device":       device,
+			"ip_address":   client.IP,
+			"user_agent":   client.UserAgent,
+			"last_seen_at": at,
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+func (r *sessionRepository) Revoke(id string, at time.Time) error {
+	return r.db.Model(&domain.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+func (r *sessionRepository) RevokeByUser(userID uint, at time.Time) error {
+	return r.db.Model(&domain.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
+
+func (r *sessionRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&domain.Session{}).Error
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	settingsChannel         = "settings_changed"
+	settingsNotifyMaxLength = 7000 // payload ของ NOTIFY ต้องไม่เกิน 8000 bytes
+	settingsListenMaxDelay  = 30 * time.Second
+)
+
+type settingChangeMessage struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+type settingChangeBus struct {
+	db     *gorm.DB
+	dsn    string
+	origin string // ไม่ต้องล้าง cache ซ้ำเมื่อได้รับการแจ้งเตือนที่ตัวเองส่ง
+}
+
+func NewSettingChangeBus(db *gorm.DB, dsn string) ports.SettingChangeBus {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return &settingChangeBus{db: db, dsn: dsn, origin: hex.EncodeToString(id)}
+}
+
+func (b *settingChangeBus) Publish(keys []string) error {
+	payload, err := json.Marshal(settingChangeMessage{Origin: b.origin, Keys: keys})
+	if err != nil {
+		return err
+	}
+	if len(payload) > settingsNotifyMaxLength {
+		// รายชื่อยาวเกินไป ให้ instance อื่นโหลดใหม่ทั้งหมด
+		payload, _ = json.Marshal(settingChangeMessage{Origin: b.origin})
+	}
+	return b.db.Exec("SELECT pg_notify(?, ?)", settingsChannel, string(payload)).Error
+}
+
+func (b *settingChangeBus) Listen(ctx context.Context, onChange ports.SettingChangeFunc) {
+	delay := time.Second
+	for {
+		connected, err := b.listen(ctx, onChange)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			delay = time.Second
+		}
+		log.Printf("Settings listener disconnected: %v (retrying in %s)", err, delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > settingsListenMaxDelay {
+			delay = settingsListenMaxDelay
+		}
+	}
+}
+
+// listen ใช้ connection แยกจาก pool ของ GORM เพราะ LISTEN ผูกกับ connection ที่สั่ง
+func (b *settingChangeBus) listen(ctx context.Context, onChange ports.SettingChangeFunc) (bool, error) {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+settingsChannel); err != nil {
+		return false, err
+	}
+
+	// ระหว่างที่ไม่ได้เชื่อมต่ออาจพลาดการแจ้งเตือน จึงล้าง cache ทั้งหมดทุกครั้งที่เชื่อมต่อได้
+	onChange(nil)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+
+		var msg settingChangeMessage
+		if err := json.Unmarshal([]byte(notification.Payload), &msg); err != nil {
+			onChange(nil)
+			continue
+		}
+		if msg.Origin == b.origin {
+			continue
+		}
+		onChange(msg.Keys)
+	}
+}
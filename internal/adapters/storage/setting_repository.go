@@ -5,6 +5,7 @@ import (
 	"tunorth-brms-backend/internal/core/ports"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type settingRepository struct {
@@ -31,14 +32,57 @@ func (r *settingRepository) Update(setting *domain.Setting) error {
 	return r.db.Save(setting).Error
 }
 
-func (r *settingRepository) UpdateBatch(settings []domain.Setting) error {
+func (r *settingRepository) UpdateBatch(settings []domain.Setting, changeSet *domain.SettingChangeSet) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		for _, s := range settings {
-			// Update only value
-			if err := tx.Model(&domain.Setting{}).Where("setting_name = ?", s.SettingName).Update("setting_value", s.SettingValue).Error; err != nil {
-				return err
-			}
+		return updateSettings(tx, settings, changeSet)
+	})
+}
+
+// updateSettings บันทึกค่าที่เปลี่ยนจริงพร้อมประวัติ ใช้ร่วมกับ Transaction ของ configBundleRepository
+func updateSettings(tx *gorm.DB, settings []domain.Setting, changeSet *domain.SettingChangeSet) error {
+	changeSet.Changes = nil
+	for _, s := range settings {
+		// ล็อกแถวไว้อ่านค่าเดิม ป้องกันการบันทึกพร้อมกันทำให้ประวัติผิด
+		var current domain.Setting
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "setting_name = ?", s.SettingName).Error; err != nil {
+			return err
+		}
+		// secret: Service ตัดค่าที่ plaintext ไม่เปลี่ยนออกแล้ว ciphertext ที่ต่างกันจึงเป็นการเปลี่ยนจริง
+		if current.SettingValue == s.SettingValue {
+			continue
+		}
+
+		// Update only value
+		if err := tx.Model(&domain.Setting{}).Where("setting_name = ?", s.SettingName).Update("setting_value", s.SettingValue).Error; err != nil {
+			return err
 		}
+		changeSet.Changes = append(changeSet.Changes, domain.SettingChange{
+			SettingName: s.SettingName,
+			OldValue:    current.SettingValue,
+			NewValue:    s.SettingValue,
+			Secret:      current.Visibility == domain.SettingVisibilitySecret,
+		})
+	}
+	if len(changeSet.Changes) == 0 {
 		return nil
-	})
+	}
+	return tx.Create(changeSet).Error
+}
+
+func (r *settingRepository) GetHistory(settingName string, limit int) ([]domain.SettingChangeSet, error) {
+	var sets []domain.SettingChangeSet
+	query := r.db.Preload("Actor").Preload("Changes").Order("created_at desc, id desc").Limit(limit)
+	if settingName != "" {
+		query = query.
+			Where("id IN (?)", r.db.Model(&domain.SettingChange{}).Select("change_set_id").Where("setting_name = ?", settingName)).
+			Preload("Changes", "setting_name = ?", settingName)
+	}
+	err := query.Find(&sets).Error
+	return sets, err
+}
+
+func (r *settingRepository) GetChangeSet(id uint) (*domain.SettingChangeSet, error) {
+	var set domain.SettingChangeSet
+	err := r.db.Preload("Changes").First(&set, id).Error
+	return &set, err
 }
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"gorm.io/gorm"
+)
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) ports.TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+func (r *twoFactorRepository) Get(userID uint) (*domain.UserTwoFactor, error) {
+	var tf domain.UserTwoFactor
+	err := r.db.Where("user_id = ?", userID).First(&tf).Error
+	return &tf, err
+}
+
+func (r *twoFactorRepository) Save(tf *domain.UserTwoFactor) error {
+	return r.db.Save(tf).Error
+}
+
+func (r *twoFactorRepository) Delete(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.TwoFactorChallenge{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&domain.UserTwoFactor{}).Error
+	})
+}
+
+func (r *twoFactorRepository) UseStep(userID uint, step int64) (bool, error) {
+	result := r.db.Model(&domain.UserTwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(userID uint, hashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]domain.TwoFactorRecoveryCode, 0, len(hashes))
+		for _, h := range hashes {
+			codes = append(codes, domain.TwoFactorRecoveryCode{UserID: userID, CodeHash: h})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(userID uint, hash string, at time.Time) (bool, error) {
+	result := r.db.Model(&domain.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *twoFactorRepository) CountRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *twoFactorRepository) CreateChallenge(challenge *domain.TwoFactorChallenge) error {
+	return r.db.Create(challenge).Error
+}
+
+func (r *twoFactorRepository) GetChallenge(hash string) (*domain.TwoFactorChallenge, error) {
+	var challenge domain.TwoFactorChallenge
+	err := r.db.Where("token_hash = ?", hash).First(&challenge).Error
+	return &challenge, err
+}
+
+func (r *twoFactorRepository) IncrementChallengeAttempts(id uint) error {
+	return r.db.Model(&domain.TwoFactorChallenge{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+func (r *twoFactorRepository) ConsumeChallenge(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&domain.TwoFactorChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *twoFactorRepository) DeleteExpiredChallenges(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&domain.TwoFactorChallenge{}).Error
+}
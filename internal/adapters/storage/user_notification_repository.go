@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"gorm.io/gorm"
+)
+
+type userNotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewUserNotificationRepository(db *gorm.DB) ports.UserNotificationRepository {
+	return &userNotificationRepository{db: db}
+}
+
+// CreateBatch: บันทึกทีเดียวทั้งชุด ถ้าล้มเหลวจะไม่มีรายการใดถูกบันทึก (retry แล้วไม่ซ้ำ)
+func (r *userNotificationRepository) CreateBatch(items []domain.UserNotification) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.Create(&items).Error
+}
+
+func (r *userNotificationRepository) ListByUser(userID uint, unreadOnly bool, limit, offset int) ([]domain.UserNotification, int64, error) {
+	var items []domain.UserNotification
+	var total int64
+
+	query := r.db.Model(&domain.UserNotification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at desc").Limit(limit).Offset(offset).Find(&items).Error
+	return items, total, err
+}
+
+func (r *userNotificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.UserNotification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *userNotificationRepository) MarkRead(userID, id uint) (bool, error) {
+	result := r.db.Model(&domain.UserNotification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *userNotificationRepository) MarkAllRead(userID uint) (int64, error) {
+	result := r.db.Model(&domain.UserNotification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
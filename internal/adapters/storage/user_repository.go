@@ -50,10 +50,21 @@ func (r *userRepository) GetAll() ([]domain.User, error) {
 	return users, err
 }
 
+func (r *userRepository) GetByRoles(roles ...string) ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.Omit("password").Where("role IN ?", roles).Find(&users).Error
+	return users, err
+}
+
 func (r *userRepository) Update(user *domain.User) error {
 	return r.db.Save(user).Error
 }
 
+func (r *userRepository) IncrementTokenVersion(id uint) error {
+	return r.db.Model(&domain.User{}).Where("id = ?", id).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
+}
+
 func (r *userRepository) Delete(id uint) error {
 	return r.db.Delete(&domain.User{}, id).Error
 }
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) ports.WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) GetAll() ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	err := r.db.Order("id asc").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *webhookRepository) GetActive() ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	err := r.db.Where("is_active = ?", true).Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *webhookRepository) GetByID(id uint) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := r.db.First(&webhook, id).Error
+	return &webhook, err
+}
+
+func (r *webhookRepository) Create(webhook *domain.Webhook) error {
+	return r.db.Create(webhook).Error
+}
+
+func (r *webhookRepository) Update(webhook *domain.Webhook) error {
+	return r.db.Save(webhook).Error
+}
+
+// Delete: ลบ Webhook พร้อมประวัติการส่ง
+func (r *webhookRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&domain.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Webhook{}, id).Error
+	})
+}
+
+func (r *webhookRepository) CreateDeliveries(deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Create(&deliveries).Error
+}
+
+func (r *webhookRepository) GetDelivery(id uint) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := r.db.First(&delivery, id).Error
+	return &delivery, err
+}
+
+func (r *webhookRepository) ClaimDueDeliveries(now time.Time, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND next_attempt_at <= ?", []string{domain.OutboxStatusPending, domain.OutboxStatusFailed}, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i, d := range deliveries {
+			ids[i] = d.ID
+		}
+		return tx.Model(&domain.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return deliveries, err
+}
+
+func (r *webhookRepository) ListDeliveries(webhookID uint, status string, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	query := r.db.Where("webhook_id = ?", webhookID).Order("created_at desc").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery *domain.WebhookDelivery) error {
+	return r.db.Save(delivery).Error
+}
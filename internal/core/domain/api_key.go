@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix ขึ้นต้นทุก API Key (ใช้แยกจาก JWT ใน Authorization header)
+const APIKeyPrefix = "brms_"
+
+// ขอบเขต (scope) ของ API Key
+const (
+	ScopeBookingsRead  = "bookings:read"
+	ScopeBookingsWrite = "bookings:write"
+	ScopeReportsRead   = "reports:read"
+)
+
+// APIKeyScopePermissions สิทธิ์ที่แต่ละ scope เปิดให้
+// สิทธิ์จริง = สิทธิ์ของ scope ∩ สิทธิ์ของ role ปัจจุบันของเจ้าของ Key
+// (ไม่มี scope ใดให้ PermProfile ดังนั้น API Key จัดการบัญชี / สร้าง Key ใหม่ไม่ได้)
+var APIKeyScopePermissions = map[string][]string{
+	ScopeBookingsRead:  {PermResourcesView, PermBookingsView},
+	ScopeBookingsWrite: {PermResourcesView, PermBookingsView, PermBookingsCreate},
+	ScopeReportsRead:   {PermReportsView},
+}
+
+// APIKey แทนตาราง api_keys: Personal Access Token สำหรับสคริปต์ / ระบบภายนอก
+// เก็บเฉพาะ hash ตัว Key จริงแสดงครั้งเดียวตอนสร้าง
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	User       *User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Name       string     `gorm:"not null" json:"name"`
+	Prefix     string     `gorm:"type:varchar(16)" json:"prefix"` // ส่วนต้นของ Key ไว้ให้ผู้ใช้จำได้ เช่น brms_1a2b3c
+	KeyHash    string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Scopes     string     `gorm:"not null" json:"scopes"` // คั่นด้วยจุลภาค
+	ExpiresAt  *time.Time `json:"expires_at"`             // nil = ไม่หมดอายุ
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (k *APIKey) ScopeList() []string {
+	var scopes []string
+	for _, s := range strings.Split(k.Scopes, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+// Allows: scope ใดของ Key นี้เปิดสิทธิ์ permission หรือไม่
+func (k *APIKey) Allows(permission string) bool {
+	for _, scope := range k.ScopeList() {
+		for _, p := range APIKeyScopePermissions[scope] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// สถานะการตอบรับคำเชิญเข้าร่วมประชุม
+const (
+	AttendeeStatusInvited  = "invited"
+	AttendeeStatusAccepted = "accepted"
+	AttendeeStatusDeclined = "declined"
+)
+
+// BookingAttendee แทนตาราง booking_attendees (ผู้ใช้ที่ผู้จองเชิญเข้าร่วมประชุม)
+// ผู้ที่ตอบรับแล้วได้รับการเตือนก่อนเริ่มประชุมเหมือนผู้จอง
+type BookingAttendee struct {
+	BookingID   uint       `gorm:"primaryKey" json:"booking_id"`
+	Booking     *Booking   `gorm:"foreignKey:BookingID" json:"booking,omitempty"`
+	UserID      uint       `gorm:"primaryKey;index" json:"user_id"`
+	User        *User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Status      string     `gorm:"type:varchar(10);default:'invited'" json:"status"`
+	RespondedAt *time.Time `json:"responded_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// AttendeeView ข้อมูลผู้เข้าร่วมที่แสดงให้ผู้เกี่ยวข้องกับการจองเห็น (ไม่รวมอีเมล / เบอร์โทร / Chat ID)
+type AttendeeView struct {
+	UserID      uint       `json:"user_id"`
+	FullName    string     `json:"full_name"`
+	Department  string     `json:"department"`
+	Status      string     `json:"status"`
+	RespondedAt *time.Time `json:"responded_at"`
+}
+
+func (a BookingAttendee) View() AttendeeView {
+	view := AttendeeView{UserID: a.UserID, Status: a.Status, RespondedAt: a.RespondedAt}
+	if a.User != nil {
+		view.FullName = a.User.FullName
+		view.Department = a.User.Department
+	}
+	return view
+}
@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+// BookingEvent ข้อมูลการจองที่ส่งออกไปนอกระบบ (ไม่รวมข้อมูลติดต่อส่วนตัวของผู้จอง)
+type BookingEvent struct {
+	ID           uint      `json:"id"`
+	Subject      string    `json:"subject"`
+	RoomID       uint      `json:"room_id"`
+	RoomName     string    `json:"room_name"`
+	UserID       uint      `json:"user_id"`
+	UserName     string    `json:"user_name"`
+	Department   string    `json:"department"`
+	Attendees    int       `json:"attendees"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	Status       string    `json:"status"`
+	ApproverID   *uint     `json:"approver_id"`
+	RejectReason string    `json:"reject_reason"`
+	Note         string    `json:"note"`
+	ResourceText string    `json:"resource_text"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func NewBookingEvent(b *Booking) BookingEvent {
+	return BookingEvent{
+		ID:           b.ID,
+		Subject:      b.Subject,
+		RoomID:       b.RoomID,
+		RoomName:     b.Room.RoomName,
+		UserID:       b.UserID,
+		UserName:     b.User.FullName,
+		Department:   b.Department,
+		Attendees:    b.Attendees,
+		StartTime:    b.StartTime,
+		EndTime:      b.EndTime,
+		Status:       b.Status,
+		ApproverID:   b.ApproverID,
+		RejectReason: b.RejectReason,
+		Note:         b.Note,
+		ResourceText: b.ResourceText,
+		UpdatedAt:    b.UpdatedAt,
+	}
+}
+
+// Public: ตัดข้อมูลที่ไม่ควรเปิดให้ผู้ที่ไม่ได้ Login เห็น (เหลือเท่าที่ปฏิทินต้องใช้)
+func (e BookingEvent) Public() BookingEvent {
+	return BookingEvent{
+		ID:        e.ID,
+		Subject:   e.Subject,
+		RoomID:    e.RoomID,
+		RoomName:  e.RoomName,
+		StartTime: e.StartTime,
+		EndTime:   e.EndTime,
+		Status:    e.Status,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// แพลตฟอร์มแชทที่รองรับ (Incoming Webhook)
+const (
+	ChatPlatformSlack   = "slack"
+	ChatPlatformDiscord = "discord"
+	ChatPlatformTeams   = "teams"
+)
+
+var ChatPlatforms = []string{ChatPlatformSlack, ChatPlatformDiscord, ChatPlatformTeams}
+
+// ChannelChatWebhook ช่องทางภายในสำหรับข้อความใน Outbox ที่ส่งไปยัง ChatWebhook (ไม่ใช่ค่าที่ผู้ใช้เลือกรับ)
+const ChannelChatWebhook = "chat_webhook"
+
+// ChatGroupEvents เหตุการณ์เดียวกับที่ส่งเข้ากลุ่ม Telegram
+var ChatGroupEvents = []string{EventBookingCreated, EventBookingStatusChanged, EventBookingCancelled}
+
+// ChatWebhook แทนตาราง chat_webhooks (ห้องแชทของหน่วยงาน/ห้องประชุม)
+// RoomID = nil และ Department = "" หมายถึงรับทุกการจอง
+type ChatWebhook struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Name       string    `gorm:"type:varchar(100);not null" json:"name"`
+	Platform   string    `gorm:"type:varchar(20);not null" json:"platform"` // slack, discord, teams
+	URL        string    `gorm:"type:text;not null" json:"-"`               // ใครมี URL ก็โพสต์เข้าห้องได้ จึงเก็บแบบเข้ารหัส และแสดงครั้งเดียวตอนสร้าง
+	HasURL     bool      `gorm:"-" json:"has_url"`
+	URLHost    string    `gorm:"-" json:"url_host"` // เช่น hooks.slack.com ไว้ให้ผู้ดูแลแยกแยะ
+	RoomID     *uint     `gorm:"index" json:"room_id"`
+	Department string    `json:"department"`
+	Events     []string  `gorm:"type:text;serializer:json" json:"events"` // ว่าง = ทุกเหตุการณ์ใน ChatGroupEvents
+	Language   string    `gorm:"type:varchar(5)" json:"language"`         // ว่าง = ภาษาเริ่มต้นของระบบ
+	IsActive   bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Matches: ห้องแชทนี้ต้องได้รับเหตุการณ์ของการจองนี้หรือไม่
+func (w *ChatWebhook) Matches(eventType string, booking *Booking) bool {
+	if !w.IsActive {
+		return false
+	}
+	if w.RoomID != nil && *w.RoomID != booking.RoomID {
+		return false
+	}
+	if w.Department != "" && !strings.EqualFold(strings.TrimSpace(w.Department), strings.TrimSpace(booking.Department)) {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact เติม HasURL / URLHost สำหรับส่งออกทาง API จาก URL ที่ถอดรหัสแล้ว (ตัว URL ไม่ถูกส่งออก)
+func (w *ChatWebhook) Redact(plainURL string) {
+	w.HasURL = w.URL != ""
+	w.URLHost = ""
+	if u, err := url.Parse(plainURL); err == nil {
+		w.URLHost = u.Host
+	}
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+// ConfigBundleVersion รุ่นของรูปแบบไฟล์ (เพิ่มเมื่อเปลี่ยนโครงสร้างแบบที่ไฟล์เก่าอ่านไม่ได้)
+const ConfigBundleVersion = 1
+
+// ConfigBundle ค่าตั้งค่าของ instance หนึ่ง ใช้ตั้งค่า instance ใหม่ (เช่น วิทยาเขตใหม่) โดยไม่ต้องกรอกซ้ำ
+// ไม่มีค่า secret (Token / รหัสผ่าน) ต้องตั้งใหม่ที่ปลายทางเอง
+type ConfigBundle struct {
+	Version    int               `json:"version" yaml:"version"`
+	ExportedAt time.Time         `json:"exported_at" yaml:"exported_at"`
+	Source     string            `json:"source,omitempty" yaml:"source,omitempty"` // site_name ของต้นทาง
+	Settings   map[string]string `json:"settings" yaml:"settings"`
+	Rooms      []BundleRoom      `json:"rooms,omitempty" yaml:"rooms,omitempty"`
+	Resources  []BundleResource  `json:"resources,omitempty" yaml:"resources,omitempty"`
+}
+
+// BundleRoom ห้องใน Bundle (จับคู่กับห้องเดิมที่ปลายทางด้วยชื่อ เพราะ ID ต่างกัน)
+type BundleRoom struct {
+	RoomName    string `json:"room_name" yaml:"room_name"`
+	Description string `json:"description" yaml:"description"`
+	Capacity    int    `json:"capacity" yaml:"capacity"`
+	ImagePath   string `json:"image_path" yaml:"image_path"`
+	Color       string `json:"color" yaml:"color"`
+	Status      string `json:"status" yaml:"status"`
+}
+
+// BundleResource อุปกรณ์ใน Bundle (จับคู่ด้วยชื่อ)
+type BundleResource struct {
+	ResourceName string `json:"resource_name" yaml:"resource_name"`
+	Type         string `json:"type" yaml:"type"`
+}
+
+const (
+	BundleActionCreate = "create"
+	BundleActionUpdate = "update"
+)
+
+// ConfigBundleChange รายการที่จะเปลี่ยน/เปลี่ยนแล้ว (Old ว่างเมื่อสร้างใหม่)
+type ConfigBundleChange struct {
+	Name   string      `json:"name"`
+	Action string      `json:"action"`
+	Old    interface{} `json:"old,omitempty"`
+	New    interface{} `json:"new"`
+}
+
+// ConfigBundleDiff ผลการนำเข้า (dry run = ตัวอย่างสิ่งที่จะเปลี่ยน ยังไม่บันทึก)
+// รายการที่ตรงกับของเดิมอยู่แล้วไม่ถูกแสดง และไม่มีการลบสิ่งที่ไม่อยู่ใน Bundle
+type ConfigBundleDiff struct {
+	DryRun      bool                 `json:"dry_run"`
+	Settings    []ConfigBundleChange `json:"settings"`
+	Rooms       []ConfigBundleChange `json:"rooms"`
+	Resources   []ConfigBundleChange `json:"resources"`
+	ChangeSetID *uint                `json:"change_set_id,omitempty"` // ประวัติค่าตั้งค่า (ย้อนกลับได้ที่ /api/settings/history)
+}
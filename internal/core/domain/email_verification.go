@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// EmailVerificationToken แทนตาราง email_verification_tokens (เก็บเฉพาะ hash ใช้ได้ครั้งเดียว)
+type EmailVerificationToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Email     string     `json:"-"` // อีเมลใหม่ที่ลิงก์นี้ยืนยัน (เปลี่ยนอีเมล) ว่าง = ยืนยันตอนสมัคร
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -14,5 +14,7 @@ type Log struct {
 	Description string `json:"description"` // Details
 	IPAddress   string `json:"ip_address"`
 	UserAgent   string `json:"user_agent"` // Optional: Browser info
+	ImpersonatorID *uint `json:"impersonator_id"` // ผู้ดูแลที่สวมสิทธิ์ UserID อยู่ตอนทำรายการนี้
+	Impersonator   *User `json:"impersonator,omitempty" gorm:"foreignKey:ImpersonatorID"`
 	CreatedAt   time.Time `json:"created_at"`
 }
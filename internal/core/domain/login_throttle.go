@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// ขอบเขตการนับ Login ที่ล้มเหลว
+const (
+	ThrottleScopeAccount = "account" // Key = username (ตัวพิมพ์เล็ก) หรือชื่อที่กรอกถ้าไม่มีบัญชีนี้
+	ThrottleScopeIP      = "ip"
+)
+
+// LoginThrottle แทนตาราง login_throttles: จำนวนครั้งที่ Login ล้มเหลวต่อบัญชี / ต่อ IP
+type LoginThrottle struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Scope         string     `gorm:"type:varchar(10);not null;uniqueIndex:idx_login_throttle_key" json:"scope"`
+	Key           string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_login_throttle_key" json:"key"`
+	UserID        *uint      `json:"user_id"`
+	Failures      int        `gorm:"default:0" json:"failures"`
+	LastFailedAt  time.Time  `json:"last_failed_at"`
+	LockedUntil   *time.Time `gorm:"index" json:"locked_until"`
+	LastIP        string     `json:"last_ip"`
+	LastUserAgent string     `json:"last_user_agent"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package domain
+
+// NotificationPreference แทนตาราง notification_preferences
+// เลือกรับ/ไม่รับ แยกตามประเภทเหตุการณ์และช่องทาง (ถ้าไม่มีแถวใช้ค่า DefaultChannelEnabled)
+type NotificationPreference struct {
+	UserID    uint   `gorm:"primaryKey" json:"-"`
+	EventType string `gorm:"primaryKey;type:varchar(50)" json:"event_type"`
+	Channel   string `gorm:"primaryKey;type:varchar(20)" json:"channel"`
+	Enabled   bool   `json:"enabled"`
+}
+
+// NotificationQuietHours ช่วงเวลาห้ามรบกวน (ไม่มีผลกับการแจ้งเตือนในระบบ)
+type NotificationQuietHours struct {
+	UserID  uint   `gorm:"primaryKey" json:"-"`
+	Enabled bool   `json:"enabled"`
+	Start   string `gorm:"type:varchar(5)" json:"start"` // HH:MM เช่น 22:00
+	End     string `gorm:"type:varchar(5)" json:"end"`   // HH:MM เช่น 07:00
+}
+
+// DefaultChannelEnabled ค่าเริ่มต้นเมื่อผู้ใช้ยังไม่เคยตั้งค่า
+func DefaultChannelEnabled(channel string) bool {
+	return channel == ChannelInApp || channel == ChannelTelegram
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// ChannelTelegramGroup ข้อความเข้ากลุ่ม Telegram ของระบบ (แยกจากการส่งรายบุคคล)
+const ChannelTelegramGroup = "telegram_group"
+
+// ภาษาที่รองรับ
+const (
+	LanguageThai    = "th"
+	LanguageEnglish = "en"
+)
+
+var NotificationLanguages = []string{LanguageThai, LanguageEnglish}
+
+// NotificationTemplate แทนตาราง notification_templates (Go template แยกตามเหตุการณ์ ช่องทาง และภาษา)
+type NotificationTemplate struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	EventType string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_notification_template_key" json:"event_type"`
+	Channel   string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_notification_template_key" json:"channel"`
+	Language  string    `gorm:"type:varchar(5);not null;uniqueIndex:idx_notification_template_key" json:"language"`
+	Subject   string    `json:"subject"`               // หัวข้ออีเมล / หัวข้อแจ้งเตือนในระบบ
+	Body      string    `gorm:"type:text" json:"body"` // Telegram ใช้ html/template ช่องทางอื่นใช้ text/template
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
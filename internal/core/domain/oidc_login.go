@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// OIDCLogin แทนตาราง oidc_logins: สถานะของการ Login ผ่าน OpenID Connect หนึ่งครั้ง
+// state / code_verifier ใช้ตอน callback, exchange_hash คือรหัสใช้ครั้งเดียวที่ส่งให้ Frontend แลก Token
+type OIDCLogin struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	StateHash    string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Nonce        string     `gorm:"not null" json:"-"`
+	CodeVerifier string     `gorm:"not null" json:"-"`
+	BrowserHash  string     `gorm:"type:varchar(64)" json:"-"` // hash ของค่าใน Cookie ของ Browser ที่เริ่ม Login (กัน Login CSRF)
+	ExchangeHash *string    `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	UserID       *uint      `json:"user_id"`
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"expires_at"`
+	ConsumedAt   *time.Time `json:"consumed_at"`
+	IPAddress    string     `json:"ip_address"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
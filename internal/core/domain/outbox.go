@@ -0,0 +1,62 @@
+package domain
+
+import "time"
+
+// สถานะของข้อความใน Outbox
+const (
+	OutboxStatusPending = "pending" // รอส่ง
+	OutboxStatusFailed  = "failed"  // ส่งไม่สำเร็จ รอ retry
+	OutboxStatusSent    = "sent"    // ส่งสำเร็จ
+	OutboxStatusDead    = "dead"    // เกินจำนวนครั้งสูงสุด (Dead-letter)
+)
+
+// ประเภทเหตุการณ์ที่ทำให้เกิดการแจ้งเตือน
+const (
+	EventBookingCreated       = "booking.created"
+	EventBookingStatusChanged = "booking.status_changed"
+	EventBookingReminder      = "booking.reminder"
+	EventBookingCancelled     = "booking.cancelled"
+	EventDailyDigest          = "booking.digest"
+)
+
+// ช่องทางการส่ง
+const (
+	ChannelTelegram = "telegram"
+	ChannelInApp    = "in_app"
+	ChannelEmail    = "email"
+	ChannelLine     = "line"
+)
+
+// NotificationChannels ช่องทางที่ทุกเหตุการณ์จะถูกส่งออกไป
+var NotificationChannels = []string{ChannelTelegram, ChannelInApp, ChannelEmail, ChannelLine}
+
+// NotificationEvents เหตุการณ์ที่ผู้ใช้เลือกรับ/ไม่รับได้
+var NotificationEvents = []string{EventBookingCreated, EventBookingStatusChanged, EventBookingReminder, EventBookingCancelled, EventDailyDigest}
+
+// OutboxMessage แทนตาราง outbox_messages
+// ถูกเขียนใน Transaction เดียวกับการจอง แล้ว Dispatcher จะดึงไปส่งทีหลัง
+type OutboxMessage struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	EventType     string     `gorm:"type:varchar(50);not null;index" json:"event_type"`
+	Channel       string     `gorm:"type:varchar(20);not null" json:"channel"`
+	BookingID     *uint      `gorm:"index" json:"booking_id"`
+	RecipientID   *uint      `gorm:"index" json:"recipient_id"`                                // nil = ยังไม่แตกเป็นรายคน (ส่งเข้ากลุ่ม + fan-out)
+	ChatWebhookID *uint      `gorm:"index" json:"chat_webhook_id"`                             // ส่งไปยังห้องแชท Slack/Discord/Teams (Channel = chat_webhook)
+	Payload       string     `json:"payload"`                                                  // JSON ข้อมูล ณ เวลาที่เกิดเหตุการณ์ เช่น {"status":"approved"}
+	DedupKey      *string    `gorm:"type:varchar(191);uniqueIndex" json:"dedup_key,omitempty"` // กันส่งซ้ำข้าม instance
+	Status        string     `gorm:"type:varchar(20);default:'pending';index" json:"status"`
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `json:"max_attempts"`
+	NextAttemptAt time.Time  `gorm:"index" json:"next_attempt_at"`
+	LastError     string     `json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// OutboxPayload ข้อมูลประกอบเหตุการณ์ (เก็บเป็น JSON ใน OutboxMessage.Payload)
+type OutboxPayload struct {
+	Status        string `json:"status,omitempty"`         // สถานะ ณ เวลาที่เปลี่ยน
+	UserID        uint   `json:"user_id,omitempty"`        // ผู้รับ (เช่น การเตือนก่อนประชุม)
+	BeforeMinutes int    `json:"before_minutes,omitempty"` // เตือนล่วงหน้ากี่นาที
+}
@@ -0,0 +1,68 @@
+package domain
+
+// บทบาทของผู้ใช้ (User.Role)
+const (
+	RoleAdmin    = "admin"
+	RoleApprover = "approver"
+	RoleUser     = "user"
+)
+
+// สิทธิ์การใช้งาน (ผูกกับ Route ใน handlers/http/rbac.go)
+const (
+	PermProfile             = "profile"              // ข้อมูลส่วนตัว / การแจ้งเตือนของตัวเอง
+	PermBookingsView        = "bookings:view"        // ดูรายละเอียดการจอง (ปฏิทินรวมเป็น Public อยู่แล้ว)
+	PermBookingsCreate      = "bookings:create"      // จอง / แก้ไข / ยกเลิกการจอง (เจ้าของตรวจใน Service)
+	PermBookingsApprove     = "bookings:approve"     // อนุมัติ / ไม่อนุมัติ
+	PermResourcesView       = "resources:view"       // ดูรายการอุปกรณ์ (ใช้ตอนจอง)
+	PermReportsView         = "reports:view"         // Dashboard
+	PermRoomsManage         = "rooms:manage"         // เพิ่ม/แก้/ลบ ห้อง
+	PermResourcesManage     = "resources:manage"     // เพิ่ม/แก้/ลบ อุปกรณ์
+	PermUsersManage         = "users:manage"         // จัดการผู้ใช้
+	PermSettingsManage      = "settings:manage"      // ตั้งค่าระบบ
+	PermLogsView            = "logs:view"            // ประวัติการใช้งาน
+	PermNotificationsManage = "notifications:manage" // Template / Outbox การแจ้งเตือน
+	PermIntegrationsManage  = "integrations:manage"  // Webhook / Chat Webhook
+)
+
+// RolePermissions สิทธิ์ของแต่ละบทบาท
+var RolePermissions = map[string][]string{
+	RoleUser: {
+		PermProfile,
+		PermBookingsView,
+		PermBookingsCreate,
+		PermResourcesView,
+	},
+	RoleApprover: {
+		PermProfile,
+		PermBookingsView,
+		PermBookingsCreate,
+		PermResourcesView,
+		PermBookingsApprove,
+		PermReportsView,
+	},
+	RoleAdmin: {
+		PermProfile,
+		PermBookingsView,
+		PermBookingsCreate,
+		PermResourcesView,
+		PermBookingsApprove,
+		PermReportsView,
+		PermRoomsManage,
+		PermResourcesManage,
+		PermUsersManage,
+		PermSettingsManage,
+		PermLogsView,
+		PermNotificationsManage,
+		PermIntegrationsManage,
+	},
+}
+
+// HasPermission: บทบาทนี้มีสิทธิ์นี้หรือไม่ (บทบาทที่ไม่รู้จัก = ไม่มีสิทธิ์)
+func HasPermission(role, permission string) bool {
+	for _, p := range RolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// RefreshToken แทนตาราง refresh_tokens
+// เก็บเฉพาะ hash ของ token ทุกครั้งที่ใช้จะถูกหมุน (rotate) เป็นตัวใหม่ใน Family เดียวกัน
+// ถ้ามีการใช้ token ที่ถูกหมุนไปแล้วซ้ำ ถือว่าถูกขโมย และจะเพิกถอนทั้ง Family
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	FamilyID  string     `gorm:"type:varchar(64);not null;index" json:"family_id"` // 1 Family = 1 การ Login
+	TokenHash string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`    // ถูกหมุนเป็นตัวใหม่แล้ว
+	RevokedAt *time.Time `json:"revoked_at"` // Logout / เปลี่ยนรหัสผ่าน / ตรวจพบการใช้ซ้ำ
+	IPAddress string     `json:"ip_address"`
+	UserAgent string     `json:"user_agent"`
+	CreatedAt time.Time  `json:"created_at"`
+}
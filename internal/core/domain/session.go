@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// Session แทนตาราง sessions: 1 แถว = 1 การ Login (ID เดียวกับ FamilyID ของ Refresh Token และ "sid" ใน Access Token)
+type Session struct {
+	ID         string     `gorm:"type:varchar(64);primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Device     string     `json:"device"` // สรุปจาก User-Agent เช่น "Chrome on Windows"
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"index" json:"expires_at"` // เลื่อนทุกครั้งที่ Refresh
+	RevokedAt  *time.Time `json:"revoked_at"`
+	// ImpersonatorID ผู้ดูแลที่สวมสิทธิ์เป็นผู้ใช้นี้ (nil = ผู้ใช้ Login เอง)
+	ImpersonatorID *uint `json:"impersonator_id,omitempty"`
+	Current        bool  `gorm:"-" json:"current"` // เป็น Session ที่เรียก API นี้อยู่
+}
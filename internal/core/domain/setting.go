@@ -10,4 +10,33 @@ type Setting struct {
 	Type        string `json:"type"`        // e.g., "text", "number", "boolean", "image", "color"
 	Label       string `json:"label"`       // e.g., "System Name"
 	Description string `json:"description"` // e.g., "The name displayed on the login screen"
-}
\ No newline at end of file
+
+	// Visibility ใครเห็นค่านี้ได้: public (GET /api/settings/public), internal (ผู้ดูแลเท่านั้น),
+	// secret (เข้ารหัสในฐานข้อมูล และไม่ส่งค่ากลับทาง API เลย แจ้งเพียง is_set)
+	Visibility string `gorm:"type:varchar(10);default:'internal'" json:"visibility"`
+	IsSet      bool   `gorm:"-" json:"is_set"`           // secret: มีค่าแล้วหรือยัง
+	Clear      bool   `gorm:"-" json:"clear,omitempty"` // PUT /api/settings: ล้างค่า secret (ค่าว่าง = ไม่เปลี่ยน)
+}
+
+// SettingField คำอธิบายของค่าตั้งค่า 1 ตัว (GET /api/settings/schema ให้หน้าตั้งค่า render ฟอร์มและตรวจค่าเบื้องต้น)
+type SettingField struct {
+	Name        string   `json:"name"`
+	Group       string   `json:"group"`
+	Type        string   `json:"type"` // text, number, boolean, select, color, image, password
+	Label       string   `json:"label"`
+	Description string   `json:"description"`
+	Visibility  string   `json:"visibility"`
+	Default     string   `json:"default"`
+	Required    bool     `json:"required"`
+	Options     []string `json:"options,omitempty"` // select
+	Min         *int     `json:"min,omitempty"`     // number
+	Max         *int     `json:"max,omitempty"`     // number
+	Pattern     string   `json:"pattern,omitempty"` // regex ที่ค่าต้องตรง (text / color / image)
+	Hint        string   `json:"hint,omitempty"`    // ข้อความอธิบายเมื่อค่าไม่ตรง Pattern
+}
+
+const (
+	SettingVisibilityPublic   = "public"
+	SettingVisibilityInternal = "internal"
+	SettingVisibilitySecret   = "secret"
+)
\ No newline at end of file
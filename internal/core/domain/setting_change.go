@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+const (
+	SettingChangeSourceUpdate   = "update"   // PUT /api/settings
+	SettingChangeSourceRollback = "rollback" // ย้อนกลับจากประวัติ
+	SettingChangeSourceImport   = "import"   // นำเข้า Config Bundle
+
+	// SettingSecretMask แสดงแทนค่า secret ในประวัติ (ค่าว่าง = ไม่ได้ตั้งค่า/ล้างค่า ยังแสดงเป็นค่าว่าง)
+	SettingSecretMask = "********"
+)
+
+// SettingChangeSet การบันทึกค่าตั้งค่า 1 ครั้ง (หลายค่าพร้อมกัน) ย้อนกลับได้ทั้งชุดหรือทีละค่า
+type SettingChangeSet struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	ActorID    uint            `gorm:"index" json:"actor_id"`
+	Actor      *User           `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+	Source     string          `gorm:"type:varchar(20)" json:"source"`
+	RollbackOf *uint           `json:"rollback_of,omitempty"` // ชุดที่ถูกย้อนกลับ (Source = rollback)
+	Changes    []SettingChange `gorm:"foreignKey:ChangeSetID" json:"changes"`
+	CreatedAt  time.Time       `gorm:"index" json:"created_at"`
+}
+
+// SettingChange ค่าเดิมและค่าใหม่ของค่าตั้งค่า 1 ตัว
+// ค่า secret เก็บตามที่อยู่ในฐานข้อมูล (เข้ารหัส) และถูกแทนด้วย SettingSecretMask ก่อนส่งออกทาง API
+type SettingChange struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	ChangeSetID uint   `gorm:"index" json:"change_set_id"`
+	SettingName string `gorm:"index" json:"setting_name"`
+	OldValue    string `json:"old_value"`
+	NewValue    string `json:"new_value"`
+	Secret      bool   `json:"secret"`
+}
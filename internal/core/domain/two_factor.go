@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// UserTwoFactor แทนตาราง user_two_factors (TOTP ของผู้ใช้ 1 คนต่อ 1 แถว)
+// EnabledAt ว่าง = อยู่ระหว่างตั้งค่า (ยังไม่ได้ยืนยันรหัสจากแอป)
+type UserTwoFactor struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"not null;uniqueIndex" json:"user_id"`
+	Secret       string     `gorm:"not null" json:"-"` // Base32
+	EnabledAt    *time.Time `json:"enabled_at"`
+	LastUsedStep int64      `gorm:"default:0" json:"-"` // กันการใช้รหัสเดิมซ้ำภายใน 30 วินาที
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TwoFactorRecoveryCode แทนตาราง two_factor_recovery_codes (เก็บเฉพาะ hash ใช้ได้ครั้งเดียว)
+type TwoFactorRecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TwoFactorChallenge แทนตาราง two_factor_challenges: ขั้นที่สองของการ Login หลังรหัสผ่านถูกต้อง
+type TwoFactorChallenge struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Attempts  int        `gorm:"default:0" json:"attempts"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	IPAddress string     `json:"ip_address"`
+	CreatedAt time.Time  `json:"created_at"`
+}
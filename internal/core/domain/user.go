@@ -1,21 +1,79 @@
 package domain
 
 import (
-	"time"
 	"gorm.io/gorm"
+	"time"
 )
 
 // User struct แทนตาราง users
 type User struct {
-	ID           uint           `gorm:"primaryKey" json:"id"`
-	Username     string         `gorm:"unique;not null" json:"username"`
-	Password     string         `gorm:"not null" json:"-"` // json:"-" เพื่อไม่ให้ส่ง password กลับไปหน้าเว็บ
-	FullName     string         `gorm:"not null" json:"full_name"`
-	Department   string         `json:"department"`
-	Role         string         `gorm:"type:varchar(20);default:'user'" json:"role"` // admin, approver, user
-	Email        string         `gorm:"unique" json:"email"`
-	Tel          string         `json:"tel"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"` // Soft Delete (ลบแบบกู้คืนได้)
-}
\ No newline at end of file
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	Username        string         `gorm:"unique;not null" json:"username"`
+	Password        string         `gorm:"not null" json:"-"` // json:"-" เพื่อไม่ให้ส่ง password กลับไปหน้าเว็บ
+	FullName        string         `gorm:"not null" json:"full_name"`
+	Department      string         `json:"department"`
+	Role            string         `gorm:"type:varchar(20);default:'user'" json:"role"` // admin, approver, user
+	Email           string         `gorm:"unique" json:"email"`
+	Tel             string         `json:"tel"`
+	TelegramChatID  string         `json:"-"`                                                     // Chat ID ส่วนตัว สำหรับแจ้งเตือนรายบุคคล (เห็น/แก้ได้เฉพาะเจ้าของที่ /api/me)
+	LineUserID      string         `json:"-"`                                                     // LINE User ID สำหรับ Push Message (เห็น/แก้ได้เฉพาะเจ้าของที่ /api/me)
+	Language        string         `gorm:"type:varchar(5);default:'th'" json:"language"`          // ภาษาของข้อความแจ้งเตือน
+	TokenVersion    int            `gorm:"default:0" json:"-"`                                    // เพิ่มเมื่อต้องการเพิกถอน Token ทั้งหมดของผู้ใช้
+	Status          string         `gorm:"type:varchar(30);default:'active';index" json:"status"` // active, pending_verification, pending_approval, disabled
+	EmailVerifiedAt *time.Time     `json:"email_verified_at"`
+	PendingEmail    string         `json:"-"`                                                   // อีเมลใหม่ที่รอกดลิงก์ยืนยัน (เปลี่ยนที่ PUT /api/me)
+	AuthSource      string         `gorm:"type:varchar(20);default:'local'" json:"auth_source"` // local, ldap (ใช้รหัสผ่านจาก Directory) หรือ oidc (Login ผ่าน SSO เท่านั้น)
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"` // Soft Delete (ลบแบบกู้คืนได้)
+
+	// ImpersonatedBy มีค่าเฉพาะใน GET /api/me ระหว่างที่ผู้ดูแลสวมสิทธิ์เป็นผู้ใช้นี้
+	ImpersonatedBy *Impersonator `gorm:"-" json:"impersonated_by,omitempty"`
+}
+
+// Impersonator ผู้ดูแลที่กำลังสวมสิทธิ์ (แสดงแถบเตือนบนหน้าเว็บ)
+type Impersonator struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	FullName  string    `json:"full_name"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// สถานะบัญชี (สมัครเองต้องยืนยันอีเมล / รอผู้ดูแลอนุมัติก่อนจึงจะ Login ได้)
+const (
+	UserStatusActive              = "active"
+	UserStatusPendingVerification = "pending_verification"
+	UserStatusPendingApproval     = "pending_approval"
+	UserStatusDisabled            = "disabled"
+)
+
+var UserStatuses = []string{UserStatusActive, UserStatusPendingVerification, UserStatusPendingApproval, UserStatusDisabled}
+
+// แหล่งที่ใช้ตรวจรหัสผ่าน
+const (
+	AuthSourceLocal = "local"
+	AuthSourceLDAP  = "ldap"
+	AuthSourceOIDC  = "oidc"
+)
+
+// IsLocal: บัญชีที่ตรวจรหัสผ่านกับฐานข้อมูลของระบบเอง (รวมบัญชีเก่าที่ยังไม่มีค่า)
+func (u *User) IsLocal() bool {
+	return u.AuthSource == "" || u.AuthSource == AuthSourceLocal
+}
+
+// Public: ข้อมูลผู้จองที่แสดงบนปฏิทินได้ (ไม่รวมสถานะบัญชีและแหล่ง Login)
+func (u User) Public() User {
+	return User{
+		ID:         u.ID,
+		Username:   u.Username,
+		FullName:   u.FullName,
+		Department: u.Department,
+		Email:      u.Email,
+		Tel:        u.Tel,
+	}
+}
+
+// IsActive: บัญชีเก่าที่ยังไม่มีสถานะถือว่าใช้งานได้
+func (u *User) IsActive() bool {
+	return u.Status == "" || u.Status == UserStatusActive
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// UserNotification แทนตาราง user_notifications (การแจ้งเตือนในระบบ / กระดิ่ง)
+type UserNotification struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	EventType string     `gorm:"type:varchar(50)" json:"event_type"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	BookingID *uint      `json:"booking_id"`
+	ReadAt    *time.Time `gorm:"index" json:"read_at"`
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`
+}
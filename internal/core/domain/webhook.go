@@ -0,0 +1,81 @@
+package domain
+
+import "time"
+
+// เหตุการณ์ที่ส่งออกไปยัง Webhook ของระบบอื่น
+const (
+	WebhookEventBookingCreated   = "booking.created"
+	WebhookEventBookingUpdated   = "booking.updated"
+	WebhookEventBookingApproved  = "booking.approved"
+	WebhookEventBookingRejected  = "booking.rejected"
+	WebhookEventBookingCancelled = "booking.cancelled"
+	WebhookEventRoomCreated      = "room.created"
+	WebhookEventRoomUpdated      = "room.updated"
+	WebhookEventRoomDeleted      = "room.deleted"
+	WebhookEventTest             = "webhook.test"
+	WebhookEventAll              = "*"
+)
+
+// WebhookEvents เหตุการณ์ที่สมัครรับได้
+var WebhookEvents = []string{
+	WebhookEventBookingCreated,
+	WebhookEventBookingUpdated,
+	WebhookEventBookingApproved,
+	WebhookEventBookingRejected,
+	WebhookEventBookingCancelled,
+	WebhookEventRoomCreated,
+	WebhookEventRoomUpdated,
+	WebhookEventRoomDeleted,
+}
+
+// Webhook แทนตาราง webhooks (ปลายทางที่ผู้ดูแลลงทะเบียนไว้)
+type Webhook struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(100);not null" json:"name"`
+	URL         string    `gorm:"type:text;not null" json:"url"`
+	Secret      string    `gorm:"type:text;not null" json:"-"` // ใช้สร้างลายเซ็น HMAC-SHA256 เก็บแบบเข้ารหัส (แสดงครั้งเดียวตอนสร้าง/หมุนใหม่)
+	HasSecret   bool      `gorm:"-" json:"has_secret"`
+	Events      []string  `gorm:"type:text;serializer:json" json:"events"` // ["*"] = ทุกเหตุการณ์
+	IsActive    bool      `gorm:"default:true" json:"is_active"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Subscribes: Webhook นี้สมัครรับเหตุการณ์นี้หรือไม่
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, e := range w.Events {
+		if e == WebhookEventAll || e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery แทนตาราง webhook_deliveries (คิวส่ง + ประวัติการส่งแต่ละครั้ง)
+// ใช้สถานะชุดเดียวกับ Outbox (pending / failed / sent / dead)
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	WebhookID      uint       `gorm:"not null;index" json:"webhook_id"`
+	EventID        string     `gorm:"type:varchar(64);not null;index" json:"event_id"` // ผู้รับใช้กันประมวลผลซ้ำ
+	EventType      string     `gorm:"type:varchar(50);not null" json:"event_type"`
+	Payload        string     `gorm:"type:text" json:"payload"`
+	Status         string     `gorm:"type:varchar(20);default:'pending';index" json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"index" json:"next_attempt_at"`
+	ResponseStatus int        `json:"response_status"`
+	ResponseBody   string     `gorm:"type:text" json:"response_body"`
+	DurationMs     int64      `json:"duration_ms"`
+	LastError      string     `json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WebhookEnvelope รูปแบบ JSON ที่ส่งไปยังปลายทาง
+type WebhookEnvelope struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
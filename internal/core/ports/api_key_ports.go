@@ -0,0 +1,34 @@
+package ports
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+type APIKeyRepository interface {
+	Create(key *domain.APIKey) error
+	GetByHash(hash string) (*domain.APIKey, error)
+	GetByID(id uint) (*domain.APIKey, error)
+	GetByUser(userID uint) ([]domain.APIKey, error)
+	GetAll() ([]domain.APIKey, error)
+	Revoke(id uint, at time.Time) (bool, error)
+	// Touch อัปเดตเวลาใช้งานล่าสุด (ข้ามถ้าเพิ่งอัปเดตหลัง since เพื่อลดการเขียน)
+	Touch(id uint, at, since time.Time, ip string) error
+}
+
+type APIKeyInput struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // 0 = ใช้ค่าสูงสุดที่ระบบอนุญาต
+}
+
+type APIKeyService interface {
+	// Create คืน Key จริง (แสดงได้ครั้งเดียว)
+	Create(userID uint, input APIKeyInput, client ClientInfo) (*domain.APIKey, string, error)
+	GetMine(userID uint) ([]domain.APIKey, error)
+	GetAll() ([]domain.APIKey, error)
+	// Revoke: เจ้าของเพิกถอน Key ตัวเอง หรือ asAdmin เพิกถอนของใครก็ได้
+	Revoke(id, actorID uint, asAdmin bool, client ClientInfo) error
+	// Authenticate ตรวจ Key จาก Request คืน Key และเจ้าของ (ต้องยังใช้งานได้)
+	Authenticate(rawKey string, client ClientInfo) (*domain.APIKey, *domain.User, error)
+}
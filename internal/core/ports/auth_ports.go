@@ -9,16 +9,20 @@ type UserRepository interface {
 	GetByUsernameOrEmail(identifier string) (*domain.User, error)
 	GetByID(id uint) (*domain.User, error)
 	GetAll() ([]domain.User, error)
+	GetByRoles(roles ...string) ([]domain.User, error)
 	Update(user *domain.User) error
+	IncrementTokenVersion(id uint) error
 	Delete(id uint) error
 	Count() (int64, error)
 }
 
 type AuthService interface {
 	Register(user *domain.User) error
-	Login(identifier, password string) (string, uint, error)
+	// Login คืน *TwoFactorRequiredError ถ้าผู้ใช้ต้องยืนยันตัวตนขั้นที่สอง
+	Login(identifier, password string, client ClientInfo) (*TokenPair, uint, error)
 	GetMe(userID uint) (*domain.User, error)
-	UpdateMe(userID uint, user *domain.User) error
+	// UpdateMe อีเมลใหม่ต้องผ่านโดเมนที่อนุญาตและไม่ซ้ำ (ถ้าต้องยืนยันอีเมล จะอยู่ใน PendingEmail จนกว่าจะกดลิงก์)
+	UpdateMe(userID uint, user *domain.User) (*domain.User, error)
 }
 
 // --- แก้ไขตรงนี้ครับ ---
@@ -26,6 +30,8 @@ type UserService interface {
 	GetAllUsers() ([]domain.User, error)
 	UpdateUser(id uint, user *domain.User) error
 	DeleteUser(id uint) error
+	// UpdateStatus ใช้อนุมัติบัญชีที่สมัครเอง หรือระงับการใช้งาน
+	UpdateStatus(id uint, status string, actorID uint) error
 
 	// ✅ เพิ่มบรรทัดนี้ลงไปครับ
 	CreateUser(user *domain.User) error
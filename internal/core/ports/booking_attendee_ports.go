@@ -0,0 +1,33 @@
+package ports
+
+import (
+	"errors"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+// ErrAttendeesForbidden ผู้เรียกไม่ใช่ผู้จอง ผู้ได้รับเชิญ ผู้อนุมัติ หรือผู้ดูแล
+var ErrAttendeesForbidden = errors.New("you do not have permission to view the attendees of this booking")
+
+type BookingAttendeeRepository interface {
+	GetByBooking(bookingID uint) ([]domain.BookingAttendee, error)
+	// GetByUser คำเชิญทั้งหมดของผู้ใช้ พร้อมข้อมูลการจอง
+	GetByUser(userID uint) ([]domain.BookingAttendee, error)
+	// GetAccepted ผู้ที่ตอบรับของหลายการจองในครั้งเดียว (bookingID -> userIDs)
+	GetAccepted(bookingIDs []uint) (map[uint][]uint, error)
+	// Replace: คนที่ยังอยู่ในรายชื่อคงสถานะเดิม คนใหม่เป็น invited คนที่ถูกเอาออกถูกลบ
+	Replace(bookingID uint, userIDs []uint) error
+	// Respond คืน false ถ้าผู้ใช้ไม่ได้รับเชิญในการจองนี้
+	Respond(bookingID, userID uint, status string, at time.Time) (bool, error)
+}
+
+type BookingAttendeeService interface {
+	// GetAttendees เฉพาะผู้จอง ผู้ได้รับเชิญ ผู้อนุมัติ และผู้ดูแล (ไม่ได้สิทธิ์ = ErrAttendeesForbidden)
+	GetAttendees(bookingID, actorID uint) ([]domain.AttendeeView, error)
+	// SetAttendees เฉพาะผู้จองหรือผู้ดูแล
+	SetAttendees(bookingID uint, userIDs []uint, actorID uint, client ClientInfo) error
+	// Respond ผู้ได้รับเชิญตอบรับ (accepted) หรือปฏิเสธ (declined)
+	Respond(bookingID, userID uint, status string, client ClientInfo) error
+	// GetInvitations คำเชิญของการประชุมที่ยังไม่จบ
+	GetInvitations(userID uint) ([]domain.BookingAttendee, error)
+}
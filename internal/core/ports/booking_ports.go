@@ -6,26 +6,30 @@ import (
 )
 
 type BookingRepository interface {
-	Create(booking *domain.Booking) error
+	// outbox จะถูกบันทึกใน Transaction เดียวกับการจอง
+	Create(booking *domain.Booking, outbox ...domain.OutboxMessage) error
 	GetAll() ([]domain.Booking, error)
 	GetByID(id uint) (*domain.Booking, error)
+	// รวมรายการที่ถูกลบไปแล้ว (ใช้ตอนแจ้งเตือนการยกเลิก)
+	GetByIDWithDeleted(id uint) (*domain.Booking, error)
 	// ดึงเฉพาะช่วงเวลา (สำหรับปฏิทิน)
 	GetByDateRange(start, end time.Time) ([]domain.Booking, error)
+	GetByStatus(status string) ([]domain.Booking, error)
 	// เช็คว่าห้องนี้ เวลานี้ มีใครจองหรือยัง (เพื่อป้องกันจองซ้ำ)
 	CountOverlapping(roomID uint, start, end time.Time) (int64, error)
 	// เช็คซ้ำแต่นับข้าม ID ตัวเอง (สำหรับ Update)
 	CountOverlappingExcludingID(roomID uint, start, end time.Time, excludeID uint) (int64, error)
-	Update(booking *domain.Booking) error
-	Delete(id uint) error
+	Update(booking *domain.Booking, outbox ...domain.OutboxMessage) error
+	Delete(id uint, outbox ...domain.OutboxMessage) error
 }
 
 type BookingService interface {
-	CreateBooking(booking *domain.Booking) error
+	CreateBooking(booking *domain.Booking, client ClientInfo) error
 	GetAllBookings() ([]domain.Booking, error)
 	GetBookingsByRange(start, end string) ([]domain.Booking, error) // รับ string แล้วแปลงเป็น time ใน service
 	GetBookingByID(id uint) (*domain.Booking, error)
-	UpdateBookingStatus(id uint, status string, approverID uint) error
-	UpdateBooking(id uint, booking *domain.Booking, actorID uint) error
+	UpdateBookingStatus(id uint, status string, approverID uint, client ClientInfo) error
+	UpdateBooking(id uint, booking *domain.Booking, actorID uint, client ClientInfo) error
 	// DeleteBooking(id uint) error -> เปลี่ยนเป็น รับ actorID ด้วย
-	DeleteBooking(id uint, actorID uint) error
+	DeleteBooking(id uint, actorID uint, client ClientInfo) error
 }
\ No newline at end of file
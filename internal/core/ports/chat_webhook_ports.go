@@ -0,0 +1,42 @@
+package ports
+
+import (
+	"errors"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+// ErrChatWebhookEncryptionKeyMissing: ไม่ได้ตั้ง SETTINGS_ENCRYPTION_KEY จึงบันทึก URL ของห้องแชทไม่ได้ (ไม่เก็บเป็นข้อความธรรมดา)
+var ErrChatWebhookEncryptionKeyMissing = errors.New("SETTINGS_ENCRYPTION_KEY is not set on the server, chat webhook URLs cannot be saved")
+
+// ChatMessage ข้อความกลางก่อนแปลงเป็นรูปแบบของแต่ละแพลตฟอร์ม
+type ChatMessage struct {
+	Title string
+	Text  string
+	Link  string
+	Color string // Hex เช่น #db2777 (สีห้อง)
+}
+
+type ChatWebhookRepository interface {
+	GetAll() ([]domain.ChatWebhook, error)
+	GetActive() ([]domain.ChatWebhook, error)
+	GetByID(id uint) (*domain.ChatWebhook, error)
+	Create(webhook *domain.ChatWebhook) error
+	Update(webhook *domain.ChatWebhook) error
+	Delete(id uint) error
+}
+
+type ChatWebhookService interface {
+	GetWebhooks() ([]domain.ChatWebhook, error)
+	GetWebhook(id uint) (*domain.ChatWebhook, error)
+	CreateWebhook(webhook *domain.ChatWebhook, actorID uint) error
+	UpdateWebhook(id uint, webhook *domain.ChatWebhook, actorID uint) error
+	DeleteWebhook(id uint, actorID uint) error
+	Test(id uint, actorID uint) error
+
+	// Targets ห้องแชทที่ต้องได้รับเหตุการณ์ของการจองนี้
+	Targets(eventType string, booking *domain.Booking) ([]domain.ChatWebhook, error)
+	// Post ส่งข้อความตามรูปแบบของแพลตฟอร์ม
+	Post(webhook *domain.ChatWebhook, msg ChatMessage) error
+	// SealStoredURLs เข้ารหัส URL ที่บันทึกไว้เป็นข้อความธรรมดาก่อนมีการเข้ารหัส (เรียกตอนเริ่มระบบ)
+	SealStoredURLs() error
+}
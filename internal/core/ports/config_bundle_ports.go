@@ -0,0 +1,17 @@
+package ports
+
+import "tunorth-brms-backend/internal/core/domain"
+
+type ConfigBundleRepository interface {
+	// Apply บันทึกทั้งหมดใน Transaction เดียว: ค่าตั้งค่าพร้อมประวัติ (เหมือน SettingRepository.UpdateBatch)
+	// และห้อง/อุปกรณ์ (ID = 0 สร้างใหม่ นอกนั้นแก้ไข)
+	Apply(settings []domain.Setting, changeSet *domain.SettingChangeSet, rooms []domain.Room, resources []domain.Resource) error
+}
+
+type ConfigBundleService interface {
+	// Export ค่าตั้งค่าที่ไม่ใช่ secret และห้อง/อุปกรณ์ถ้าเลือก
+	Export(includeRooms, includeResources bool, actorID uint) (*domain.ConfigBundle, error)
+	// Import ตรวจทั้ง Bundle ก่อน (ไม่ผ่าน = *SettingValidationError ชื่อ field เช่น settings.theme_color, rooms.0.color)
+	// dryRun = คืนเฉพาะสิ่งที่จะเปลี่ยน ไม่บันทึก
+	Import(bundle *domain.ConfigBundle, dryRun bool, actorID uint) (*domain.ConfigBundleDiff, error)
+}
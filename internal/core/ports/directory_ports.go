@@ -0,0 +1,26 @@
+package ports
+
+import "errors"
+
+var (
+	// ErrDirectoryInvalidCredentials: ไม่พบผู้ใช้ใน Directory หรือรหัสผ่านผิด
+	ErrDirectoryInvalidCredentials = errors.New("invalid directory credentials")
+	// ErrDirectoryAccessDenied: รหัสผ่านถูกแต่ไม่อยู่ในกลุ่มที่อนุญาตให้ใช้ระบบ
+	ErrDirectoryAccessDenied = errors.New("directory account is not allowed to use this system")
+)
+
+// DirectoryUser ข้อมูลผู้ใช้จาก LDAP / Active Directory หลังตรวจรหัสผ่านผ่านแล้ว
+type DirectoryUser struct {
+	DN         string   `json:"dn"`
+	Username   string   `json:"username"`
+	FullName   string   `json:"full_name"`
+	Department string   `json:"department"`
+	Email      string   `json:"email"`
+	Groups     []string `json:"groups"`
+	Role       string   `json:"role"` // role ที่ได้จากการจับคู่กลุ่ม
+}
+
+type DirectoryAuthenticator interface {
+	Enabled() bool
+	Authenticate(username, password string) (*DirectoryUser, error)
+}
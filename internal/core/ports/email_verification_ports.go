@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+type EmailVerificationRepository interface {
+	Create(token *domain.EmailVerificationToken) error
+	GetByHash(hash string) (*domain.EmailVerificationToken, error)
+	CountSince(userID uint, since time.Time) (int64, error)
+	// MarkUsed คืน false ถ้า token ถูกใช้ไปก่อนแล้ว
+	MarkUsed(id uint, at time.Time) (bool, error)
+	// InvalidateUser ทำให้ token ที่ยังไม่ใช้ทั้งหมดของผู้ใช้ใช้ไม่ได้
+	InvalidateUser(userID uint, at time.Time) error
+}
+
+type EmailVerificationService interface {
+	// SendVerification ส่งลิงก์ยืนยันอีเมลให้ผู้ใช้ที่อยู่ในสถานะ pending_verification
+	SendVerification(user *domain.User)
+	// SendEmailChange ส่งลิงก์ไปที่ user.PendingEmail อีเมลของบัญชีจะเปลี่ยนเมื่อกดลิงก์แล้วเท่านั้น
+	SendEmailChange(user *domain.User)
+	// Verify คืนผู้ใช้หลังยืนยัน (สถานะจะเป็น active หรือ pending_approval / ลิงก์เปลี่ยนอีเมล: ย้าย PendingEmail ไปเป็น Email)
+	Verify(token string, client ClientInfo) (*domain.User, error)
+	// Resend ส่งลิงก์ใหม่ (ไม่บอกว่ามีบัญชีนี้หรือไม่)
+	Resend(email string, client ClientInfo)
+}
@@ -0,0 +1,15 @@
+package ports
+
+// StreamEvent เหตุการณ์ที่ส่งให้ผู้เปิดปฏิทินค้างไว้ (Server-Sent Events)
+type StreamEvent struct {
+	ID   uint64
+	Type string
+	Data interface{} // domain.BookingEvent หรือ domain.Room
+}
+
+// EventStreamService กระจายเหตุการณ์ให้ผู้ที่เชื่อมต่ออยู่กับ instance นี้
+type EventStreamService interface {
+	EventPublisher
+	// Subscribe คืน channel ของเหตุการณ์ และฟังก์ชันยกเลิกการรับ (ต้องเรียกเมื่อผู้ใช้ตัดการเชื่อมต่อ)
+	Subscribe() (<-chan StreamEvent, func())
+}
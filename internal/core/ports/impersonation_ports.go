@@ -0,0 +1,12 @@
+package ports
+
+import "tunorth-brms-backend/internal/core/domain"
+
+type ImpersonationService interface {
+	// Start ผู้ดูแล (actorID) ขอ Token ในนามผู้ใช้ targetID
+	Start(targetID, actorID uint, reason string, client ClientInfo) (*TokenPair, *domain.User, error)
+	// End ออกจาก Session สวมสิทธิ์ (sessionID จาก Token ที่ใช้อยู่)
+	End(sessionID string, userID, impersonatorID uint, client ClientInfo) error
+	// GetImpersonator ข้อมูลผู้ดูแลสำหรับแสดงใน GET /api/me
+	GetImpersonator(sessionID string, impersonatorID uint) (*domain.Impersonator, error)
+}
@@ -10,5 +10,9 @@ type LogRepository interface {
 
 type LogService interface {
 	LogAction(userID uint, action, description, ip, userAgent string) error
+	// LogImpersonatedAction บันทึกทั้งผู้ใช้ที่ถูกสวมสิทธิ์ และผู้ดูแลที่ทำรายการจริง
+	LogImpersonatedAction(userID, impersonatorID uint, action, description, ip, userAgent string) error
+	// LogClientAction ใช้ IP / User-Agent / ผู้ดูแลที่สวมสิทธิ์ จาก ClientInfo ของ Request
+	LogClientAction(userID uint, action, description string, client ClientInfo) error
 	GetLogs(limit int) ([]domain.Log, error)
 }
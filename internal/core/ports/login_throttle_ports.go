@@ -0,0 +1,37 @@
+package ports
+
+import (
+	"fmt"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+type LoginThrottleRepository interface {
+	Get(scope, key string) (*domain.LoginThrottle, error)
+	// RecordFailure เพิ่มตัวนับ (เริ่มนับใหม่ถ้าครั้งล่าสุดเก่ากว่า windowStart) แล้วคืนค่าล่าสุด
+	RecordFailure(entry *domain.LoginThrottle, windowStart time.Time) (*domain.LoginThrottle, error)
+	SetLockedUntil(id uint, until time.Time) error
+	Reset(scope, key string) error
+	GetByID(id uint) (*domain.LoginThrottle, error)
+	// GetRecent รายการที่ยังล็อกอยู่ หรือล้มเหลวหลัง since
+	GetRecent(since time.Time) ([]domain.LoginThrottle, error)
+	Delete(id uint) error
+}
+
+// LoginLockedError: ต้องรอก่อน Login ครั้งถัดไป (หน่วงเวลาแบบทวีคูณ หรือถูกล็อกชั่วคราว)
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, please try again in %d seconds", int(e.RetryAfter.Round(time.Second)/time.Second))
+}
+
+type LoginThrottleService interface {
+	// Check คืน *LoginLockedError ถ้าบัญชีหรือ IP ยังอยู่ในช่วงรอ
+	Check(accountKey, ip string) error
+	RecordFailure(accountKey string, userID uint, reason string, client ClientInfo)
+	RecordSuccess(accountKey string)
+	GetLockouts() ([]domain.LoginThrottle, error)
+	Clear(id, actorID uint, client ClientInfo) error
+}
@@ -1,9 +1,31 @@
 package ports
 
-import "tunorth-brms-backend/internal/core/domain"
+import (
+	"context"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+)
 
 type NotificationService interface {
 	SendTelegram(chatID, message string) error
+	SendEmail(to, subject, body string) error
+	SendLine(to, message string) error
 	NotifyAdminNewBooking(booking *domain.Booking) error
 	NotifyUserStatusChange(booking *domain.Booking) error
+	NotifyAdminBookingCancelled(booking *domain.Booking) error
+	// Deliver ส่งข้อความจาก Outbox (คืน error เพื่อให้ Dispatcher retry)
+	Deliver(msg *domain.OutboxMessage) error
+}
+
+// ReminderService ตั้งเวลาเตือนก่อนเริ่มประชุม
+type ReminderService interface {
+	Start(ctx context.Context)
+	// ScheduleDue ใส่การแจ้งเตือนที่ถึงเวลาลง Outbox คืนจำนวนที่เพิ่มใหม่
+	ScheduleDue(now time.Time) (int, error)
+}
+
+// DigestService ตั้งเวลาส่งสรุปประจำวันให้ผู้อนุมัติ
+type DigestService interface {
+	Start(ctx context.Context)
+	ScheduleDue(now time.Time) (int, error)
 }
@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+// NotificationPreferences รูปแบบที่ใช้รับ/ส่งกับหน้าเว็บ
+type NotificationPreferences struct {
+	// event_type -> channel -> enabled
+	Events     map[string]map[string]bool    `json:"events"`
+	QuietHours domain.NotificationQuietHours `json:"quiet_hours"`
+}
+
+type NotificationPreferenceRepository interface {
+	GetByUser(userID uint) ([]domain.NotificationPreference, error)
+	GetQuietHours(userID uint) (*domain.NotificationQuietHours, error)
+	// Save แทนที่ค่าทั้งหมดของผู้ใช้ใน Transaction เดียว
+	Save(userID uint, prefs []domain.NotificationPreference, quiet *domain.NotificationQuietHours) error
+}
+
+type NotificationPreferenceService interface {
+	GetPreferences(userID uint) (*NotificationPreferences, error)
+	UpdatePreferences(userID uint, prefs *NotificationPreferences) error
+	IsEnabled(userID uint, eventType, channel string) bool
+	// QuietUntil คืนเวลาที่ช่วงห้ามรบกวนสิ้นสุด ถ้า now อยู่ในช่วงนั้น
+	QuietUntil(userID uint, now time.Time) (time.Time, bool)
+}
@@ -0,0 +1,67 @@
+package ports
+
+import "tunorth-brms-backend/internal/core/domain"
+
+// NotificationTemplateData ตัวแปรที่ใช้ได้ใน Template เช่น {{.Subject}}, {{.AdminLink}}
+type NotificationTemplateData struct {
+	SiteName      string
+	BaseURL       string
+	AdminLink     string
+	BookingID     uint
+	Subject       string
+	RoomName      string
+	UserName      string
+	Department    string
+	Start         string // 02/01/2006 15:04
+	End           string // 15:04
+	Status        string // pending, approved, rejected, cancelled
+	StatusLabel   string // ข้อความสถานะภาษาไทย
+	BeforeMinutes int    // สำหรับการเตือนก่อนประชุม
+	Before        string // เช่น "15 นาที"
+
+	// สรุปประจำวัน (booking.digest)
+	Date         string
+	PendingCount int
+	Pending      []DigestBooking
+	Overdue      []DigestBooking // รออนุมัตินานเกิน OverdueHours ชั่วโมง
+	OverdueHours int
+	Tomorrow     []DigestRoom // การจองที่อนุมัติแล้วของพรุ่งนี้ แยกตามห้อง
+}
+
+type DigestBooking struct {
+	ID           uint
+	Subject      string
+	RoomName     string
+	UserName     string
+	Start        string
+	End          string
+	WaitingHours int
+}
+
+type DigestRoom struct {
+	RoomName string
+	Bookings []DigestBooking
+}
+
+type RenderedNotification struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+type NotificationTemplateRepository interface {
+	GetAll() ([]domain.NotificationTemplate, error)
+	GetByID(id uint) (*domain.NotificationTemplate, error)
+	Find(eventType, channel, language string) (*domain.NotificationTemplate, error)
+	Create(tpl *domain.NotificationTemplate) error
+	Update(tpl *domain.NotificationTemplate) error
+}
+
+type NotificationTemplateService interface {
+	GetTemplates(eventType, channel, language string) ([]domain.NotificationTemplate, error)
+	CreateTemplate(tpl *domain.NotificationTemplate, actorID uint) error
+	UpdateTemplate(id uint, tpl *domain.NotificationTemplate, actorID uint) error
+	// Preview แสดงผลกับข้อมูลการจองตัวอย่าง (ไม่บันทึก)
+	Preview(tpl *domain.NotificationTemplate) (*RenderedNotification, error)
+	Render(eventType, channel, language string, data *NotificationTemplateData) (*RenderedNotification, error)
+	InitializeDefaults() error
+}
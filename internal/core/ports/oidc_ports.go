@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+type OIDCLoginRepository interface {
+	Create(login *domain.OIDCLogin) error
+	GetByStateHash(hash string) (*domain.OIDCLogin, error)
+	// Complete ผูกผู้ใช้และรหัสแลก Token (คืน false ถ้า state ถูกใช้ไปแล้ว)
+	Complete(id uint, exchangeHash string, userID uint, expiresAt time.Time) (bool, error)
+	// ConsumeExchange ใช้รหัสแลก Token ได้ครั้งเดียว
+	ConsumeExchange(exchangeHash string, at time.Time) (*domain.OIDCLogin, error)
+	DeleteExpired(before time.Time) error
+}
+
+type OIDCService interface {
+	Enabled() bool
+	// LoginURL สร้าง URL ไปหน้า Login ของผู้ให้บริการ (Authorization Code + PKCE)
+	// และค่า browser ที่ต้องเก็บใน Cookie ของ Browser นี้ไว้ส่งกลับมาตอน Callback
+	LoginURL(client ClientInfo) (authURL, browser string, err error)
+	// Callback ตรวจ state / Cookie ของ Browser ที่เริ่ม Login / ID Token แล้วคืนรหัสใช้ครั้งเดียวสำหรับ Frontend
+	Callback(state, code, browser string, client ClientInfo) (string, error)
+	// Exchange แลกรหัสเป็น Token ชุดเดียวกับการ Login ปกติ (ตรวจการล็อกบัญชี และคืน *TwoFactorRequiredError ถ้าต้องใช้ 2FA)
+	Exchange(code string, client ClientInfo) (*TokenPair, uint, error)
+}
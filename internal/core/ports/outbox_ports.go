@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+type OutboxRepository interface {
+	Create(msg *domain.OutboxMessage) error
+	// CreateUnique ไม่บันทึกซ้ำถ้ามี DedupKey เดิมอยู่แล้ว (คืน false)
+	CreateUnique(msg *domain.OutboxMessage) (bool, error)
+	GetByID(id uint) (*domain.OutboxMessage, error)
+	// ดึงรายการที่ถึงเวลาส่ง และจองไว้ (lease) เพื่อไม่ให้ instance อื่นดึงซ้ำ
+	ClaimDue(now time.Time, limit int, lease time.Duration) ([]domain.OutboxMessage, error)
+	List(status string, limit int) ([]domain.OutboxMessage, error)
+	Update(msg *domain.OutboxMessage) error
+}
+
+type OutboxService interface {
+	// Start วน loop ส่งข้อความที่ค้างอยู่จนกว่า ctx จะถูกยกเลิก
+	Start(ctx context.Context)
+	DispatchDue() (int, error)
+	GetMessages(status string, limit int) ([]domain.OutboxMessage, error)
+	Retry(id uint, actorID uint) error
+}
@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+type PasswordResetRepository interface {
+	Create(token *domain.PasswordResetToken) error
+	GetByHash(hash string) (*domain.PasswordResetToken, error)
+	CountSince(userID uint, since time.Time) (int64, error)
+	// MarkUsed คืน false ถ้า token ถูกใช้ไปก่อนแล้ว
+	MarkUsed(id uint, at time.Time) (bool, error)
+	// InvalidateUser ทำให้ token ที่ยังไม่ใช้ทั้งหมดของผู้ใช้ใช้ไม่ได้
+	InvalidateUser(userID uint, at time.Time) error
+}
+
+type PasswordResetService interface {
+	// RequestReset ส่งอีเมลลิงก์ตั้งรหัสผ่านใหม่ (ไม่บอกว่ามีบัญชีนี้หรือไม่)
+	RequestReset(email string, client ClientInfo)
+	ResetPassword(token, newPassword string, client ClientInfo) error
+}
@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+type SessionRepository interface {
+	Create(session *domain.Session) error
+	GetByID(id string) (*domain.Session, error)
+	GetActiveByUser(userID uint, now time.Time) ([]domain.Session, error)
+	// Touch อัปเดต last seen (ข้ามถ้าเพิ่งอัปเดตหลัง since เพื่อลดการเขียน)
+	Touch(id string, at, since time.Time) error
+	// Refreshed อัปเดตเครื่อง / IP / วันหมดอายุ เมื่อหมุน Refresh Token
+	Refreshed(id, device string, client ClientInfo, at, expiresAt time.Time) error
+	Revoke(id string, at time.Time) error
+	RevokeByUser(userID uint, at time.Time) error
+	DeleteExpired(before time.Time) error
+}
+
+type SessionService interface {
+	// GetSessions Session ที่ยังใช้งานได้ของผู้ใช้ (currentID ใช้ทำเครื่องหมาย Current)
+	GetSessions(userID uint, currentID string) ([]domain.Session, error)
+	// Revoke ออกจากระบบเฉพาะ Session นี้ (ต้องเป็นของ userID)
+	Revoke(userID uint, sessionID string, client ClientInfo) error
+	// RevokeAll ผู้ดูแลบังคับออกจากระบบทุกเครื่องของผู้ใช้
+	RevokeAll(userID, actorID uint, client ClientInfo) error
+}
@@ -1,17 +1,81 @@
 package ports
 
-import "tunorth-brms-backend/internal/core/domain"
+import (
+	"context"
+	"errors"
+	"strings"
+	"tunorth-brms-backend/internal/core/domain"
+)
 
 type SettingRepository interface {
 	GetAll() ([]domain.Setting, error)
 	GetByName(name string) (*domain.Setting, error)
 	Update(setting *domain.Setting) error
-	UpdateBatch(settings []domain.Setting) error
+	// UpdateBatch บันทึกค่าใหม่พร้อมประวัติใน Transaction เดียว ค่าที่เปลี่ยนจริงถูกเพิ่มลง changeSet.Changes
+	// (ไม่มีค่าใดเปลี่ยน = ไม่สร้าง changeSet)
+	UpdateBatch(settings []domain.Setting, changeSet *domain.SettingChangeSet) error
+	// GetHistory ล่าสุดก่อน settingName ว่าง = ทุกค่า
+	GetHistory(settingName string, limit int) ([]domain.SettingChangeSet, error)
+	GetChangeSet(id uint) (*domain.SettingChangeSet, error)
+}
+
+// ErrSettingsEncryptionKeyMissing: ไม่ได้ตั้ง SETTINGS_ENCRYPTION_KEY จึงบันทึกค่า secret ไม่ได้ (ไม่เก็บเป็นข้อความธรรมดา)
+var ErrSettingsEncryptionKeyMissing = errors.New("SETTINGS_ENCRYPTION_KEY is not set on the server, secret settings cannot be saved")
+
+// SettingValidationError ค่าที่ไม่ผ่านการตรวจ แยกตามชื่อ setting
+type SettingValidationError struct {
+	Fields map[string]string
+}
+
+func (e *SettingValidationError) Error() string {
+	return "some settings are invalid"
 }
 
 type SettingService interface {
-	GetAllSettings() (map[string]interface{}, []domain.Setting, error)
+	// GetSchema ทะเบียนค่าตั้งค่าทั้งหมด (ชนิด ตัวเลือก ช่วงตัวเลข รูปแบบ)
+	GetSchema() []domain.SettingField
+	// GetAllSettings สำหรับหน้าตั้งค่าของผู้ดูแล (ค่า secret ถูกซ่อน แจ้งเพียง IsSet)
+	GetAllSettings() ([]domain.Setting, error)
+	// GetPublicSettings เฉพาะค่าที่ Visibility = public
+	GetPublicSettings() (map[string]string, error)
+	// UpdateSettings ตรวจทุกค่ากับ schema ก่อนบันทึก (ไม่ผ่าน = *SettingValidationError ไม่บันทึกเลย)
 	UpdateSettings(updates []domain.Setting, actorID uint) error
+	// GetHistory ประวัติการแก้ไข (ค่า secret ถูกซ่อน)
+	GetHistory(settingName string, limit int) ([]domain.SettingChangeSet, error)
+	// Rollback คืนค่าก่อนการแก้ไขชุด changeSetID (settingName ว่าง = ทั้งชุด) บันทึกเป็นการแก้ไขชุดใหม่
+	Rollback(changeSetID uint, settingName string, actorID uint) (*domain.SettingChangeSet, error)
+	// GetSettingValue ค่าจริง (ถอดรหัส secret แล้ว) ใช้ภายใน Backend เท่านั้น อ่านจาก cache ในหน่วยความจำ
 	GetSettingValue(key string) string
 	InitializeDefaults() error
+	// Refresh ล้าง cache และแจ้ง instance อื่น หลังแก้ค่าใน Database โดยไม่ผ่าน UpdateSettings (เช่น นำเข้า Bundle)
+	Refresh(keys []string)
+	// Subscribe ลงทะเบียนฟังก์ชันที่ถูกเรียกทุกครั้งที่ค่าตั้งค่าเปลี่ยน (รวมถึงการเปลี่ยนจาก instance อื่น)
+	Subscribe(fn SettingChangeFunc)
+	// Start รอรับการเปลี่ยนค่าจาก instance อื่นผ่าน SettingChangeBus (ทำงานเบื้องหลังจน ctx ถูกยกเลิก)
+	Start(ctx context.Context)
+}
+
+// SettingChangeFunc รับชื่อค่าตั้งค่าที่เปลี่ยน (nil = อาจเปลี่ยนทั้งหมด เช่น หลังเชื่อมต่อใหม่)
+type SettingChangeFunc func(keys []string)
+
+// SettingChangeBus แจ้ง instance อื่นให้ล้าง cache เมื่อค่าตั้งค่าเปลี่ยน (PostgreSQL LISTEN/NOTIFY)
+type SettingChangeBus interface {
+	Publish(keys []string) error
+	// Listen เรียก onChange ทุกครั้งที่ instance อื่นแจ้งมา เชื่อมต่อใหม่เองเมื่อหลุด (ทำงานจน ctx ถูกยกเลิก)
+	Listen(ctx context.Context, onChange SettingChangeFunc)
+}
+
+// SettingsAffected true ถ้ามีค่าที่ขึ้นต้นด้วย prefix ใดๆ อยู่ใน keys (keys = nil ถือว่าเปลี่ยนทั้งหมด)
+func SettingsAffected(keys []string, prefixes ...string) bool {
+	if keys == nil {
+		return true
+	}
+	for _, key := range keys {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		}
+	}
+	return false
 }
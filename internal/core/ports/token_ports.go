@@ -0,0 +1,61 @@
+package ports
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+// ClientInfo ข้อมูลเครื่องที่เรียก API (ใช้บันทึกคู่กับ Token และ Log)
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	// ImpersonatorID ผู้ดูแลที่สวมสิทธิ์ผู้ใช้อยู่ตอนเรียก (claim "imp") 0 = ไม่ได้สวมสิทธิ์
+	ImpersonatorID uint
+}
+
+// TokenPair ผลลัพธ์ของการ Login / Refresh
+// ใช้ชื่อ "token" สำหรับ Access Token เหมือนเดิม เพื่อไม่ให้ Frontend เดิมพัง
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	RefreshToken     string    `json:"refresh_token"`
+	ExpiresIn        int       `json:"expires_in"` // วินาที
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// AccessClaims ข้อมูลใน Access Token ที่ตรวจสอบแล้ว
+type AccessClaims struct {
+	UserID    uint
+	Username  string
+	Role      string
+	SessionID string // FamilyID ของ Refresh Token
+	Version   int    // ต้องตรงกับ User.TokenVersion
+	// ImpersonatorID ผู้ดูแลที่สวมสิทธิ์ (0 = ผู้ใช้ Login เอง)
+	ImpersonatorID uint
+}
+
+type RefreshTokenRepository interface {
+	Create(token *domain.RefreshToken) error
+	GetByHash(hash string) (*domain.RefreshToken, error)
+	// MarkUsed คืน false ถ้า token ถูกใช้/เพิกถอนไปก่อนแล้ว (กันการ refresh พร้อมกัน)
+	MarkUsed(id uint, at time.Time) (bool, error)
+	RevokeFamily(familyID string, at time.Time) error
+	RevokeByUser(userID uint, at time.Time) error
+	DeleteExpired(before time.Time) error
+}
+
+type TokenService interface {
+	// Issue ออก Token คู่ใหม่ (Family ใหม่) หลัง Login สำเร็จ
+	Issue(user *domain.User, client ClientInfo) (*TokenPair, error)
+	// IssueImpersonation ออก Access Token ที่มีทั้ง user_id และ imp (ผู้ดูแล) ไม่มี Refresh Token
+	IssueImpersonation(user *domain.User, impersonatorID uint, ttl time.Duration, client ClientInfo) (*TokenPair, *domain.Session, error)
+	Refresh(refreshToken string, client ClientInfo) (*TokenPair, error)
+	// Revoke เพิกถอนทั้ง Family ของ Refresh Token นี้ (Logout) คืน userID เจ้าของ
+	Revoke(refreshToken string) (uint, error)
+	// RevokeSession เพิกถอน Session เดียว (รวม Access Token ที่ออกไปแล้วของ Session นั้น)
+	RevokeSession(sessionID string) error
+	// RevokeUserTokens เพิกถอนทุก Token ของผู้ใช้ (รวม Access Token ที่ออกไปแล้ว)
+	RevokeUserTokens(userID uint) error
+	ParseAccessToken(token string) (*AccessClaims, error)
+	// ValidateAccess ตรวจว่าผู้ใช้ยังอยู่ และ Token / Session ยังไม่ถูกเพิกถอน
+	ValidateAccess(userID uint, version int, sessionID string) error
+}
@@ -0,0 +1,70 @@
+package ports
+
+import (
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+type TwoFactorRepository interface {
+	Get(userID uint) (*domain.UserTwoFactor, error)
+	Save(tf *domain.UserTwoFactor) error
+	// Delete ลบทั้ง TOTP และ Recovery Code ของผู้ใช้
+	Delete(userID uint) error
+	// UseStep คืน false ถ้ารหัสของช่วงเวลานี้ (หรือหลังจากนี้) ถูกใช้ไปแล้ว
+	UseStep(userID uint, step int64) (bool, error)
+
+	ReplaceRecoveryCodes(userID uint, hashes []string) error
+	UseRecoveryCode(userID uint, hash string, at time.Time) (bool, error)
+	CountRecoveryCodes(userID uint) (int64, error)
+
+	CreateChallenge(challenge *domain.TwoFactorChallenge) error
+	GetChallenge(hash string) (*domain.TwoFactorChallenge, error)
+	IncrementChallengeAttempts(id uint) error
+	// ConsumeChallenge คืน false ถ้า challenge ถูกใช้ไปแล้ว
+	ConsumeChallenge(id uint, at time.Time) (bool, error)
+	DeleteExpiredChallenges(before time.Time) error
+}
+
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at"`
+	Required               bool       `json:"required"` // role นี้ถูกบังคับให้ใช้ 2FA
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorSetup ข้อมูลสำหรับเพิ่มบัญชีในแอป Authenticator (Frontend นำ otpauth_url ไปสร้าง QR Code)
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+// TwoFactorRequiredError: รหัสผ่านถูกต้องแล้ว แต่ต้องยืนยันขั้นที่สองด้วย ChallengeToken
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+	ExpiresIn      int  // วินาที
+	EnrollRequired bool // role ถูกบังคับแต่ยังไม่ได้ตั้งค่า ต้องตั้งค่าก่อน
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return "two-factor authentication required"
+}
+
+type TwoFactorService interface {
+	Status(userID uint) (*TwoFactorStatus, error)
+	// IsRequired: ต้องผ่าน 2FA ตอน Login (ตั้งค่าไว้แล้ว หรือ role ถูกบังคับ)
+	IsRequired(user *domain.User) bool
+
+	BeginSetup(userID uint) (*TwoFactorSetup, error)
+	// Enable ยืนยันรหัสแรกจากแอป แล้วคืน Recovery Code (แสดงได้ครั้งเดียว)
+	Enable(userID uint, code string, client ClientInfo) ([]string, error)
+	Disable(userID uint, code string, client ClientInfo) error
+	RegenerateRecoveryCodes(userID uint, code string, client ClientInfo) ([]string, error)
+	// Reset: ผู้ดูแลล้าง 2FA ของผู้ใช้ (เช่น โทรศัพท์หาย)
+	Reset(userID, actorID uint, client ClientInfo) error
+
+	StartChallenge(user *domain.User, client ClientInfo) (*TwoFactorRequiredError, error)
+	// ChallengeSetup ตั้งค่า 2FA ระหว่าง Login สำหรับ role ที่ถูกบังคับ
+	ChallengeSetup(challengeToken string) (*TwoFactorSetup, error)
+	// CompleteChallenge ตรวจรหัส TOTP หรือ Recovery Code แล้วคืนผู้ใช้ (และ Recovery Code ถ้าเพิ่งตั้งค่าเสร็จ)
+	CompleteChallenge(challengeToken, code string, client ClientInfo) (*domain.User, []string, error)
+}
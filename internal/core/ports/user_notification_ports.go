@@ -0,0 +1,25 @@
+package ports
+
+import "tunorth-brms-backend/internal/core/domain"
+
+type UserNotificationPage struct {
+	Items       []domain.UserNotification `json:"items"`
+	Total       int64                     `json:"total"`
+	UnreadCount int64                     `json:"unread_count"`
+	Page        int                       `json:"page"`
+	Limit       int                       `json:"limit"`
+}
+
+type UserNotificationRepository interface {
+	CreateBatch(items []domain.UserNotification) error
+	ListByUser(userID uint, unreadOnly bool, limit, offset int) ([]domain.UserNotification, int64, error)
+	CountUnread(userID uint) (int64, error)
+	MarkRead(userID, id uint) (bool, error)
+	MarkAllRead(userID uint) (int64, error)
+}
+
+type UserNotificationService interface {
+	GetNotifications(userID uint, unreadOnly bool, page, limit int) (*UserNotificationPage, error)
+	MarkRead(userID, id uint) error
+	MarkAllRead(userID uint) (int64, error)
+}
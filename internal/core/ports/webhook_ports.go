@@ -0,0 +1,55 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+// ErrWebhookEncryptionKeyMissing: ไม่ได้ตั้ง SETTINGS_ENCRYPTION_KEY จึงบันทึก secret ของ Webhook ไม่ได้ (ไม่เก็บเป็นข้อความธรรมดา)
+var ErrWebhookEncryptionKeyMissing = errors.New("SETTINGS_ENCRYPTION_KEY is not set on the server, webhook secrets cannot be saved")
+
+// EventPublisher รับเหตุการณ์จาก Service อื่น (การจอง / ห้อง) ไปส่งต่อ
+type EventPublisher interface {
+	Publish(eventType string, data interface{})
+}
+
+type WebhookRepository interface {
+	GetAll() ([]domain.Webhook, error)
+	GetActive() ([]domain.Webhook, error)
+	GetByID(id uint) (*domain.Webhook, error)
+	Create(webhook *domain.Webhook) error
+	Update(webhook *domain.Webhook) error
+	Delete(id uint) error
+
+	CreateDeliveries(deliveries []domain.WebhookDelivery) error
+	GetDelivery(id uint) (*domain.WebhookDelivery, error)
+	// ดึงรายการที่ถึงเวลาส่ง และจองไว้ (lease) เหมือน Outbox
+	ClaimDueDeliveries(now time.Time, limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	ListDeliveries(webhookID uint, status string, limit int) ([]domain.WebhookDelivery, error)
+	UpdateDelivery(delivery *domain.WebhookDelivery) error
+}
+
+type WebhookService interface {
+	EventPublisher
+
+	GetWebhooks() ([]domain.Webhook, error)
+	GetWebhook(id uint) (*domain.Webhook, error)
+	CreateWebhook(webhook *domain.Webhook, actorID uint) error
+	UpdateWebhook(id uint, webhook *domain.Webhook, actorID uint) error
+	DeleteWebhook(id uint, actorID uint) error
+	// RotateSecret สุ่ม secret ใหม่ คืนค่าให้ผู้ดูแลครั้งเดียว (ปลายทางต้องเปลี่ยนตามทันที)
+	RotateSecret(id uint, actorID uint) (string, error)
+	// SealStoredSecrets เข้ารหัส secret ที่บันทึกไว้เป็นข้อความธรรมดาก่อนมีการเข้ารหัส (เรียกตอนเริ่มระบบ)
+	SealStoredSecrets() error
+
+	GetDeliveries(webhookID uint, status string, limit int) ([]domain.WebhookDelivery, error)
+	RetryDelivery(id uint, actorID uint) error
+	// Test ส่งเหตุการณ์ webhook.test ทันที แล้วคืนผลการส่ง
+	Test(id uint, actorID uint) (*domain.WebhookDelivery, error)
+
+	// Start วน loop ส่ง delivery ที่ค้างอยู่จนกว่า ctx จะถูกยกเลิก
+	Start(ctx context.Context)
+	DispatchDue() (int, error)
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+const (
+	defaultAPIKeyMaxDays = 365
+	apiKeyTouchInterval  = time.Minute // บันทึกเวลาใช้งานล่าสุดไม่ถี่กว่านี้
+)
+
+var errInvalidAPIKey = errors.New("invalid or expired API key")
+
+type apiKeyService struct {
+	repo       ports.APIKeyRepository
+	userRepo   ports.UserRepository
+	settings   ports.SettingService
+	logService ports.LogService
+}
+
+func NewAPIKeyService(repo ports.APIKeyRepository, userRepo ports.UserRepository, settings ports.SettingService, logService ports.LogService) ports.APIKeyService {
+	return &apiKeyService{repo: repo, userRepo: userRepo, settings: settings, logService: logService}
+}
+
+func (s *apiKeyService) Create(userID uint, input ports.APIKeyInput, client ports.ClientInfo) (*domain.APIKey, string, error) {
+	if s.settings.GetSettingValue("api_keys_enabled") == "false" {
+		return nil, "", errors.New("API keys are disabled")
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, "", errors.New("user not found")
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return nil, "", errors.New("name is required")
+	}
+	scopes, err := allowedScopes(user.Role, input.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// อายุของ Key: ไม่เกิน api_key_max_days (0 = ไม่จำกัด)
+	maxDays := defaultAPIKeyMaxDays
+	if v, err := strconv.Atoi(s.settings.GetSettingValue("api_key_max_days")); err == nil && v >= 0 {
+		maxDays = v
+	}
+	days := input.ExpiresInDays
+	if days < 0 {
+		return nil, "", errors.New("expires_in_days must not be negative")
+	}
+	if maxDays > 0 && (days == 0 || days > maxDays) {
+		days = maxDays
+	}
+	var expiresAt *time.Time
+	if days > 0 {
+		t := time.Now().AddDate(0, 0, days)
+		expiresAt = &t
+	}
+
+	secret, err := randomURLToken()
+	if err != nil {
+		return nil, "", err
+	}
+	raw := domain.APIKeyPrefix + secret
+
+	key := &domain.APIKey{
+		UserID:    userID,
+		Name:      input.Name,
+		Prefix:    raw[:len(domain.APIKeyPrefix)+6],
+		KeyHash:   hashToken(raw),
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.repo.Create(key); err != nil {
+		return nil, "", err
+	}
+
+	go s.logService.LogClientAction(userID, "API_KEY_CREATED", fmt.Sprintf("Created API key %q (%s, scopes: %s)", key.Name, key.Prefix, key.Scopes), client)
+	return key, raw, nil
+}
+
+// allowedScopes: scope ต้องรู้จัก และ role ของผู้สร้างต้องมีสิทธิ์ทั้งหมดที่ scope นั้นเปิดให้
+func allowedScopes(role string, requested []string) ([]string, error) {
+	seen := map[string]bool{}
+	var scopes []string
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		if scope == "" || seen[scope] {
+			continue
+		}
+		permissions, ok := domain.APIKeyScopePermissions[scope]
+		if !ok {
+			return nil, fmt.Errorf("unknown scope: %s", scope)
+		}
+		for _, p := range permissions {
+			if !domain.HasPermission(role, p) {
+				return nil, fmt.Errorf("your role cannot grant scope: %s", scope)
+			}
+		}
+		seen[scope] = true
+		scopes = append(scopes, scope)
+	}
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	sort.Strings(scopes)
+	return scopes, nil
+}
+
+func (s *apiKeyService) GetMine(userID uint) ([]domain.APIKey, error) {
+	return s.repo.GetByUser(userID)
+}
+
+func (s *apiKeyService) GetAll() ([]domain.APIKey, error) {
+	return s.repo.GetAll()
+}
+
+func (s *apiKeyService) Revoke(id, actorID uint, asAdmin bool, client ports.ClientInfo) error {
+	key, err := s.repo.GetByID(id)
+	if err != nil || (!asAdmin && key.UserID != actorID) {
+		return errors.New("API key not found")
+	}
+
+	ok, err := s.repo.Revoke(id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("API key is already revoked")
+	}
+
+	go s.logService.LogClientAction(actorID, "API_KEY_REVOKED", fmt.Sprintf("Revoked API key %q (%s) of user ID %d", key.Name, key.Prefix, key.UserID), client)
+	return nil
+}
+
+func (s *apiKeyService) Authenticate(rawKey string, client ports.ClientInfo) (*domain.APIKey, *domain.User, error) {
+	if !strings.HasPrefix(rawKey, domain.APIKeyPrefix) || s.settings.GetSettingValue("api_keys_enabled") == "false" {
+		return nil, nil, errInvalidAPIKey
+	}
+
+	now := time.Now()
+	key, err := s.repo.GetByHash(hashToken(rawKey))
+	if err != nil || key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return nil, nil, errInvalidAPIKey
+	}
+
+	// role / สถานะอ่านจากฐานข้อมูลทุกครั้ง: ผู้ใช้ถูกปิดหรือลดสิทธิ์ Key ก็ถูกจำกัดตามทันที
+	user, err := s.userRepo.GetByID(key.UserID)
+	if err != nil || !user.IsActive() {
+		return nil, nil, errInvalidAPIKey
+	}
+
+	if err := s.repo.Touch(key.ID, now, now.Add(-apiKeyTouchInterval), client.IP); err != nil {
+		log.Println("API key: failed to update last used:", err)
+	}
+	return key, user, nil
+}
@@ -2,25 +2,64 @@ package services
 
 import (
 	"errors"
-	"os"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 	"tunorth-brms-backend/internal/core/domain"
 	"tunorth-brms-backend/internal/core/ports"
 
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type authService struct {
-	userRepo ports.UserRepository
+	userRepo     ports.UserRepository
+	tokens       ports.TokenService
+	settings     ports.SettingService
+	verification ports.EmailVerificationService
+	directory    ports.DirectoryAuthenticator
+	twoFactor    ports.TwoFactorService
+	throttle     ports.LoginThrottleService
+	logService   ports.LogService
 }
 
-func NewAuthService(userRepo ports.UserRepository) ports.AuthService {
-	return &authService{userRepo: userRepo}
+var errInvalidCredentials = errors.New("invalid username or password")
+
+func NewAuthService(userRepo ports.UserRepository, tokens ports.TokenService, settings ports.SettingService, verification ports.EmailVerificationService, directory ports.DirectoryAuthenticator, twoFactor ports.TwoFactorService, throttle ports.LoginThrottleService, logService ports.LogService) ports.AuthService {
+	return &authService{
+		userRepo:     userRepo,
+		tokens:       tokens,
+		settings:     settings,
+		verification: verification,
+		directory:    directory,
+		twoFactor:    twoFactor,
+		throttle:     throttle,
+		logService:   logService,
+	}
 }
 
 // Register: สมัครสมาชิก (Hash Password ก่อนบันทึก)
+// สมัครเองได้เฉพาะ role user เสมอ และสถานะเริ่มต้นขึ้นกับการตั้งค่า (ยืนยันอีเมล / รอผู้ดูแลอนุมัติ)
 func (s *authService) Register(user *domain.User) error {
+	user.Email = strings.TrimSpace(user.Email)
+	if user.Email == "" {
+		return errors.New("email is required")
+	}
+	if !emailDomainAllowed(user.Email, s.settings.GetSettingValue("register_allowed_email_domains")) {
+		return errors.New("registration is not allowed for this email domain")
+	}
+
+	user.Role = domain.RoleUser
+	user.EmailVerifiedAt = nil
+	switch {
+	case s.settings.GetSettingValue("register_require_email_verification") == "true":
+		user.Status = domain.UserStatusPendingVerification
+	case s.settings.GetSettingValue("register_require_admin_approval") == "true":
+		user.Status = domain.UserStatusPendingApproval
+	default:
+		user.Status = domain.UserStatusActive
+	}
+
 	// 1. ตรวจสอบว่ามี username นี้หรือยัง
 	existingUser, _ := s.userRepo.GetByUsername(user.Username)
 	if existingUser != nil && existingUser.ID != 0 {
@@ -41,64 +80,260 @@ func (s *authService) Register(user *domain.User) error {
 	user.Password = string(hashedPassword)
 
 	// 3. บันทึก
-	return s.userRepo.Create(user)
+	if err := s.userRepo.Create(user); err != nil {
+		return err
+	}
+
+	// 4. ส่งลิงก์ยืนยันอีเมลเบื้องหลัง
+	go s.verification.SendVerification(user)
+	return nil
+}
+
+// emailDomainAllowed: allowed ว่าง = รับทุกโดเมน (คั่นด้วยจุลภาค รวมโดเมนย่อย)
+func emailDomainAllowed(email, allowed string) bool {
+	allowed = strings.TrimSpace(allowed)
+	if allowed == "" {
+		return true
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domainPart := strings.ToLower(email[at+1:])
+	for _, d := range strings.Split(allowed, ",") {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if d != "" && (domainPart == d || strings.HasSuffix(domainPart, "."+d)) {
+			return true
+		}
+	}
+	return false
 }
 
 // Login: ตรวจสอบรหัสและออก Token (รองรับ Username หรือ Email)
-func (s *authService) Login(identifier, password string) (string, uint, error) {
+func (s *authService) Login(identifier, password string, client ports.ClientInfo) (*ports.TokenPair, uint, error) {
 	// 1. หา User (By Username or Email)
 	user, err := s.userRepo.GetByUsernameOrEmail(identifier)
-	if err != nil {
-		return "", 0, errors.New("invalid username/email or password")
+
+	// 1.1 กันการเดารหัสผ่าน: นับต่อบัญชี (username จริงถ้ามี) และต่อ IP
+	accountKey := identifier
+	var accountID uint
+	if err == nil {
+		accountKey = user.Username
+		accountID = user.ID
+	}
+	if err := s.throttle.Check(accountKey, client.IP); err != nil {
+		return nil, 0, err
+	}
+
+	switch {
+	case err == nil && user.IsLocal():
+		// 2. ตรวจสอบรหัสผ่าน (Hash vs Plain) - บัญชีในระบบ เช่น admin เริ่มต้น
+		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+		if err != nil {
+			s.throttle.RecordFailure(accountKey, accountID, "invalid password", client)
+			return nil, 0, errInvalidCredentials
+		}
+	case s.directory.Enabled():
+		// 2. ตรวจสอบกับ LDAP / Active Directory (สร้างบัญชีให้อัตโนมัติเมื่อ Login ครั้งแรก)
+		user, err = s.directoryLogin(identifier, password, client)
+		if err != nil {
+			if errors.Is(err, errInvalidCredentials) {
+				s.throttle.RecordFailure(accountKey, accountID, "invalid directory credentials", client)
+			}
+			return nil, 0, err
+		}
+		accountKey = user.Username
+	default:
+		s.throttle.RecordFailure(accountKey, 0, "unknown account", client)
+		return nil, 0, errInvalidCredentials
+	}
+
+	// 2.1 บัญชีที่ยังไม่พร้อมใช้งาน (บอกเหตุผลได้ เพราะรหัสผ่านถูกต้องแล้ว)
+	switch user.Status {
+	case domain.UserStatusPendingVerification:
+		return nil, 0, errors.New("please verify your email address before logging in")
+	case domain.UserStatusPendingApproval:
+		return nil, 0, errors.New("your account is awaiting administrator approval")
+	case domain.UserStatusDisabled:
+		return nil, 0, errors.New("your account has been disabled")
+	}
+
+	// 2.2 ยืนยันตัวตนขั้นที่สอง (TOTP): คืน *ports.TwoFactorRequiredError ให้ Frontend ส่งรหัสไปที่ /api/login/2fa
+	if s.twoFactor.IsRequired(user) {
+		challenge, err := s.twoFactor.StartChallenge(user, client)
+		if err != nil {
+			return nil, 0, err
+		}
+		return nil, user.ID, challenge
 	}
 
-	// 2. ตรวจสอบรหัสผ่าน (Hash vs Plain)
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	// 3. ออก Access Token อายุสั้น + Refresh Token
+	tokens, err := s.tokens.Issue(user, client)
 	if err != nil {
-		return "", 0, errors.New("invalid username or password")
+		return nil, 0, err
 	}
 
-	// 3. สร้าง JWT Token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id":  user.ID,
-		"username": user.Username,
-		"role":     user.Role,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(), // หมดอายุใน 24 ชม.
-	})
+	s.throttle.RecordSuccess(accountKey)
+	return tokens, user.ID, nil
+}
 
-	// เซ็นลายเซ็นด้วย Secret Key (จาก .env)
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+// directoryLogin: ข้อมูลชื่อ หน่วยงาน อีเมล และ role ยึดตาม Directory ทุกครั้งที่ Login
+func (s *authService) directoryLogin(identifier, password string, client ports.ClientInfo) (*domain.User, error) {
+	entry, err := s.directory.Authenticate(identifier, password)
 	if err != nil {
-		return "", 0, err
+		switch {
+		case errors.Is(err, ports.ErrDirectoryInvalidCredentials):
+			return nil, errInvalidCredentials
+		case errors.Is(err, ports.ErrDirectoryAccessDenied):
+			return nil, err
+		}
+		log.Println("LDAP login:", err)
+		return nil, errors.New("directory service is unavailable, please try again later")
 	}
 
-	return tokenString, user.ID, nil
+	user, err := s.userRepo.GetByUsername(entry.Username)
+	if err != nil {
+		// Login ครั้งแรก: รหัสผ่านในระบบเป็นค่าสุ่ม (ใช้ Login แบบ local ไม่ได้)
+		random, err := randomHex(32)
+		if err != nil {
+			return nil, err
+		}
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(random), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		user = &domain.User{
+			Username:        entry.Username,
+			Password:        string(hashedPassword),
+			FullName:        entry.FullName,
+			Department:      entry.Department,
+			Role:            entry.Role,
+			Email:           entry.Email,
+			Status:          domain.UserStatusActive,
+			EmailVerifiedAt: &now,
+			AuthSource:      domain.AuthSourceLDAP,
+		}
+		if err := s.userRepo.Create(user); err != nil {
+			log.Println("LDAP login: failed to provision user:", err)
+			return nil, errors.New("could not create an account from the directory (username or email may already be in use)")
+		}
+		go s.logService.LogClientAction(user.ID, "LDAP_PROVISION_USER", fmt.Sprintf("Created user %s from directory (%s, role: %s)", user.Username, entry.DN, user.Role), client)
+		return user, nil
+	}
+
+	// มีบัญชี local ชื่อเดียวกันอยู่แล้ว: ไม่ยึดบัญชีนั้นด้วยรหัสผ่านจาก Directory
+	if user.IsLocal() {
+		return nil, errInvalidCredentials
+	}
+
+	roleChanged := user.Role != entry.Role
+	user.FullName = entry.FullName
+	user.Department = entry.Department
+	if entry.Email != "" {
+		user.Email = entry.Email
+	}
+	user.Role = entry.Role
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	// กลุ่มใน Directory เปลี่ยน: Token เดิมที่มี role เก่าต้องใช้ไม่ได้
+	if roleChanged {
+		if err := s.tokens.RevokeUserTokens(user.ID); err != nil {
+			return nil, err
+		}
+		go s.logService.LogClientAction(user.ID, "LDAP_ROLE_SYNC", fmt.Sprintf("Role of %s set to %s from directory groups", user.Username, user.Role), client)
+		return s.userRepo.GetByID(user.ID)
+	}
+	return user, nil
 }
 
 func (s *authService) GetMe(userID uint) (*domain.User, error) {
 	return s.userRepo.GetByID(userID)
 }
 
-func (s *authService) UpdateMe(userID uint, updates *domain.User) error {
+func (s *authService) UpdateMe(userID uint, updates *domain.User) (*domain.User, error) {
 	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// เปลี่ยนอีเมล (ค่าว่าง = ไม่เปลี่ยน): ตรวจเหมือนตอนสมัคร และอีเมลใหม่ยังไม่ถือว่ายืนยันแล้ว
+	emailChanged := false
+	if email := strings.TrimSpace(updates.Email); email != "" && !strings.EqualFold(email, user.Email) {
+		if err := s.checkNewEmail(user, email); err != nil {
+			return nil, err
+		}
+		if s.settings.GetSettingValue("register_require_email_verification") == "true" {
+			user.PendingEmail = email // เปลี่ยนจริงเมื่อกดลิงก์ที่ส่งไปอีเมลใหม่
+		} else {
+			user.Email = email
+			user.PendingEmail = ""
+			user.EmailVerifiedAt = nil
+		}
+		emailChanged = true
 	}
 
 	// Update fields
 	user.FullName = updates.FullName
-	user.Email = updates.Email
 	user.Tel = updates.Tel
-    user.Department = updates.Department
+	user.TelegramChatID = updates.TelegramChatID
+	user.LineUserID = updates.LineUserID
+	if updates.Language != "" {
+		user.Language = updates.Language
+	}
+	user.Department = updates.Department
 
 	// If password provided, hash it
+	passwordChanged := false
 	if updates.Password != "" {
+		if !user.IsLocal() {
+			return nil, errors.New("password is managed by the directory and cannot be changed here")
+		}
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(updates.Password), bcrypt.DefaultCost)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		user.Password = string(hashedPassword)
+		passwordChanged = true
 	}
 
-	return s.userRepo.Update(user)
-}
\ No newline at end of file
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	if emailChanged {
+		go s.logService.LogAction(userID, "REQUEST_EMAIL_CHANGE", fmt.Sprintf("ขอเปลี่ยนอีเมลเป็น %s", strings.TrimSpace(updates.Email)), "", "")
+		if user.PendingEmail != "" {
+			go s.verification.SendEmailChange(user)
+		}
+	}
+
+	// เปลี่ยนรหัสผ่าน: ให้ทุกเครื่องที่ Login ไว้ต้อง Login ใหม่
+	if passwordChanged {
+		if err := s.tokens.RevokeUserTokens(userID); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// checkNewEmail: กฎเดียวกับการสมัคร (โดเมนที่อนุญาต / ไม่ซ้ำกับบัญชีอื่น)
+func (s *authService) checkNewEmail(user *domain.User, email string) error {
+	if !user.IsLocal() {
+		return errors.New("email address is managed by the directory and cannot be changed here")
+	}
+	if !strings.Contains(email, "@") {
+		return errors.New("invalid email address")
+	}
+	if !emailDomainAllowed(email, s.settings.GetSettingValue("register_allowed_email_domains")) {
+		return errors.New("this email domain is not allowed")
+	}
+	for _, candidate := range []string{email, strings.ToLower(email)} {
+		if existing, err := s.userRepo.GetByEmail(candidate); err == nil && existing.ID != 0 && existing.ID != user.ID {
+			return errors.New("email already exists")
+		}
+	}
+	return nil
+}
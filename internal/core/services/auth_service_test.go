@@ -0,0 +1,370 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+// fakeUserRepo เก็บผู้ใช้ในหน่วยความจำ
+type fakeUserRepo struct {
+	mu    sync.Mutex
+	users map[uint]*domain.User
+}
+
+func newFakeUserRepo(users ...*domain.User) *fakeUserRepo {
+	repo := &fakeUserRepo{users: map[uint]*domain.User{}}
+	for _, u := range users {
+		_ = repo.Create(u)
+	}
+	return repo
+}
+
+var errUserNotFound = errors.New("record not found")
+
+func (r *fakeUserRepo) Create(user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Username == user.Username || (user.Email != "" && u.Email == user.Email) {
+			return errors.New("duplicate key value violates unique constraint")
+		}
+	}
+	if user.ID == 0 {
+		user.ID = uint(len(r.users) + 1)
+	}
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+func (r *fakeUserRepo) find(match func(u *domain.User) bool) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if match(u) {
+			found := *u
+			return &found, nil
+		}
+	}
+	return nil, errUserNotFound
+}
+
+func (r *fakeUserRepo) GetByUsername(username string) (*domain.User, error) {
+	return r.find(func(u *domain.User) bool { return u.Username == username })
+}
+
+func (r *fakeUserRepo) GetByEmail(email string) (*domain.User, error) {
+	return r.find(func(u *domain.User) bool { return u.Email == email })
+}
+
+func (r *fakeUserRepo) GetByUsernameOrEmail(identifier string) (*domain.User, error) {
+	return r.find(func(u *domain.User) bool { return u.Username == identifier || u.Email == identifier })
+}
+
+func (r *fakeUserRepo) GetByID(id uint) (*domain.User, error) {
+	return r.find(func(u *domain.User) bool { return u.ID == id })
+}
+
+func (r *fakeUserRepo) GetAll() ([]domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []domain.User
+	for _, u := range r.users {
+		users = append(users, *u)
+	}
+	return users, nil
+}
+
+func (r *fakeUserRepo) GetByRoles(roles ...string) ([]domain.User, error) {
+	users, _ := r.GetAll()
+	var matched []domain.User
+	for _, u := range users {
+		if contains(roles, u.Role) {
+			matched = append(matched, u)
+		}
+	}
+	return matched, nil
+}
+
+func (r *fakeUserRepo) Update(user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[user.ID]; !ok {
+		return errUserNotFound
+	}
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+func (r *fakeUserRepo) IncrementTokenVersion(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u, ok := r.users[id]; ok {
+		u.TokenVersion++
+	}
+	return nil
+}
+
+func (r *fakeUserRepo) Delete(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.users, id)
+	return nil
+}
+
+func (r *fakeUserRepo) Count() (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.users)), nil
+}
+
+// fakeTokenService ออก Token ปลอม และนับการเพิกถอน
+type fakeTokenService struct {
+	ports.TokenService
+	revoked []uint
+}
+
+func (s *fakeTokenService) Issue(user *domain.User, client ports.ClientInfo) (*ports.TokenPair, error) {
+	return &ports.TokenPair{AccessToken: "access-" + user.Username, RefreshToken: "refresh-" + user.Username}, nil
+}
+
+func (s *fakeTokenService) RevokeUserTokens(userID uint) error {
+	s.revoked = append(s.revoked, userID)
+	return nil
+}
+
+// fakeThrottle ไม่ล็อกใคร แค่จดเหตุผลของความล้มเหลว
+type fakeThrottle struct {
+	ports.LoginThrottleService
+	failures []string
+}
+
+func (t *fakeThrottle) Check(accountKey, ip string) error { return nil }
+
+func (t *fakeThrottle) RecordFailure(accountKey string, userID uint, reason string, client ports.ClientInfo) {
+	t.failures = append(t.failures, reason)
+}
+
+func (t *fakeThrottle) RecordSuccess(accountKey string) {}
+
+type noTwoFactor struct{ ports.TwoFactorService }
+
+func (noTwoFactor) IsRequired(user *domain.User) bool { return false }
+
+// fakeDirectory แทน LDAP: รหัสผ่านถูกต้องเมื่อตรงกับ passwords[username]
+type fakeDirectory struct {
+	passwords map[string]string
+	entries   map[string]*ports.DirectoryUser
+	err       error // ไม่ว่าง = Directory ใช้งานไม่ได้
+}
+
+func (d *fakeDirectory) Enabled() bool { return true }
+
+func (d *fakeDirectory) Authenticate(username, password string) (*ports.DirectoryUser, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if want, ok := d.passwords[username]; !ok || want != password {
+		return nil, ports.ErrDirectoryInvalidCredentials
+	}
+	entry := *d.entries[username]
+	return &entry, nil
+}
+
+type authFixture struct {
+	service   ports.AuthService
+	users     *fakeUserRepo
+	tokens    *fakeTokenService
+	throttle  *fakeThrottle
+	directory *fakeDirectory
+}
+
+func newAuthFixture(settings []domain.Setting, users ...*domain.User) *authFixture {
+	f := &authFixture{
+		users:    newFakeUserRepo(users...),
+		tokens:   &fakeTokenService{},
+		throttle: &fakeThrottle{},
+		directory: &fakeDirectory{
+			passwords: map[string]string{"somchai": "ldap-pass"},
+			entries: map[string]*ports.DirectoryUser{
+				"somchai": {
+					DN:         "uid=somchai,ou=people,dc=tu,dc=ac,dc=th",
+					Username:   "somchai",
+					FullName:   "Somchai Jaidee",
+					Department: "Engineering",
+					Email:      "somchai@tu.ac.th",
+					Role:       domain.RoleUser,
+				},
+			},
+		},
+	}
+	settingService := NewSettingService(newFakeSettingRepo(settings...), fakeLogService{}, nil, "")
+	f.service = NewAuthService(f.users, f.tokens, settingService, nil, f.directory, noTwoFactor{}, f.throttle, fakeLogService{})
+	return f
+}
+
+func TestDirectoryLoginBindFailure(t *testing.T) {
+	f := newAuthFixture(nil)
+
+	if _, _, err := f.service.Login("somchai", "wrong", ports.ClientInfo{}); !errors.Is(err, errInvalidCredentials) {
+		t.Fatalf("wrong password: got %v, want errInvalidCredentials", err)
+	}
+	if len(f.throttle.failures) != 1 {
+		t.Fatalf("got %d recorded failures, want 1", len(f.throttle.failures))
+	}
+	if n, _ := f.users.Count(); n != 0 {
+		t.Fatalf("a failed bind provisioned %d users", n)
+	}
+
+	// Directory ล่ม: ไม่นับเป็นการเดารหัสผ่าน และไม่บอกรายละเอียดภายใน
+	f.directory.err = errors.New("ldap: connection refused")
+	_, _, err := f.service.Login("somchai", "ldap-pass", ports.ClientInfo{})
+	if err == nil || strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("directory down: got %v", err)
+	}
+	if len(f.throttle.failures) != 1 {
+		t.Fatal("an unavailable directory was counted as a failed login")
+	}
+
+	// รหัสผ่านถูกแต่ไม่อยู่ในกลุ่มที่อนุญาต
+	f.directory.err = ports.ErrDirectoryAccessDenied
+	if _, _, err := f.service.Login("somchai", "ldap-pass", ports.ClientInfo{}); !errors.Is(err, ports.ErrDirectoryAccessDenied) {
+		t.Fatalf("access denied: got %v", err)
+	}
+}
+
+func TestDirectoryLoginProvisionsOnFirstLogin(t *testing.T) {
+	f := newAuthFixture(nil)
+
+	tokens, userID, err := f.service.Login("somchai", "ldap-pass", ports.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens == nil || tokens.AccessToken == "" {
+		t.Fatal("no tokens issued")
+	}
+
+	user, err := f.users.GetByID(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.AuthSource != domain.AuthSourceLDAP || user.Status != domain.UserStatusActive || user.EmailVerifiedAt == nil {
+		t.Fatalf("provisioned user = %+v", user)
+	}
+	if user.FullName != "Somchai Jaidee" || user.Department != "Engineering" || user.Email != "somchai@tu.ac.th" || user.Role != domain.RoleUser {
+		t.Fatalf("provisioned user attributes = %+v", user)
+	}
+	if user.Password == "" || user.Password == "ldap-pass" {
+		t.Fatal("directory password must not be stored")
+	}
+}
+
+func TestDirectoryLoginSyncsAttributes(t *testing.T) {
+	f := newAuthFixture(nil)
+	if _, _, err := f.service.Login("somchai", "ldap-pass", ports.ClientInfo{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// ข้อมูลใน Directory เปลี่ยน (ย้ายหน่วยงาน ได้สิทธิ์อนุมัติ): ต้องตามมาในการ Login ครั้งถัดไป
+	entry := f.directory.entries["somchai"]
+	entry.FullName = "Somchai Jaidee-Suk"
+	entry.Department = "Science"
+	entry.Email = "somchai.j@tu.ac.th"
+	entry.Role = domain.RoleApprover
+
+	_, userID, err := f.service.Login("somchai", "ldap-pass", ports.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, _ := f.users.GetByID(userID)
+	if user.FullName != "Somchai Jaidee-Suk" || user.Department != "Science" || user.Email != "somchai.j@tu.ac.th" || user.Role != domain.RoleApprover {
+		t.Fatalf("synced user = %+v", user)
+	}
+	if n, _ := f.users.Count(); n != 1 {
+		t.Fatalf("second login created another account (%d users)", n)
+	}
+	// role เปลี่ยน: Token เดิมต้องถูกเพิกถอน
+	if len(f.tokens.revoked) != 1 || f.tokens.revoked[0] != userID {
+		t.Fatalf("revoked = %v, want [%d]", f.tokens.revoked, userID)
+	}
+}
+
+func TestDirectoryLoginDoesNotTakeOverLocalAccount(t *testing.T) {
+	local := &domain.User{Username: "somchai", Password: "$2a$10$invalid", Role: domain.RoleAdmin, Status: domain.UserStatusActive}
+	f := newAuthFixture(nil, local)
+
+	// ชื่อเดียวกับบัญชี local: ใช้รหัสผ่านจาก Directory แทนไม่ได้
+	if _, _, err := f.service.Login("somchai", "ldap-pass", ports.ClientInfo{}); !errors.Is(err, errInvalidCredentials) {
+		t.Fatalf("got %v, want errInvalidCredentials", err)
+	}
+}
+
+// fakeVerification ส่งผู้ใช้ที่ขอเปลี่ยนอีเมลออกทาง channel (SendEmailChange ถูกเรียกใน goroutine)
+type fakeVerification struct {
+	ports.EmailVerificationService
+	changes chan domain.User
+}
+
+func (v *fakeVerification) SendEmailChange(user *domain.User) {
+	v.changes <- *user
+}
+
+func TestUpdateMeEmailChange(t *testing.T) {
+	newService := func(requireVerification string) (ports.AuthService, *fakeVerification) {
+		verifiedAt := time.Now()
+		users := newFakeUserRepo(
+			&domain.User{Username: "somchai", Email: "somchai@tu.ac.th", EmailVerifiedAt: &verifiedAt, Status: domain.UserStatusActive},
+			&domain.User{Username: "somsri", Email: "somsri@tu.ac.th", Status: domain.UserStatusActive},
+		)
+		settings := NewSettingService(newFakeSettingRepo(
+			domain.Setting{SettingName: "register_require_email_verification", SettingValue: requireVerification},
+			domain.Setting{SettingName: "register_allowed_email_domains", SettingValue: "tu.ac.th"},
+		), fakeLogService{}, nil, "")
+		verification := &fakeVerification{changes: make(chan domain.User, 1)}
+		return NewAuthService(users, &fakeTokenService{}, settings, verification, nil, noTwoFactor{}, &fakeThrottle{}, fakeLogService{}), verification
+	}
+
+	t.Run("rejected", func(t *testing.T) {
+		service, _ := newService("true")
+		for _, email := range []string{"not-an-email", "somchai@gmail.com", "somsri@tu.ac.th"} {
+			if _, err := service.UpdateMe(1, &domain.User{Email: email}); err == nil {
+				t.Errorf("changed email to %q", email)
+			}
+		}
+	})
+
+	t.Run("verification required", func(t *testing.T) {
+		service, verification := newService("true")
+		user, err := service.UpdateMe(1, &domain.User{Email: "new@tu.ac.th"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// อีเมลเดิมใช้ต่อจนกว่าจะกดลิงก์ที่ส่งไปอีเมลใหม่
+		if user.Email != "somchai@tu.ac.th" || user.PendingEmail != "new@tu.ac.th" {
+			t.Fatalf("email = %q, pending = %q", user.Email, user.PendingEmail)
+		}
+		if sent := <-verification.changes; sent.PendingEmail != "new@tu.ac.th" {
+			t.Fatalf("change link sent for %q", sent.PendingEmail)
+		}
+	})
+
+	t.Run("verification not required", func(t *testing.T) {
+		service, verification := newService("false")
+		user, err := service.UpdateMe(1, &domain.User{Email: "new@tu.ac.th"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.Email != "new@tu.ac.th" || user.PendingEmail != "" || user.EmailVerifiedAt != nil {
+			t.Fatalf("user after change = %+v", user)
+		}
+		if len(verification.changes) != 0 {
+			t.Fatal("change link sent although verification is off")
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+// maxBookingAttendees กันการเชิญทั้งองค์กรในครั้งเดียว
+const maxBookingAttendees = 200
+
+type bookingAttendeeService struct {
+	repo        ports.BookingAttendeeRepository
+	bookingRepo ports.BookingRepository
+	userRepo    ports.UserRepository
+	logService  ports.LogService
+}
+
+func NewBookingAttendeeService(repo ports.BookingAttendeeRepository, bookingRepo ports.BookingRepository, userRepo ports.UserRepository, logService ports.LogService) ports.BookingAttendeeService {
+	return &bookingAttendeeService{
+		repo:        repo,
+		bookingRepo: bookingRepo,
+		userRepo:    userRepo,
+		logService:  logService,
+	}
+}
+
+func (s *bookingAttendeeService) GetAttendees(bookingID, actorID uint) ([]domain.AttendeeView, error) {
+	booking, err := s.bookingRepo.GetByID(bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+	actor, err := s.userRepo.GetByID(actorID)
+	if err != nil {
+		return nil, ports.ErrAttendeesForbidden
+	}
+	attendees, err := s.repo.GetByBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check permission: Owner OR ผู้ได้รับเชิญ OR Approver / Admin
+	allowed := booking.UserID == actorID || actor.Role == domain.RoleAdmin || actor.Role == domain.RoleApprover
+	views := make([]domain.AttendeeView, len(attendees))
+	for i, a := range attendees {
+		if a.UserID == actorID {
+			allowed = true
+		}
+		views[i] = a.View()
+	}
+	if !allowed {
+		return nil, ports.ErrAttendeesForbidden
+	}
+	return views, nil
+}
+
+func (s *bookingAttendeeService) SetAttendees(bookingID uint, userIDs []uint, actorID uint, client ports.ClientInfo) error {
+	booking, err := s.bookingRepo.GetByID(bookingID)
+	if err != nil {
+		return errors.New("booking not found")
+	}
+	actor, err := s.userRepo.GetByID(actorID)
+	if err != nil {
+		return errors.New("unauthorized")
+	}
+	// Check permission: Owner OR Admin (เหมือนการแก้ไขการจอง)
+	if booking.UserID != actorID && actor.Role != domain.RoleAdmin {
+		return errors.New("you do not have permission to edit this booking")
+	}
+
+	// ตัดรายชื่อซ้ำ และผู้จองเอง (ได้รับการเตือนอยู่แล้ว)
+	seen := map[uint]bool{booking.UserID: true}
+	var invitees []uint
+	for _, id := range userIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		user, err := s.userRepo.GetByID(id)
+		if err != nil || !user.IsActive() {
+			return fmt.Errorf("user %d not found or not active", id)
+		}
+		invitees = append(invitees, id)
+	}
+	if len(invitees) > maxBookingAttendees {
+		return fmt.Errorf("at most %d attendees can be invited", maxBookingAttendees)
+	}
+
+	if err := s.repo.Replace(bookingID, invitees); err != nil {
+		return err
+	}
+
+	go s.logService.LogClientAction(actorID, "SET_BOOKING_ATTENDEES", fmt.Sprintf("Set %d attendees for booking ID: %d", len(invitees), bookingID), client)
+	return nil
+}
+
+func (s *bookingAttendeeService) Respond(bookingID, userID uint, status string, client ports.ClientInfo) error {
+	if status != domain.AttendeeStatusAccepted && status != domain.AttendeeStatusDeclined {
+		return fmt.Errorf("status must be %s or %s", domain.AttendeeStatusAccepted, domain.AttendeeStatusDeclined)
+	}
+	booking, err := s.bookingRepo.GetByID(bookingID)
+	if err != nil {
+		return errors.New("booking not found")
+	}
+	now := time.Now()
+	if !booking.EndTime.After(now) {
+		return errors.New("this meeting has already ended")
+	}
+
+	ok, err := s.repo.Respond(bookingID, userID, status, now)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("you are not invited to this booking")
+	}
+
+	go s.logService.LogClientAction(userID, "RESPOND_BOOKING_INVITATION", fmt.Sprintf("Responded %s to booking ID: %d", status, bookingID), client)
+	return nil
+}
+
+func (s *bookingAttendeeService) GetInvitations(userID uint) ([]domain.BookingAttendee, error) {
+	attendees, err := s.repo.GetByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// การจองที่ถูกลบ (Booking = nil) หรือจบไปแล้วไม่ต้องแสดง
+	now := time.Now()
+	invitations := make([]domain.BookingAttendee, 0, len(attendees))
+	for _, a := range attendees {
+		if a.Booking != nil && a.Booking.EndTime.After(now) {
+			a.Booking.User = a.Booking.User.Public()
+			invitations = append(invitations, a)
+		}
+	}
+	return invitations, nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+func (r *fakeBookingRepo) GetByID(id uint) (*domain.Booking, error) {
+	for _, b := range r.bookings {
+		if b.ID == id {
+			found := b
+			return &found, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeAttendeeRepo) GetByBooking(bookingID uint) ([]domain.BookingAttendee, error) {
+	var found []domain.BookingAttendee
+	for _, a := range r.attendees {
+		if a.BookingID == bookingID {
+			found = append(found, a)
+		}
+	}
+	return found, nil
+}
+
+func TestGetAttendeesOnlyForPeopleInvolved(t *testing.T) {
+	users := newFakeUserRepo(
+		&domain.User{Username: "owner", Role: domain.RoleUser},
+		&domain.User{Username: "guest", Role: domain.RoleUser, FullName: "Guest", Email: "guest@tu.ac.th", Tel: "0812345678", TelegramChatID: "tg-42"},
+		&domain.User{Username: "stranger", Role: domain.RoleUser},
+		&domain.User{Username: "approver", Role: domain.RoleApprover},
+		&domain.User{Username: "admin", Role: domain.RoleAdmin},
+	)
+	id := func(username string) uint {
+		u, _ := users.GetByUsername(username)
+		return u.ID
+	}
+	guest, _ := users.GetByUsername("guest")
+	bookings := &fakeBookingRepo{bookings: []domain.Booking{{ID: 1, UserID: id("owner"), EndTime: time.Now().Add(time.Hour)}}}
+	attendees := &fakeAttendeeRepo{attendees: []domain.BookingAttendee{{BookingID: 1, UserID: guest.ID, User: guest, Status: domain.AttendeeStatusInvited}}}
+	service := NewBookingAttendeeService(attendees, bookings, users, fakeLogService{})
+
+	for _, username := range []string{"owner", "guest", "approver", "admin"} {
+		views, err := service.GetAttendees(1, id(username))
+		if err != nil {
+			t.Fatalf("%s: %v", username, err)
+		}
+		if len(views) != 1 || views[0].FullName != "Guest" {
+			t.Fatalf("%s: attendees = %+v", username, views)
+		}
+		// ไม่มีข้อมูลติดต่อของผู้ได้รับเชิญ
+		raw, _ := json.Marshal(views)
+		for _, private := range []string{"guest@tu.ac.th", "0812345678", "tg-42"} {
+			if strings.Contains(string(raw), private) {
+				t.Errorf("%s sees %s in %s", username, private, raw)
+			}
+		}
+	}
+
+	if _, err := service.GetAttendees(1, id("stranger")); !errors.Is(err, ports.ErrAttendeesForbidden) {
+		t.Fatalf("stranger: got %v, want ErrAttendeesForbidden", err)
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -14,22 +15,22 @@ type bookingService struct {
 	roomRepo   ports.RoomRepository // Add RoomRepo
 	settings   ports.SettingService
 	userRepo   ports.UserRepository
-	notifier   ports.NotificationService
 	logService ports.LogService
+	events     ports.EventPublisher
 }
 
-func NewBookingService(repo ports.BookingRepository, roomRepo ports.RoomRepository, settings ports.SettingService, userRepo ports.UserRepository, notifier ports.NotificationService, logService ports.LogService) ports.BookingService {
+func NewBookingService(repo ports.BookingRepository, roomRepo ports.RoomRepository, settings ports.SettingService, userRepo ports.UserRepository, logService ports.LogService, events ports.EventPublisher) ports.BookingService {
 	return &bookingService{
 		repo:       repo,
 		roomRepo:   roomRepo,
 		settings:   settings,
 		userRepo:   userRepo,
-		notifier:   notifier,
 		logService: logService,
+		events:     events,
 	}
 }
 
-func (s *bookingService) CreateBooking(booking *domain.Booking) error {
+func (s *bookingService) CreateBooking(booking *domain.Booking, client ports.ClientInfo) error {
 	// 1. Validation พื้นฐาน
 	if booking.RoomID == 0 {
 		return errors.New("room_id is required")
@@ -98,17 +99,14 @@ func (s *bookingService) CreateBooking(booking *domain.Booking) error {
 	}
 	booking.Status = defaultStatus
 
-	// 4. บันทึก
-	if err := s.repo.Create(booking); err != nil {
+	// 4. บันทึก พร้อมคิวแจ้งเตือนแอดมิน (Outbox อยู่ใน Transaction เดียวกัน)
+	if err := s.repo.Create(booking, newOutboxMessages(domain.EventBookingCreated, nil)...); err != nil {
 		return err
 	}
 
-	// 5. Notify Admin
-	// เรียกแบบ Async (go func) เพื่อไม่ให้ User ต้องรอ
-	go s.notifier.NotifyAdminNewBooking(booking)
-
 	// 6. Log Activity
-	go s.logService.LogAction(booking.UserID, "CREATE_BOOKING", fmt.Sprintf("จองห้อง ID: %d วันที่: %s", booking.RoomID, booking.StartTime.Format("02/01/2006")), "", "")
+	go s.logService.LogClientAction(booking.UserID, "CREATE_BOOKING", fmt.Sprintf("จองห้อง ID: %d วันที่: %s", booking.RoomID, booking.StartTime.Format("02/01/2006")), client)
+	go s.publish(domain.WebhookEventBookingCreated, booking.ID)
 
 	return nil
 }
@@ -146,7 +144,7 @@ func (s *bookingService) GetBookingByID(id uint) (*domain.Booking, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *bookingService) UpdateBookingStatus(id uint, status string, approverID uint) error {
+func (s *bookingService) UpdateBookingStatus(id uint, status string, approverID uint, client ports.ClientInfo) error {
 	// 1. หา Booking เดิมมาก่อน
 	booking, err := s.repo.GetByID(id)
 	if err != nil {
@@ -157,15 +155,13 @@ func (s *bookingService) UpdateBookingStatus(id uint, status string, approverID
 	booking.Status = status
 	booking.ApproverID = &approverID // บันทึกว่าใครเป็นคนกดอนุมัติ
 	
-	// 3. บันทึก
-	if err := s.repo.Update(booking); err != nil {
+	// 3. บันทึก พร้อมคิวแจ้งเตือนผู้จอง
+	payload := &domain.OutboxPayload{Status: status}
+	if err := s.repo.Update(booking, newOutboxMessages(domain.EventBookingStatusChanged, payload)...); err != nil {
 		return err
 	}
-	
-	// 4. Notify User
-	go s.notifier.NotifyUserStatusChange(booking)
-	
-	// 5. Log
+
+	// 4. Log
 	action := "UPDATE"
 	if status == "approved" {
 		action = "APPROVE"
@@ -174,16 +170,26 @@ func (s *bookingService) UpdateBookingStatus(id uint, status string, approverID
 	} else if status == "cancelled" {
 		action = "CANCEL"
 	}
-	go s.logService.LogAction(approverID, action, fmt.Sprintf("%s รายการจอง ID: %d", status, booking.ID), "", "")
+	go s.logService.LogClientAction(approverID, action, fmt.Sprintf("%s รายการจอง ID: %d", status, booking.ID), client)
+	go s.publish(statusEvent(status), booking.ID)
 
 	return nil
 }
 
-func (s *bookingService) UpdateBooking(id uint, updatedBooking *domain.Booking, actorID uint) error {
+func (s *bookingService) UpdateBooking(id uint, updatedBooking *domain.Booking, actorID uint, client ports.ClientInfo) error {
     existing, err := s.repo.GetByID(id)
     if err != nil {
         return err
     }
+
+	// Check permission: Owner OR Admin (เหมือน DeleteBooking)
+	actor, err := s.userRepo.GetByID(actorID)
+	if err != nil {
+		return errors.New("unauthorized")
+	}
+	if existing.UserID != actorID && actor.Role != "admin" {
+		return errors.New("you do not have permission to edit this booking")
+	}
     
     // Update fields
     existing.Subject = updatedBooking.Subject
@@ -220,12 +226,13 @@ func (s *bookingService) UpdateBooking(id uint, updatedBooking *domain.Booking,
 	}
 
 	// Log
-	go s.logService.LogAction(actorID, "UPDATE_BOOKING", fmt.Sprintf("Updated booking ID: %d", id), "", "")
+	go s.logService.LogClientAction(actorID, "UPDATE_BOOKING", fmt.Sprintf("Updated booking ID: %d", id), client)
+	go s.publish(domain.WebhookEventBookingUpdated, id)
 
 	return nil
 }
 
-func (s *bookingService) DeleteBooking(id uint, actorID uint) error {
+func (s *bookingService) DeleteBooking(id uint, actorID uint, client ports.ClientInfo) error {
 	booking, err := s.repo.GetByID(id)
 	if err != nil {
 		return err
@@ -244,12 +251,62 @@ func (s *bookingService) DeleteBooking(id uint, actorID uint) error {
 		return errors.New("you do not have permission to delete this booking")
 	}
 
-    if err := s.repo.Delete(id); err != nil {
+	// ลบ พร้อมคิวแจ้งเตือนการยกเลิก
+	if err := s.repo.Delete(id, newOutboxMessages(domain.EventBookingCancelled, nil)...); err != nil {
 		return err
 	}
 
 	// Log
-	go s.logService.LogAction(actorID, "DELETE_BOOKING", fmt.Sprintf("Deleted booking ID: %d", id), "", "")
+	go s.logService.LogClientAction(actorID, "DELETE_BOOKING", fmt.Sprintf("Deleted booking ID: %d", id), client)
+	go s.publish(domain.WebhookEventBookingCancelled, id)
 
 	return nil
-}
\ No newline at end of file
+}
+
+// publish: โหลดการจองใหม่ (รวมที่ถูกลบแล้ว) เพื่อให้มีชื่อห้อง/ผู้จองครบ แล้วส่งต่อให้ระบบภายนอก
+func (s *bookingService) publish(eventType string, id uint) {
+	booking, err := s.repo.GetByIDWithDeleted(id)
+	if err != nil {
+		return
+	}
+	s.events.Publish(eventType, domain.NewBookingEvent(booking))
+}
+
+// statusEvent: ชื่อเหตุการณ์ตามสถานะใหม่
+func statusEvent(status string) string {
+	switch status {
+	case "approved":
+		return domain.WebhookEventBookingApproved
+	case "rejected":
+		return domain.WebhookEventBookingRejected
+	case "cancelled":
+		return domain.WebhookEventBookingCancelled
+	default:
+		return domain.WebhookEventBookingUpdated
+	}
+}
+
+// newOutboxMessage สร้างข้อความสำหรับ Outbox (BookingID จะถูกเติมตอนบันทึก)
+func newOutboxMessage(eventType, channel string, payload *domain.OutboxPayload) domain.OutboxMessage {
+	msg := domain.OutboxMessage{
+		EventType:     eventType,
+		Channel:       channel,
+		Status:        domain.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if payload != nil {
+		if b, err := json.Marshal(payload); err == nil {
+			msg.Payload = string(b)
+		}
+	}
+	return msg
+}
+
+// newOutboxMessages สร้างข้อความ 1 รายการต่อช่องทาง (ส่ง/retry แยกกันได้)
+func newOutboxMessages(eventType string, payload *domain.OutboxPayload) []domain.OutboxMessage {
+	msgs := make([]domain.OutboxMessage, 0, len(domain.NotificationChannels))
+	for _, channel := range domain.NotificationChannels {
+		msgs = append(msgs, newOutboxMessage(eventType, channel, payload))
+	}
+	return msgs
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+type chatWebhookService struct {
+	repo       ports.ChatWebhookRepository
+	settings   ports.SettingService
+	logService ports.LogService
+	client     *http.Client
+	box        *secretBox // nil = ไม่ได้ตั้ง SETTINGS_ENCRYPTION_KEY (เพิ่ม/เปลี่ยน URL ไม่ได้)
+}
+
+// NewChatWebhookService: URL ของห้องแชทเข้ารหัสด้วยกุญแจเดียวกับค่าตั้งค่าลับ (SETTINGS_ENCRYPTION_KEY)
+func NewChatWebhookService(repo ports.ChatWebhookRepository, settings ports.SettingService, logService ports.LogService, encryptionKey string) ports.ChatWebhookService {
+	box, _ := newSecretBox(encryptionKey) // ไม่มีกุญแจ: NewSettingService เตือนไว้แล้วตอนเริ่มระบบ
+	return &chatWebhookService{
+		repo:       repo,
+		settings:   settings,
+		logService: logService,
+		client:     &http.Client{Timeout: webhookTimeout},
+		box:        box,
+	}
+}
+
+func (s *chatWebhookService) GetWebhooks() ([]domain.ChatWebhook, error) {
+	webhooks, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		s.redact(&webhooks[i])
+	}
+	return webhooks, nil
+}
+
+func (s *chatWebhookService) GetWebhook(id uint) (*domain.ChatWebhook, error) {
+	webhook, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.redact(webhook)
+	return webhook, nil
+}
+
+func (s *chatWebhookService) CreateWebhook(webhook *domain.ChatWebhook, actorID uint) error {
+	if err := validateChatWebhook(webhook); err != nil {
+		return err
+	}
+	plainURL := webhook.URL
+	sealed, err := s.sealURL(plainURL)
+	if err != nil {
+		return err
+	}
+
+	webhook.ID = 0
+	webhook.URL = sealed
+	err = s.repo.Create(webhook)
+	webhook.URL = plainURL // Handler ส่งกลับให้ผู้ดูแลครั้งเดียว
+	if err != nil {
+		return err
+	}
+	webhook.Redact(plainURL)
+
+	go s.logService.LogAction(actorID, "CREATE_CHAT_WEBHOOK", fmt.Sprintf("Created %s webhook: %s", webhook.Platform, webhook.Name), "", "")
+	return nil
+}
+
+func (s *chatWebhookService) UpdateWebhook(id uint, input *domain.ChatWebhook, actorID uint) error {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("chat webhook not found")
+	}
+
+	// ตรวจและเข้ารหัสใหม่จาก URL จริง (ค่าเก่าที่ยังไม่เข้ารหัสจะถูกเข้ารหัสไปด้วย)
+	plainURL, err := s.openURL(existing)
+	if err != nil {
+		return err
+	}
+	if input.URL != "" { // ว่าง = ใช้ URL เดิม
+		plainURL = input.URL
+	}
+
+	existing.Name = input.Name
+	existing.Platform = input.Platform
+	existing.URL = plainURL
+	existing.RoomID = input.RoomID
+	existing.Department = input.Department
+	existing.Events = input.Events
+	existing.Language = input.Language
+	existing.IsActive = input.IsActive
+	if err := validateChatWebhook(existing); err != nil {
+		return err
+	}
+	if existing.URL, err = s.sealURL(plainURL); err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(existing); err != nil {
+		return err
+	}
+
+	go s.logService.LogAction(actorID, "UPDATE_CHAT_WEBHOOK", fmt.Sprintf("Updated chat webhook ID: %d", id), "", "")
+	return nil
+}
+
+func (s *chatWebhookService) DeleteWebhook(id uint, actorID uint) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return errors.New("chat webhook not found")
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	go s.logService.LogAction(actorID, "DELETE_CHAT_WEBHOOK", fmt.Sprintf("Deleted chat webhook ID: %d", id), "", "")
+	return nil
+}
+
+// Test: ส่งข้อความทดสอบทันที เพื่อตรวจว่า URL ถูกต้อง
+func (s *chatWebhookService) Test(id uint, actorID uint) error {
+	webhook, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("chat webhook not found")
+	}
+
+	siteName := s.settings.GetSettingValue("site_name")
+	err = s.Post(webhook, ports.ChatMessage{
+		Title: fmt.Sprintf("%s: ทดสอบการเชื่อมต่อ", siteName),
+		Text:  fmt.Sprintf("ห้องแชทนี้จะได้รับการแจ้งเตือนการจองจาก %s", siteName),
+		Link:  publicBaseURL(s.settings),
+	})
+
+	go s.logService.LogAction(actorID, "TEST_CHAT_WEBHOOK", fmt.Sprintf("Test chat webhook ID: %d", id), "", "")
+	return err
+}
+
+func (s *chatWebhookService) Targets(eventType string, booking *domain.Booking) ([]domain.ChatWebhook, error) {
+	webhooks, err := s.repo.GetActive()
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []domain.ChatWebhook
+	for _, w := range webhooks {
+		if w.Matches(eventType, booking) {
+			targets = append(targets, w)
+		}
+	}
+	return targets, nil
+}
+
+func (s *chatWebhookService) Post(webhook *domain.ChatWebhook, msg ports.ChatMessage) error {
+	var payload interface{}
+	switch webhook.Platform {
+	case domain.ChatPlatformSlack:
+		payload = slackPayload(msg)
+	case domain.ChatPlatformDiscord:
+		payload = discordPayload(msg, s.settings.GetSettingValue("site_name"))
+	case domain.ChatPlatformTeams:
+		payload = teamsPayload(msg)
+	default:
+		return fmt.Errorf("unknown platform: %s", webhook.Platform)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	target, err := s.openURL(webhook)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(target, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return withoutURL(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("failed to post to %s, status: %d %s", webhook.Platform, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// SealStoredURLs: URL ที่บันทึกก่อนมีการเข้ารหัสยังเป็นข้อความธรรมดา เข้ารหัสทับเมื่อมีกุญแจ
+func (s *chatWebhookService) SealStoredURLs() error {
+	if s.box == nil {
+		return nil
+	}
+	webhooks, err := s.repo.GetAll()
+	if err != nil {
+		return err
+	}
+	for i := range webhooks {
+		if webhooks[i].URL == "" || isEncrypted(webhooks[i].URL) {
+			continue
+		}
+		if webhooks[i].URL, err = s.box.seal(webhooks[i].URL); err != nil {
+			return err
+		}
+		if err := s.repo.Update(&webhooks[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sealURL: ไม่มีกุญแจ = ปฏิเสธ (ไม่เก็บ URL เป็นข้อความธรรมดา)
+func (s *chatWebhookService) sealURL(plainURL string) (string, error) {
+	if s.box == nil {
+		return "", ports.ErrChatWebhookEncryptionKeyMissing
+	}
+	return s.box.seal(plainURL)
+}
+
+// openURL ถอดรหัสตอนส่ง / ตอนแสดงชื่อโฮสต์ (ค่าเก่าที่ยังไม่เข้ารหัสใช้ได้ตามเดิม)
+func (s *chatWebhookService) openURL(webhook *domain.ChatWebhook) (string, error) {
+	if !isEncrypted(webhook.URL) {
+		return webhook.URL, nil
+	}
+	if s.box == nil {
+		return "", errors.New("chat webhook URL is encrypted but SETTINGS_ENCRYPTION_KEY is not set")
+	}
+	return s.box.open(webhook.URL)
+}
+
+// redact: ถอดรหัสไม่ได้ยังแสดงรายการได้ แค่ไม่มีชื่อโฮสต์
+func (s *chatWebhookService) redact(webhook *domain.ChatWebhook) {
+	plainURL, err := s.openURL(webhook)
+	if err != nil {
+		log.Printf("Chat webhook %d: %v", webhook.ID, err)
+	}
+	webhook.Redact(plainURL)
+}
+
+// slackPayload: Block Kit (หัวข้อ + เนื้อหา mrkdwn + ปุ่มลิงก์) และ text สำหรับการแจ้งเตือนบนมือถือ
+func slackPayload(msg ports.ChatMessage) map[string]interface{} {
+	blocks := []map[string]interface{}{
+		{"type": "header", "text": map[string]string{"type": "plain_text", "text": msg.Title}},
+		{"type": "section", "text": map[string]string{"type": "mrkdwn", "text": slackEscape(msg.Text)}},
+	}
+	if msg.Link != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "actions",
+			"elements": []map[string]interface{}{{
+				"type": "button",
+				"text": map[string]string{"type": "plain_text", "text": "เปิดดู"},
+				"url":  msg.Link,
+			}},
+		})
+	}
+	return map[string]interface{}{"text": msg.Title, "blocks": blocks}
+}
+
+// slackEscape: mrkdwn ต้อง escape &, <, >
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// discordPayload: Embed ใช้สีของห้องเป็นแถบด้านข้าง
+func discordPayload(msg ports.ChatMessage, siteName string) map[string]interface{} {
+	embed := map[string]interface{}{
+		"title":       msg.Title,
+		"description": msg.Text,
+	}
+	if msg.Link != "" {
+		embed["url"] = msg.Link
+	}
+	if color, ok := hexColor(msg.Color); ok {
+		embed["color"] = color
+	}
+
+	payload := map[string]interface{}{"embeds": []interface{}{embed}}
+	if siteName != "" {
+		payload["username"] = siteName
+	}
+	return payload
+}
+
+// teamsPayload: Adaptive Card (รูปแบบที่ Workflows ของ Teams รับได้)
+func teamsPayload(msg ports.ChatMessage) map[string]interface{} {
+	body := []map[string]interface{}{
+		{"type": "TextBlock", "text": msg.Title, "weight": "Bolder", "size": "Medium", "wrap": true},
+	}
+	for _, line := range strings.Split(msg.Text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		body = append(body, map[string]interface{}{"type": "TextBlock", "text": line, "wrap": true, "spacing": "None"})
+	}
+
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if msg.Link != "" {
+		card["actions"] = []map[string]string{{"type": "Action.OpenUrl", "title": "เปิดดู", "url": msg.Link}}
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     card,
+		}},
+	}
+}
+
+// hexColor แปลง "#db2777" เป็นตัวเลขสำหรับ Discord
+func hexColor(value string) (int, bool) {
+	value = strings.TrimPrefix(value, "#")
+	if len(value) != 6 {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(value, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int(n), true
+}
+
+func validateChatWebhook(webhook *domain.ChatWebhook) error {
+	if webhook.Name == "" {
+		return errors.New("name is required")
+	}
+	if !contains(domain.ChatPlatforms, webhook.Platform) {
+		return fmt.Errorf("platform must be one of: %s", strings.Join(domain.ChatPlatforms, ", "))
+	}
+	u, err := url.Parse(webhook.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return errors.New("url must be a valid https URL")
+	}
+	for _, e := range webhook.Events {
+		if !contains(domain.ChatGroupEvents, e) {
+			return fmt.Errorf("unknown event type: %s", e)
+		}
+	}
+	if len(webhook.Language) > 5 {
+		return errors.New("language must be a language code (e.g. th, en)")
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+// fakeChatWebhookRepo เก็บห้องแชทในหน่วยความจำ
+type fakeChatWebhookRepo struct {
+	ports.ChatWebhookRepository
+	mu       sync.Mutex
+	webhooks map[uint]domain.ChatWebhook
+}
+
+func newFakeChatWebhookRepo(webhooks ...domain.ChatWebhook) *fakeChatWebhookRepo {
+	repo := &fakeChatWebhookRepo{webhooks: map[uint]domain.ChatWebhook{}}
+	for _, w := range webhooks {
+		_ = repo.Create(&w)
+	}
+	return repo
+}
+
+func (r *fakeChatWebhookRepo) GetAll() ([]domain.ChatWebhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var webhooks []domain.ChatWebhook
+	for _, w := range r.webhooks {
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, nil
+}
+
+func (r *fakeChatWebhookRepo) GetByID(id uint) (*domain.ChatWebhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w, ok := r.webhooks[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &w, nil
+}
+
+func (r *fakeChatWebhookRepo) Create(webhook *domain.ChatWebhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhook.ID = uint(len(r.webhooks) + 1)
+	r.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+func (r *fakeChatWebhookRepo) Update(webhook *domain.ChatWebhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+func (r *fakeChatWebhookRepo) stored(id uint) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.webhooks[id].URL
+}
+
+// newChatWebhookFixture: ปลายทางเป็น TLS (validateChatWebhook รับเฉพาะ https) นับจำนวนข้อความที่ได้รับ
+func newChatWebhookFixture(t *testing.T, encryptionKey string, webhooks ...domain.ChatWebhook) (*chatWebhookService, *fakeChatWebhookRepo, *httptest.Server, *int) {
+	t.Helper()
+	var posts int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/T000/B000/XXXX" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		posts++
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	repo := newFakeChatWebhookRepo(webhooks...)
+	settings := NewSettingService(newFakeSettingRepo(), fakeLogService{}, nil, "")
+	service := NewChatWebhookService(repo, settings, fakeLogService{}, encryptionKey).(*chatWebhookService)
+	service.client = server.Client()
+	return service, repo, server, &posts
+}
+
+func TestChatWebhookURLStoredEncrypted(t *testing.T) {
+	service, repo, server, posts := newChatWebhookFixture(t, "test-key")
+	hookURL := server.URL + "/services/T000/B000/XXXX"
+
+	webhook := &domain.ChatWebhook{Name: "Ops", Platform: domain.ChatPlatformSlack, URL: hookURL, IsActive: true}
+	if err := service.CreateWebhook(webhook, 1); err != nil {
+		t.Fatal(err)
+	}
+	if webhook.URL != hookURL {
+		t.Fatalf("create returned URL %q, want the plain URL", webhook.URL)
+	}
+	if stored := repo.stored(webhook.ID); !isEncrypted(stored) || strings.Contains(stored, "XXXX") {
+		t.Fatalf("stored URL = %q, want it encrypted", stored)
+	}
+
+	listed, err := service.GetWebhook(webhook.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !listed.HasURL || listed.URLHost != strings.TrimPrefix(server.URL, "https://") {
+		t.Fatalf("has_url = %v, url_host = %q", listed.HasURL, listed.URLHost)
+	}
+
+	// แก้ไขโดยไม่ส่ง URL: ใช้ URL เดิม (ยังเข้ารหัสอยู่) และยังส่งข้อความได้
+	if err := service.UpdateWebhook(webhook.ID, &domain.ChatWebhook{Name: "Ops room", Platform: domain.ChatPlatformSlack, IsActive: true}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if stored := repo.stored(webhook.ID); !isEncrypted(stored) {
+		t.Fatalf("stored URL after update = %q, want it encrypted", stored)
+	}
+	if err := service.Test(webhook.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if *posts != 1 {
+		t.Fatalf("posts = %d, want 1", *posts)
+	}
+}
+
+func TestChatWebhookURLRequiresEncryptionKey(t *testing.T) {
+	service, repo, server, _ := newChatWebhookFixture(t, "")
+
+	err := service.CreateWebhook(&domain.ChatWebhook{Name: "Ops", Platform: domain.ChatPlatformSlack, URL: server.URL + "/services/T000/B000/XXXX"}, 1)
+	if !errors.Is(err, ports.ErrChatWebhookEncryptionKeyMissing) {
+		t.Fatalf("err = %v, want ErrChatWebhookEncryptionKeyMissing", err)
+	}
+	if webhooks, _ := repo.GetAll(); len(webhooks) != 0 {
+		t.Fatalf("stored %d webhooks without an encryption key", len(webhooks))
+	}
+}
+
+func TestSealStoredChatWebhookURLs(t *testing.T) {
+	service, repo, server, posts := newChatWebhookFixture(t, "test-key")
+	_ = repo.Create(&domain.ChatWebhook{Name: "Legacy", Platform: domain.ChatPlatformSlack, URL: server.URL + "/services/T000/B000/XXXX", IsActive: true})
+
+	// ค่าเก่าที่ยังไม่เข้ารหัสยังส่งได้
+	if err := service.Test(1, 1); err != nil {
+		t.Fatalf("legacy URL: %v", err)
+	}
+
+	if err := service.SealStoredURLs(); err != nil {
+		t.Fatal(err)
+	}
+	if stored := repo.stored(1); !isEncrypted(stored) {
+		t.Fatalf("stored URL = %q, want it encrypted", stored)
+	}
+	if err := service.Test(1, 1); err != nil {
+		t.Fatalf("sealed URL: %v", err)
+	}
+	if *posts != 2 {
+		t.Fatalf("posts = %d, want 2", *posts)
+	}
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+var bundleRoomColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type configBundleService struct {
+	repo         ports.ConfigBundleRepository
+	settings     ports.SettingService
+	roomRepo     ports.RoomRepository
+	resourceRepo ports.ResourceRepository
+	logService   ports.LogService
+	events       ports.EventPublisher
+}
+
+func NewConfigBundleService(repo ports.ConfigBundleRepository, settings ports.SettingService, roomRepo ports.RoomRepository, resourceRepo ports.ResourceRepository, logService ports.LogService, events ports.EventPublisher) ports.ConfigBundleService {
+	return &configBundleService{
+		repo:         repo,
+		settings:     settings,
+		roomRepo:     roomRepo,
+		resourceRepo: resourceRepo,
+		logService:   logService,
+		events:       events,
+	}
+}
+
+func (s *configBundleService) Export(includeRooms, includeResources bool, actorID uint) (*domain.ConfigBundle, error) {
+	settings, err := s.settings.GetAllSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &domain.ConfigBundle{
+		Version:    domain.ConfigBundleVersion,
+		ExportedAt: time.Now(),
+		Source:     s.settings.GetSettingValue("site_name"),
+		Settings:   make(map[string]string, len(settings)),
+	}
+	for _, setting := range settings {
+		if setting.Visibility != domain.SettingVisibilitySecret {
+			bundle.Settings[setting.SettingName] = setting.SettingValue
+		}
+	}
+
+	if includeRooms {
+		rooms, err := s.roomRepo.GetAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, room := range rooms {
+			bundle.Rooms = append(bundle.Rooms, bundleRoom(room))
+		}
+	}
+	if includeResources {
+		resources, err := s.resourceRepo.GetAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range resources {
+			bundle.Resources = append(bundle.Resources, domain.BundleResource{ResourceName: resource.ResourceName, Type: resource.Type})
+		}
+	}
+
+	go s.logService.LogAction(actorID, "EXPORT_SETTINGS", fmt.Sprintf("Exported config bundle: %d settings, %d rooms, %d resources", len(bundle.Settings), len(bundle.Rooms), len(bundle.Resources)), "", "")
+	return bundle, nil
+}
+
+// Import: ตรวจทุกอย่างก่อน ผ่านทั้งหมดจึงบันทึกใน Transaction เดียว (ไม่มีการบันทึกบางส่วน)
+func (s *configBundleService) Import(bundle *domain.ConfigBundle, dryRun bool, actorID uint) (*domain.ConfigBundleDiff, error) {
+	if bundle.Version < 1 || bundle.Version > domain.ConfigBundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d (this server reads version %d)", bundle.Version, domain.ConfigBundleVersion)
+	}
+
+	diff := &domain.ConfigBundleDiff{
+		DryRun:    dryRun,
+		Settings:  []domain.ConfigBundleChange{},
+		Rooms:     []domain.ConfigBundleChange{},
+		Resources: []domain.ConfigBundleChange{},
+	}
+	errs := map[string]string{}
+
+	settings, err := s.diffSettings(bundle, diff, errs)
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := s.diffRooms(bundle, diff, errs)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := s.diffResources(bundle, diff, errs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(errs) > 0 {
+		return diff, &ports.SettingValidationError{Fields: errs}
+	}
+	if dryRun {
+		return diff, nil
+	}
+
+	created := make([]bool, len(rooms))
+	for i := range rooms {
+		created[i] = rooms[i].ID == 0
+	}
+	changeSet := &domain.SettingChangeSet{ActorID: actorID, Source: domain.SettingChangeSourceImport}
+	if err := s.repo.Apply(settings, changeSet, rooms, resources); err != nil {
+		return nil, err
+	}
+
+	if len(changeSet.Changes) > 0 {
+		diff.ChangeSetID = &changeSet.ID
+		keys := make([]string, len(changeSet.Changes))
+		for i, change := range changeSet.Changes {
+			keys[i] = change.SettingName
+		}
+		s.settings.Refresh(keys)
+	}
+	for i, room := range rooms {
+		if created[i] {
+			go s.events.Publish(domain.WebhookEventRoomCreated, room)
+		} else {
+			go s.events.Publish(domain.WebhookEventRoomUpdated, room)
+		}
+	}
+
+	description := fmt.Sprintf("Imported config bundle: %d settings, %d rooms, %d resources", len(diff.Settings), len(diff.Rooms), len(diff.Resources))
+	if bundle.Source != "" {
+		description += fmt.Sprintf(" (from %s)", bundle.Source)
+	}
+	go s.logService.LogAction(actorID, "IMPORT_SETTINGS", description, "", "")
+	return diff, nil
+}
+
+// diffSettings ค่าที่ต่างจากปัจจุบัน (ไม่รับ secret และชื่อที่ไม่รู้จัก)
+func (s *configBundleService) diffSettings(bundle *domain.ConfigBundle, diff *domain.ConfigBundleDiff, errs map[string]string) ([]domain.Setting, error) {
+	current, err := s.settings.GetAllSettings()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]domain.Setting, len(current))
+	for _, setting := range current {
+		byName[setting.SettingName] = setting
+	}
+
+	names := make([]string, 0, len(bundle.Settings))
+	for name := range bundle.Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var updates []domain.Setting
+	for _, name := range names {
+		value := bundle.Settings[name]
+		existing, ok := byName[name]
+		if !ok {
+			errs["settings."+name] = "unknown setting"
+			continue
+		}
+		if existing.Visibility == domain.SettingVisibilitySecret {
+			errs["settings."+name] = "secret settings cannot be imported, set them on this instance"
+			continue
+		}
+		if existing.SettingValue == value {
+			continue
+		}
+		updates = append(updates, domain.Setting{SettingName: name, SettingValue: value})
+		diff.Settings = append(diff.Settings, domain.ConfigBundleChange{Name: name, Action: domain.BundleActionUpdate, Old: existing.SettingValue, New: value})
+	}
+
+	var invalid *ports.SettingValidationError
+	if err := validateSettings(updates); errors.As(err, &invalid) {
+		for name, msg := range invalid.Fields {
+			errs["settings."+name] = msg
+		}
+	}
+	return updates, nil
+}
+
+// diffRooms จับคู่ห้องด้วยชื่อ (ไม่สนตัวพิมพ์เล็ก/ใหญ่) ห้องที่ไม่อยู่ใน Bundle ไม่ถูกแตะต้อง
+func (s *configBundleService) diffRooms(bundle *domain.ConfigBundle, diff *domain.ConfigBundleDiff, errs map[string]string) ([]domain.Room, error) {
+	if len(bundle.Rooms) == 0 {
+		return nil, nil
+	}
+	existingRooms, err := s.roomRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]domain.Room, len(existingRooms))
+	for _, room := range existingRooms {
+		key := strings.ToLower(strings.TrimSpace(room.RoomName))
+		if _, ok := byName[key]; !ok {
+			byName[key] = room
+		}
+	}
+
+	seen := map[string]bool{}
+	var rooms []domain.Room
+	for i, input := range bundle.Rooms {
+		field := fmt.Sprintf("rooms.%d", i)
+		input.RoomName = strings.TrimSpace(input.RoomName)
+		if input.Status == "" {
+			input.Status = "active"
+		}
+		if !validateBundleRoom(field, input, errs) {
+			continue
+		}
+		key := strings.ToLower(input.RoomName)
+		if seen[key] {
+			errs[field+".room_name"] = "duplicate room name in bundle"
+			continue
+		}
+		seen[key] = true
+
+		room, ok := byName[key]
+		if !ok {
+			rooms = append(rooms, domain.Room{RoomName: input.RoomName, Description: input.Description, Capacity: input.Capacity, ImagePath: input.ImagePath, Color: input.Color, Status: input.Status})
+			diff.Rooms = append(diff.Rooms, domain.ConfigBundleChange{Name: input.RoomName, Action: domain.BundleActionCreate, New: input})
+			continue
+		}
+		old := bundleRoom(room)
+		input.RoomName = room.RoomName // คงชื่อเดิมของปลายทาง
+		if old == input {
+			continue
+		}
+		room.Description = input.Description
+		room.Capacity = input.Capacity
+		room.ImagePath = input.ImagePath
+		room.Color = input.Color
+		room.Status = input.Status
+		rooms = append(rooms, room)
+		diff.Rooms = append(diff.Rooms, domain.ConfigBundleChange{Name: room.RoomName, Action: domain.BundleActionUpdate, Old: old, New: input})
+	}
+	return rooms, nil
+}
+
+func (s *configBundleService) diffResources(bundle *domain.ConfigBundle, diff *domain.ConfigBundleDiff, errs map[string]string) ([]domain.Resource, error) {
+	if len(bundle.Resources) == 0 {
+		return nil, nil
+	}
+	existingResources, err := s.resourceRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]domain.Resource, len(existingResources))
+	for _, resource := range existingResources {
+		key := strings.ToLower(strings.TrimSpace(resource.ResourceName))
+		if _, ok := byName[key]; !ok {
+			byName[key] = resource
+		}
+	}
+
+	seen := map[string]bool{}
+	var resources []domain.Resource
+	for i, input := range bundle.Resources {
+		field := fmt.Sprintf("resources.%d", i)
+		input.ResourceName = strings.TrimSpace(input.ResourceName)
+		if input.ResourceName == "" {
+			errs[field+".resource_name"] = "is required"
+			continue
+		}
+		key := strings.ToLower(input.ResourceName)
+		if seen[key] {
+			errs[field+".resource_name"] = "duplicate resource name in bundle"
+			continue
+		}
+		seen[key] = true
+
+		resource, ok := byName[key]
+		if !ok {
+			resources = append(resources, domain.Resource{ResourceName: input.ResourceName, Type: input.Type})
+			diff.Resources = append(diff.Resources, domain.ConfigBundleChange{Name: input.ResourceName, Action: domain.BundleActionCreate, New: input})
+			continue
+		}
+		if resource.Type == input.Type {
+			continue
+		}
+		old := domain.BundleResource{ResourceName: resource.ResourceName, Type: resource.Type}
+		resource.Type = input.Type
+		resources = append(resources, resource)
+		diff.Resources = append(diff.Resources, domain.ConfigBundleChange{Name: resource.ResourceName, Action: domain.BundleActionUpdate, Old: old, New: domain.BundleResource{ResourceName: resource.ResourceName, Type: input.Type}})
+	}
+	return resources, nil
+}
+
+func validateBundleRoom(field string, room domain.BundleRoom, errs map[string]string) bool {
+	valid := true
+	if room.RoomName == "" {
+		errs[field+".room_name"] = "is required"
+		valid = false
+	}
+	if room.Capacity < 0 {
+		errs[field+".capacity"] = "must not be negative"
+		valid = false
+	}
+	if room.Color != "" && !bundleRoomColorPattern.MatchString(room.Color) {
+		errs[field+".color"] = "hex color, e.g. #db2777"
+		valid = false
+	}
+	if room.Status != "active" && room.Status != "maintenance" {
+		errs[field+".status"] = "must be one of: active, maintenance"
+		valid = false
+	}
+	return valid
+}
+
+func bundleRoom(room domain.Room) domain.BundleRoom {
+	return domain.BundleRoom{
+		RoomName:    room.RoomName,
+		Description: room.Description,
+		Capacity:    room.Capacity,
+		ImagePath:   room.ImagePath,
+		Color:       room.Color,
+		Status:      room.Status,
+	}
+}
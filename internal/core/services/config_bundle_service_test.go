@@ -0,0 +1,183 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+// fakeConfigBundleRepo บันทึกค่าตั้งค่าผ่าน fakeSettingRepo และเก็บห้อง/อุปกรณ์ที่ได้รับ
+type fakeConfigBundleRepo struct {
+	settings  *fakeSettingRepo
+	applied   int
+	rooms     []domain.Room
+	resources []domain.Resource
+}
+
+func (r *fakeConfigBundleRepo) Apply(settings []domain.Setting, changeSet *domain.SettingChangeSet, rooms []domain.Room, resources []domain.Resource) error {
+	r.applied++
+	r.rooms = append(r.rooms, rooms...)
+	r.resources = append(r.resources, resources...)
+	return r.settings.UpdateBatch(settings, changeSet)
+}
+
+type fakeRoomRepo struct {
+	ports.RoomRepository
+	rooms []domain.Room
+}
+
+func (r *fakeRoomRepo) GetAll() ([]domain.Room, error) { return r.rooms, nil }
+
+type fakeResourceRepo struct {
+	ports.ResourceRepository
+	resources []domain.Resource
+}
+
+func (r *fakeResourceRepo) GetAll() ([]domain.Resource, error) { return r.resources, nil }
+
+type fakeEvents struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (e *fakeEvents) Publish(eventType string, data interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, eventType)
+}
+
+type bundleFixture struct {
+	service  ports.ConfigBundleService
+	settings *fakeSettingRepo
+	repo     *fakeConfigBundleRepo
+}
+
+func newBundleFixture() *bundleFixture {
+	settings := newFakeSettingRepo(
+		domain.Setting{SettingName: "site_name", SettingValue: "Booking"},
+		domain.Setting{SettingName: "theme_color", SettingValue: "#db2777"},
+		domain.Setting{SettingName: "smtp_port", SettingValue: "587"},
+		secretSetting("smtp_password", ""),
+	)
+	rooms := &fakeRoomRepo{rooms: []domain.Room{{ID: 1, RoomName: "Room A", Capacity: 10, Status: "active"}}}
+	resources := &fakeResourceRepo{resources: []domain.Resource{{ID: 1, ResourceName: "Projector", Type: "av"}}}
+	f := &bundleFixture{settings: settings, repo: &fakeConfigBundleRepo{settings: settings}}
+	f.service = NewConfigBundleService(f.repo, NewSettingService(settings, fakeLogService{}, nil, ""), rooms, resources, fakeLogService{}, &fakeEvents{})
+	return f
+}
+
+func bundle() *domain.ConfigBundle {
+	return &domain.ConfigBundle{
+		Version:  domain.ConfigBundleVersion,
+		Settings: map[string]string{"site_name": "Booking", "theme_color": "#2563eb"},
+		Rooms: []domain.BundleRoom{
+			{RoomName: "room a", Capacity: 20}, // ชื่อตรงกับ Room A (ไม่สนตัวพิมพ์)
+			{RoomName: "Room B", Capacity: 8},
+		},
+		Resources: []domain.BundleResource{{ResourceName: "Projector", Type: "av"}},
+	}
+}
+
+func TestImportDryRunDoesNotApply(t *testing.T) {
+	f := newBundleFixture()
+
+	diff, err := f.service.Import(bundle(), true, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.DryRun || f.repo.applied != 0 {
+		t.Fatalf("dry run applied the bundle (applied %d times)", f.repo.applied)
+	}
+	// site_name และ Projector ไม่เปลี่ยน จึงไม่อยู่ใน diff
+	if len(diff.Settings) != 1 || diff.Settings[0].Name != "theme_color" || diff.Settings[0].Old != "#db2777" {
+		t.Fatalf("settings diff = %+v", diff.Settings)
+	}
+	if len(diff.Rooms) != 2 || diff.Rooms[0].Action != domain.BundleActionUpdate || diff.Rooms[1].Action != domain.BundleActionCreate {
+		t.Fatalf("rooms diff = %+v", diff.Rooms)
+	}
+	if len(diff.Resources) != 0 {
+		t.Fatalf("resources diff = %+v", diff.Resources)
+	}
+	if got := f.settings.value("theme_color"); got != "#db2777" {
+		t.Fatalf("theme_color = %q after dry run", got)
+	}
+}
+
+func TestImportApplies(t *testing.T) {
+	f := newBundleFixture()
+
+	diff, err := f.service.Import(bundle(), false, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.repo.applied != 1 {
+		t.Fatalf("applied %d times, want 1", f.repo.applied)
+	}
+	if diff.ChangeSetID == nil {
+		t.Fatal("no change set recorded for the changed settings")
+	}
+	if got := f.settings.value("theme_color"); got != "#2563eb" {
+		t.Fatalf("theme_color = %q, want the imported value", got)
+	}
+	if len(f.repo.rooms) != 2 {
+		t.Fatalf("applied rooms = %+v", f.repo.rooms)
+	}
+	// ห้องเดิมแก้ไขด้วย ID เดิมและคงชื่อของปลายทาง ห้องใหม่ ID = 0
+	if r := f.repo.rooms[0]; r.ID != 1 || r.RoomName != "Room A" || r.Capacity != 20 {
+		t.Fatalf("updated room = %+v", r)
+	}
+	if r := f.repo.rooms[1]; r.ID != 0 || r.RoomName != "Room B" || r.Status != "active" {
+		t.Fatalf("created room = %+v", r)
+	}
+}
+
+func TestImportRejectsUnknownAndSecretSettings(t *testing.T) {
+	f := newBundleFixture()
+	b := bundle()
+	b.Settings["no_such_setting"] = "x"
+	b.Settings["smtp_password"] = "hunter2"
+	b.Settings["smtp_port"] = "70000"
+	b.Rooms = append(b.Rooms, domain.BundleRoom{RoomName: "Room B"}, domain.BundleRoom{RoomName: "Room C", Color: "pink"})
+
+	_, err := f.service.Import(b, false, 1)
+	var invalid *ports.SettingValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("got %v, want *SettingValidationError", err)
+	}
+	for _, field := range []string{"settings.no_such_setting", "settings.smtp_password", "settings.smtp_port", "rooms.2.room_name", "rooms.3.color"} {
+		if _, ok := invalid.Fields[field]; !ok {
+			t.Errorf("no error for %s in %v", field, invalid.Fields)
+		}
+	}
+	// ไม่มีการบันทึกบางส่วน
+	if f.repo.applied != 0 || f.settings.value("theme_color") != "#db2777" || f.settings.value("smtp_password") != "" {
+		t.Fatal("an invalid bundle was partly applied")
+	}
+}
+
+func TestImportRejectsUnsupportedVersion(t *testing.T) {
+	f := newBundleFixture()
+	b := bundle()
+	b.Version = domain.ConfigBundleVersion + 1
+	if _, err := f.service.Import(b, true, 1); err == nil {
+		t.Fatal("imported a bundle from a newer version")
+	}
+}
+
+func TestExportLeavesOutSecrets(t *testing.T) {
+	f := newBundleFixture()
+	f.settings.settings["smtp_password"] = secretSetting("smtp_password", "enc:whatever")
+
+	exported, err := f.service.Export(true, false, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := exported.Settings["smtp_password"]; ok {
+		t.Fatal("export contains a secret setting")
+	}
+	if exported.Settings["theme_color"] != "#db2777" || len(exported.Rooms) != 1 || exported.Resources != nil {
+		t.Fatalf("export = %+v", exported)
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+const digestPollInterval = time.Minute
+
+type digestService struct {
+	userRepo   ports.UserRepository
+	outboxRepo ports.OutboxRepository
+	settings   ports.SettingService
+}
+
+func NewDigestService(userRepo ports.UserRepository, outboxRepo ports.OutboxRepository, settings ports.SettingService) ports.DigestService {
+	return &digestService{
+		userRepo:   userRepo,
+		outboxRepo: outboxRepo,
+		settings:   settings,
+	}
+}
+
+func (s *digestService) Start(ctx context.Context) {
+	ticker := time.NewTicker(digestPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ScheduleDue(time.Now()); err != nil {
+			log.Println("Digest scheduler error:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ScheduleDue: เมื่อถึงเวลา digest_time ของวัน ใส่สรุปประจำวันของผู้อนุมัติแต่ละคนลง Outbox
+// DedupKey ผูกกับวันที่ ทำให้วันหนึ่งส่งได้ครั้งเดียวแม้ Scheduler จะรันทุกนาทีหรือหลาย instance
+// เนื้อหาจะสร้างตอนส่งจริง (ถ้าไม่มีอะไรต้องสรุปจะไม่ส่ง)
+func (s *digestService) ScheduleDue(now time.Time) (int, error) {
+	if s.settings.GetSettingValue("digest_enabled") != "true" {
+		return 0, nil
+	}
+
+	at, err := parseClock(s.settings.GetSettingValue("digest_time"))
+	if err != nil {
+		return 0, nil
+	}
+	now = now.In(systemLocation)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if now.Before(midnight.Add(at)) {
+		return 0, nil
+	}
+
+	approvers, err := s.userRepo.GetByRoles("admin", "approver")
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	date := now.Format("2006-01-02")
+	for _, u := range approvers {
+		for _, msg := range newOutboxMessages(domain.EventDailyDigest, nil) {
+			key := fmt.Sprintf("digest:%s:%d:%s", date, u.ID, msg.Channel)
+			recipientID := u.ID
+			msg.RecipientID = &recipientID
+			msg.DedupKey = &key
+
+			ok, err := s.outboxRepo.CreateUnique(&msg)
+			if err != nil {
+				return created, err
+			}
+			if ok {
+				created++
+			}
+		}
+	}
+	return created, nil
+}
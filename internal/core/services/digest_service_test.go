@@ -0,0 +1,49 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+func TestScheduleDueUsesSchoolTimezone(t *testing.T) {
+	newService := func() (*digestService, *fakeOutboxRepo) {
+		settings := NewSettingService(newFakeSettingRepo(
+			domain.Setting{SettingName: "digest_enabled", SettingValue: "true"},
+			domain.Setting{SettingName: "digest_time", SettingValue: "07:30"},
+		), fakeLogService{}, nil, "")
+		outbox := &fakeOutboxRepo{}
+		users := newFakeUserRepo(&domain.User{Username: "approver", Role: domain.RoleApprover})
+		return NewDigestService(users, outbox, settings).(*digestService), outbox
+	}
+
+	tests := []struct {
+		name    string
+		now     time.Time // เวลาของเครื่อง (UTC เหมือนใน Container)
+		due     bool
+		dateKey string
+	}{
+		{"07:15 Bangkok", time.Date(2026, 10, 19, 0, 15, 0, 0, time.UTC), false, ""},
+		{"07:45 Bangkok", time.Date(2026, 10, 19, 0, 45, 0, 0, time.UTC), true, "2026-10-19"},
+		// 23:45 UTC = 06:45 ของวันถัดไปที่กรุงเทพ: ยังไม่ถึงเวลาส่งของวันที่ 20
+		{"06:45 next day Bangkok", time.Date(2026, 10, 19, 23, 45, 0, 0, time.UTC), false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, outbox := newService()
+			created, err := service.ScheduleDue(tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (created > 0) != tt.due {
+				t.Fatalf("created %d digest messages, want due = %v", created, tt.due)
+			}
+			for _, msg := range outbox.messages {
+				if !strings.Contains(*msg.DedupKey, tt.dateKey) {
+					t.Errorf("DedupKey %s is not for %s", *msg.DedupKey, tt.dateKey)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+const (
+	defaultEmailVerificationTTL = 48 * time.Hour
+	verificationEmailsPerHour   = 3 // จำนวนอีเมลสูงสุดต่อบัญชีต่อชั่วโมง
+)
+
+var errInvalidVerificationToken = errors.New("invalid or expired verification link")
+
+type emailVerificationService struct {
+	repo       ports.EmailVerificationRepository
+	userRepo   ports.UserRepository
+	notifier   ports.NotificationService
+	settings   ports.SettingService
+	logService ports.LogService
+}
+
+func NewEmailVerificationService(repo ports.EmailVerificationRepository, userRepo ports.UserRepository, notifier ports.NotificationService, settings ports.SettingService, logService ports.LogService) ports.EmailVerificationService {
+	return &emailVerificationService{
+		repo:       repo,
+		userRepo:   userRepo,
+		notifier:   notifier,
+		settings:   settings,
+		logService: logService,
+	}
+}
+
+// SendVerification: ความผิดพลาดจะถูกเขียนลง log ของเซิร์ฟเวอร์ (ผู้ใช้ขอส่งใหม่ได้)
+func (s *emailVerificationService) SendVerification(user *domain.User) {
+	if user.Status != domain.UserStatusPendingVerification || user.Email == "" {
+		return
+	}
+	s.send(user, user.Email, "")
+}
+
+func (s *emailVerificationService) SendEmailChange(user *domain.User) {
+	if user.PendingEmail == "" {
+		return
+	}
+	s.send(user, user.PendingEmail, user.PendingEmail)
+}
+
+// send: newEmail ว่าง = ลิงก์ยืนยันตอนสมัคร
+func (s *emailVerificationService) send(user *domain.User, to, newEmail string) {
+	now := time.Now()
+	count, err := s.repo.CountSince(user.ID, now.Add(-time.Hour))
+	if err != nil || count >= verificationEmailsPerHour {
+		return
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		log.Println("Email verification: failed to generate token:", err)
+		return
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	ttl := s.ttl()
+	if err := s.repo.Create(&domain.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		Email:     newEmail,
+		ExpiresAt: now.Add(ttl),
+	}); err != nil {
+		log.Println("Email verification: failed to store token:", err)
+		return
+	}
+
+	link := publicBaseURL(s.settings) + "/verify-email?token=" + url.QueryEscape(token)
+	subject, body := verificationEmail(user, link, ttl, s.settings.GetSettingValue("site_name"))
+	if newEmail != "" {
+		subject, body = emailChangeEmail(user, link, ttl, s.settings.GetSettingValue("site_name"))
+	}
+	if err := s.notifier.SendEmail(to, subject, body); err != nil {
+		log.Println("Email verification: failed to send email:", err)
+	}
+}
+
+func (s *emailVerificationService) Verify(token string, client ports.ClientInfo) (*domain.User, error) {
+	stored, err := s.repo.GetByHash(hashToken(token))
+	if err != nil || stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, errInvalidVerificationToken
+	}
+
+	user, err := s.userRepo.GetByID(stored.UserID)
+	if err != nil {
+		return nil, errInvalidVerificationToken
+	}
+	if stored.Email != "" {
+		return s.confirmEmailChange(stored, user, client)
+	}
+	if user.Status != domain.UserStatusPendingVerification {
+		return nil, errInvalidVerificationToken
+	}
+
+	now := time.Now()
+	ok, err := s.repo.MarkUsed(stored.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errInvalidVerificationToken
+	}
+
+	user.EmailVerifiedAt = &now
+	user.Status = domain.UserStatusActive
+	if s.settings.GetSettingValue("register_require_admin_approval") == "true" {
+		user.Status = domain.UserStatusPendingApproval
+	}
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	if err := s.repo.InvalidateUser(user.ID, now); err != nil {
+		return nil, err
+	}
+
+	go s.logService.LogClientAction(user.ID, "VERIFY_EMAIL", fmt.Sprintf("ยืนยันอีเมล %s (สถานะ: %s)", user.Email, user.Status), client)
+	return user, nil
+}
+
+// confirmEmailChange: ลิงก์ต้องเป็นของอีเมลที่รออยู่ล่าสุด (ขอเปลี่ยนใหม่แล้ว ลิงก์เก่าใช้ไม่ได้)
+func (s *emailVerificationService) confirmEmailChange(stored *domain.EmailVerificationToken, user *domain.User, client ports.ClientInfo) (*domain.User, error) {
+	if user.PendingEmail == "" || !strings.EqualFold(user.PendingEmail, stored.Email) {
+		return nil, errInvalidVerificationToken
+	}
+	// ระหว่างรอยืนยัน อีเมลนี้อาจถูกบัญชีอื่นใช้ไปแล้ว
+	if other, err := s.userRepo.GetByEmail(stored.Email); err == nil && other.ID != 0 && other.ID != user.ID {
+		return nil, errors.New("email already exists")
+	}
+
+	now := time.Now()
+	ok, err := s.repo.MarkUsed(stored.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errInvalidVerificationToken
+	}
+
+	oldEmail := user.Email
+	user.Email = user.PendingEmail
+	user.PendingEmail = ""
+	user.EmailVerifiedAt = &now
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	if err := s.repo.InvalidateUser(user.ID, now); err != nil {
+		return nil, err
+	}
+
+	go s.logService.LogClientAction(user.ID, "CHANGE_EMAIL", fmt.Sprintf("เปลี่ยนอีเมลจาก %s เป็น %s", oldEmail, user.Email), client)
+	return user, nil
+}
+
+func (s *emailVerificationService) Resend(email string, client ports.ClientInfo) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil || user.Status != domain.UserStatusPendingVerification {
+		return
+	}
+	s.SendVerification(user)
+}
+
+func (s *emailVerificationService) ttl() time.Duration {
+	hours, err := strconv.Atoi(s.settings.GetSettingValue("email_verification_ttl_hours"))
+	if err != nil || hours <= 0 {
+		return defaultEmailVerificationTTL
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// verificationEmail: เนื้อหาอีเมลตามภาษาของผู้ใช้
+func verificationEmail(user *domain.User, link string, ttl time.Duration, siteName string) (string, string) {
+	hours := int(ttl / time.Hour)
+	if user.Language == domain.LanguageEnglish {
+		return fmt.Sprintf("%s: confirm your email address", siteName),
+			fmt.Sprintf("Hello %s,\n\nThanks for registering. Open the link below within %d hours to confirm your email address:\n\n%s\n\nIf you did not register, you can ignore this email.", user.FullName, hours, link)
+	}
+	return fmt.Sprintf("%s: ยืนยันอีเมล", siteName),
+		fmt.Sprintf("เรียน %s\n\nขอบคุณที่สมัครใช้งาน กรุณาเปิดลิงก์ด้านล่างภายใน %d ชั่วโมง เพื่อยืนยันอีเมลของคุณ\n\n%s\n\nหากคุณไม่ได้เป็นผู้สมัคร สามารถเพิกเฉยต่ออีเมลฉบับนี้ได้", user.FullName, hours, link)
+}
+
+// emailChangeEmail: ส่งไปที่อีเมลใหม่ อีเมลเดิมยังใช้งานอยู่จนกว่าจะกดลิงก์
+func emailChangeEmail(user *domain.User, link string, ttl time.Duration, siteName string) (string, string) {
+	hours := int(ttl / time.Hour)
+	if user.Language == domain.LanguageEnglish {
+		return fmt.Sprintf("%s: confirm your new email address", siteName),
+			fmt.Sprintf("Hello %s,\n\nOpen the link below within %d hours to use this address for your account:\n\n%s\n\nIf you did not ask to change your email address, you can ignore this email and your account will keep its current address.", user.FullName, hours, link)
+	}
+	return fmt.Sprintf("%s: ยืนยันอีเมลใหม่", siteName),
+		fmt.Sprintf("เรียน %s\n\nกรุณาเปิดลิงก์ด้านล่างภายใน %d ชั่วโมง เพื่อใช้อีเมลนี้กับบัญชีของคุณ\n\n%s\n\nหากคุณไม่ได้ขอเปลี่ยนอีเมล สามารถเพิกเฉยต่ออีเมลฉบับนี้ได้ บัญชีจะยังใช้อีเมลเดิม", user.FullName, hours, link)
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+// ขนาด buffer ต่อผู้รับ ถ้าผู้รับอ่านไม่ทันจะถูกข้ามเหตุการณ์ (ปฏิทินควรโหลดใหม่เมื่อเชื่อมต่อใหม่)
+const eventStreamBuffer = 32
+
+type eventStreamService struct {
+	mu          sync.RWMutex
+	nextID      uint64
+	subscribers map[chan ports.StreamEvent]struct{}
+}
+
+func NewEventStreamService() ports.EventStreamService {
+	return &eventStreamService{subscribers: make(map[chan ports.StreamEvent]struct{})}
+}
+
+func (s *eventStreamService) Subscribe() (<-chan ports.StreamEvent, func()) {
+	ch := make(chan ports.StreamEvent, eventStreamBuffer)
+
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subscribers, ch)
+			s.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (s *eventStreamService) Publish(eventType string, data interface{}) {
+	s.mu.Lock()
+	s.nextID++
+	event := ports.StreamEvent{ID: s.nextID, Type: eventType, Data: data}
+	s.mu.Unlock()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("Event stream: subscriber is too slow, dropped event %d\n", event.ID)
+		}
+	}
+}
+
+// multiPublisher ส่งเหตุการณ์เดียวกันให้หลายปลายทาง (Webhook, Event Stream, ...)
+type multiPublisher []ports.EventPublisher
+
+func NewMultiPublisher(publishers ...ports.EventPublisher) ports.EventPublisher {
+	return multiPublisher(publishers)
+}
+
+func (m multiPublisher) Publish(eventType string, data interface{}) {
+	for _, p := range m {
+		p.Publish(eventType, data)
+	}
+}
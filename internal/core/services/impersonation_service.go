@@ -0,0 +1,91 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+const defaultImpersonationTTL = time.Hour
+
+type impersonationService struct {
+	tokens     ports.TokenService
+	sessions   ports.SessionRepository
+	userRepo   ports.UserRepository
+	settings   ports.SettingService
+	logService ports.LogService
+}
+
+func NewImpersonationService(tokens ports.TokenService, sessions ports.SessionRepository, userRepo ports.UserRepository, settings ports.SettingService, logService ports.LogService) ports.ImpersonationService {
+	return &impersonationService{tokens: tokens, sessions: sessions, userRepo: userRepo, settings: settings, logService: logService}
+}
+
+func (s *impersonationService) Start(targetID, actorID uint, reason string, client ports.ClientInfo) (*ports.TokenPair, *domain.User, error) {
+	if s.settings.GetSettingValue("impersonation_enabled") == "false" {
+		return nil, nil, errors.New("impersonation is disabled")
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, nil, errors.New("reason is required")
+	}
+	if targetID == actorID {
+		return nil, nil, errors.New("you cannot impersonate yourself")
+	}
+
+	target, err := s.userRepo.GetByID(targetID)
+	if err != nil {
+		return nil, nil, errors.New("user not found")
+	}
+	if !target.IsActive() {
+		return nil, nil, errors.New("only active accounts can be impersonated")
+	}
+	// ไม่ให้ใช้สวมสิทธิ์เพื่อหลบ Log ของผู้ดูแลคนอื่น
+	if domain.HasPermission(target.Role, domain.PermUsersManage) {
+		return nil, nil, errors.New("administrators cannot be impersonated")
+	}
+
+	tokens, session, err := s.tokens.IssueImpersonation(target, actorID, s.ttl(), client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	go s.logService.LogImpersonatedAction(target.ID, actorID, "IMPERSONATION_START", fmt.Sprintf("Started impersonating %s (session %s, until %s): %s", target.Username, session.ID, session.ExpiresAt.Format(time.RFC3339), reason), client.IP, client.UserAgent)
+	return tokens, target, nil
+}
+
+func (s *impersonationService) End(sessionID string, userID, impersonatorID uint, client ports.ClientInfo) error {
+	session, err := s.sessions.GetByID(sessionID)
+	if err != nil || session.ImpersonatorID == nil || *session.ImpersonatorID != impersonatorID || session.UserID != userID {
+		return errors.New("not an impersonation session")
+	}
+	if err := s.tokens.RevokeSession(sessionID); err != nil {
+		return err
+	}
+
+	go s.logService.LogImpersonatedAction(userID, impersonatorID, "IMPERSONATION_END", fmt.Sprintf("Ended impersonation session %s", sessionID), client.IP, client.UserAgent)
+	return nil
+}
+
+func (s *impersonationService) GetImpersonator(sessionID string, impersonatorID uint) (*domain.Impersonator, error) {
+	session, err := s.sessions.GetByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := s.userRepo.GetByID(impersonatorID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Impersonator{ID: admin.ID, Username: admin.Username, FullName: admin.FullName, ExpiresAt: session.ExpiresAt}, nil
+}
+
+func (s *impersonationService) ttl() time.Duration {
+	minutes, err := strconv.Atoi(s.settings.GetSettingValue("impersonation_ttl_minutes"))
+	if err != nil || minutes <= 0 {
+		return defaultImpersonationTTL
+	}
+	return time.Duration(minutes) * time.Minute
+}
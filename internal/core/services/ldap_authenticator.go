@@ -0,0 +1,252 @@
+package services
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	defaultLDAPUserFilter = "(&(objectClass=person)(|(sAMAccountName={username})(userPrincipalName={username})(mail={username})))"
+	defaultLDAPTimeout    = 10 * time.Second
+)
+
+type ldapAuthenticator struct {
+	settings ports.SettingService
+}
+
+// NewLDAPAuthenticator: อ่านการตั้งค่ากลุ่ม ldap ทุกครั้งที่ Login (แก้ค่าได้โดยไม่ต้อง restart)
+func NewLDAPAuthenticator(settings ports.SettingService) ports.DirectoryAuthenticator {
+	return &ldapAuthenticator{settings: settings}
+}
+
+type ldapConfig struct {
+	URL             string
+	StartTLS        bool
+	InsecureSkipTLS bool
+	CACert          string
+	BindDN          string
+	BindPassword    string
+	BaseDN          string
+	UserFilter      string
+	AttrUsername    string
+	AttrFullName    string
+	AttrDepartment  string
+	AttrEmail       string
+	AttrGroups      string
+	AdminGroups     []string
+	ApproverGroups  []string
+	AllowedGroups   []string
+	Timeout         time.Duration
+}
+
+func (a *ldapAuthenticator) Enabled() bool {
+	return a.settings.GetSettingValue("ldap_enabled") == "true" && a.settings.GetSettingValue("ldap_url") != ""
+}
+
+func (a *ldapAuthenticator) config() ldapConfig {
+	get := func(key, fallback string) string {
+		if v := strings.TrimSpace(a.settings.GetSettingValue(key)); v != "" {
+			return v
+		}
+		return fallback
+	}
+
+	timeout := defaultLDAPTimeout
+	if seconds, err := strconv.Atoi(a.settings.GetSettingValue("ldap_timeout_seconds")); err == nil && seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	return ldapConfig{
+		URL:             get("ldap_url", ""),
+		StartTLS:        a.settings.GetSettingValue("ldap_start_tls") == "true",
+		InsecureSkipTLS: a.settings.GetSettingValue("ldap_insecure_skip_verify") == "true",
+		CACert:          a.settings.GetSettingValue("ldap_ca_cert"),
+		BindDN:          get("ldap_bind_dn", ""),
+		BindPassword:    a.settings.GetSettingValue("ldap_bind_password"),
+		BaseDN:          get("ldap_base_dn", ""),
+		UserFilter:      get("ldap_user_filter", defaultLDAPUserFilter),
+		AttrUsername:    get("ldap_attr_username", "sAMAccountName"),
+		AttrFullName:    get("ldap_attr_full_name", "displayName"),
+		AttrDepartment:  get("ldap_attr_department", "department"),
+		AttrEmail:       get("ldap_attr_email", "mail"),
+		AttrGroups:      get("ldap_attr_groups", "memberOf"),
+		AdminGroups:     splitGroups(a.settings.GetSettingValue("ldap_admin_groups")),
+		ApproverGroups:  splitGroups(a.settings.GetSettingValue("ldap_approver_groups")),
+		AllowedGroups:   splitGroups(a.settings.GetSettingValue("ldap_allowed_groups")),
+		Timeout:         timeout,
+	}
+}
+
+// Authenticate: bind ด้วยบัญชีบริการ -> ค้นหาผู้ใช้ -> bind ด้วยรหัสผ่านของผู้ใช้ -> จับคู่กลุ่มเป็น role
+func (a *ldapAuthenticator) Authenticate(username, password string) (*ports.DirectoryUser, error) {
+	// รหัสผ่านว่าง = unauthenticated bind ซึ่งเซิร์ฟเวอร์หลายตัวตอบว่าสำเร็จ
+	username = strings.TrimSpace(username)
+	if username == "" || password == "" {
+		return nil, ports.ErrDirectoryInvalidCredentials
+	}
+
+	cfg := a.config()
+	if cfg.BaseDN == "" {
+		return nil, errors.New("ldap_base_dn is not configured")
+	}
+
+	conn, err := dialLDAP(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if cfg.BindDN != "" {
+		if err := conn.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+			return nil, fmt.Errorf("ldap service bind failed: %w", err)
+		}
+	}
+
+	filter := strings.ReplaceAll(cfg.UserFilter, "{username}", ldap.EscapeFilter(username))
+	attributes := []string{cfg.AttrUsername, cfg.AttrFullName, "cn", cfg.AttrDepartment, cfg.AttrEmail, cfg.AttrGroups}
+	result, err := conn.Search(ldap.NewSearchRequest(
+		cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(cfg.Timeout/time.Second), false,
+		filter, attributes, nil,
+	))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+			return nil, errors.New("ldap user filter matched more than one entry")
+		}
+		return nil, fmt.Errorf("ldap search failed: %w", err)
+	}
+	if len(result.Entries) == 0 {
+		return nil, ports.ErrDirectoryInvalidCredentials
+	}
+	if len(result.Entries) > 1 {
+		return nil, errors.New("ldap user filter matched more than one entry")
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ports.ErrDirectoryInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldap user bind failed: %w", err)
+	}
+
+	user := &ports.DirectoryUser{
+		DN:         entry.DN,
+		Username:   entry.GetAttributeValue(cfg.AttrUsername),
+		FullName:   entry.GetAttributeValue(cfg.AttrFullName),
+		Department: entry.GetAttributeValue(cfg.AttrDepartment),
+		Email:      entry.GetAttributeValue(cfg.AttrEmail),
+		Groups:     entry.GetAttributeValues(cfg.AttrGroups),
+	}
+	if user.Username == "" {
+		user.Username = username
+	}
+	if user.FullName == "" {
+		user.FullName = entry.GetAttributeValue("cn")
+	}
+	if user.FullName == "" {
+		user.FullName = user.Username
+	}
+
+	if len(cfg.AllowedGroups) > 0 && !memberOfAny(user.Groups, cfg.AllowedGroups) &&
+		!memberOfAny(user.Groups, cfg.AdminGroups) && !memberOfAny(user.Groups, cfg.ApproverGroups) {
+		return nil, ports.ErrDirectoryAccessDenied
+	}
+
+	switch {
+	case memberOfAny(user.Groups, cfg.AdminGroups):
+		user.Role = domain.RoleAdmin
+	case memberOfAny(user.Groups, cfg.ApproverGroups):
+		user.Role = domain.RoleApprover
+	default:
+		user.Role = domain.RoleUser
+	}
+
+	return user, nil
+}
+
+// dialLDAP: ldaps:// ใช้ TLS ตั้งแต่ต้น, ldap:// + ldap_start_tls อัปเกรดด้วย StartTLS
+func dialLDAP(cfg ldapConfig) (*ldap.Conn, error) {
+	parsed, err := url.Parse(cfg.URL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid ldap_url: %s", cfg.URL)
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         parsed.Hostname(),
+		InsecureSkipVerify: cfg.InsecureSkipTLS,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if strings.TrimSpace(cfg.CACert) != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cfg.CACert)) {
+			return nil, errors.New("ldap_ca_cert is not a valid PEM certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	conn, err := ldap.DialURL(cfg.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: cfg.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ldap connection failed: %w", err)
+	}
+	conn.SetTimeout(cfg.Timeout)
+
+	if cfg.StartTLS && parsed.Scheme == "ldap" {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap StartTLS failed: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// splitGroups: DN มีจุลภาคอยู่แล้ว จึงคั่นหลายกลุ่มด้วย ; หรือขึ้นบรรทัดใหม่
+func splitGroups(value string) []string {
+	var groups []string
+	for _, g := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == '\n' }) {
+		if g = strings.TrimSpace(g); g != "" {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// memberOfAny: เทียบได้ทั้ง DN เต็ม หรือเฉพาะชื่อ CN (ไม่สนตัวพิมพ์เล็ก/ใหญ่)
+func memberOfAny(memberOf, groups []string) bool {
+	for _, m := range memberOf {
+		cn := groupCN(m)
+		for _, g := range groups {
+			if strings.EqualFold(m, g) || (cn != "" && strings.EqualFold(cn, g)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func groupCN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 {
+		return ""
+	}
+	for _, attr := range parsed.RDNs[0].Attributes {
+		if strings.EqualFold(attr.Type, "cn") {
+			return attr.Value
+		}
+	}
+	return ""
+}
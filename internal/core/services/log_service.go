@@ -24,6 +24,25 @@ func (s *logService) LogAction(userID uint, action, description, ip, userAgent s
 	return s.repo.Create(log)
 }
 
+func (s *logService) LogImpersonatedAction(userID, impersonatorID uint, action, description, ip, userAgent string) error {
+	return s.repo.Create(&domain.Log{
+		UserID:         userID,
+		ImpersonatorID: &impersonatorID,
+		Action:         action,
+		Description:    description,
+		IPAddress:      ip,
+		UserAgent:      userAgent,
+	})
+}
+
+// LogClientAction: รายการที่ทำด้วย Token สวมสิทธิ์ต้องบันทึกผู้ดูแลไว้ด้วย ไม่งั้นดูเหมือนผู้ใช้ทำเอง
+func (s *logService) LogClientAction(userID uint, action, description string, client ports.ClientInfo) error {
+	if client.ImpersonatorID != 0 {
+		return s.LogImpersonatedAction(userID, client.ImpersonatorID, action, description, client.IP, client.UserAgent)
+	}
+	return s.LogAction(userID, action, description, client.IP, client.UserAgent)
+}
+
 func (s *logService) GetLogs(limit int) ([]domain.Log, error) {
 	// Default limit if not provided
 	if limit <= 0 {
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+type fakeLogRepo struct {
+	logs []domain.Log
+}
+
+func (r *fakeLogRepo) Create(log *domain.Log) error {
+	r.logs = append(r.logs, *log)
+	return nil
+}
+
+func (r *fakeLogRepo) GetAll(limit int) ([]domain.Log, error) { return r.logs, nil }
+
+func TestLogClientActionRecordsImpersonator(t *testing.T) {
+	repo := &fakeLogRepo{}
+	service := NewLogService(repo)
+
+	if err := service.LogClientAction(5, "CREATE_BOOKING", "own token", ports.ClientInfo{IP: "10.0.0.1", UserAgent: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.LogClientAction(5, "CREATE_BOOKING", "impersonation token", ports.ClientInfo{IP: "10.0.0.2", UserAgent: "test", ImpersonatorID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	own, impersonated := repo.logs[0], repo.logs[1]
+	if own.UserID != 5 || own.ImpersonatorID != nil || own.IPAddress != "10.0.0.1" {
+		t.Errorf("own token log = %+v", own)
+	}
+	if impersonated.UserID != 5 || impersonated.ImpersonatorID == nil || *impersonated.ImpersonatorID != 1 || impersonated.IPAddress != "10.0.0.2" {
+		t.Errorf("impersonation log = %+v", impersonated)
+	}
+}
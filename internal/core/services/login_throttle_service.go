@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+const (
+	loginDelayAfterAccount = 3  // เริ่มหน่วงเวลาบัญชีหลังล้มเหลวครบจำนวนนี้
+	loginDelayAfterIP      = 10 // IP เดียวกันอาจมีหลายคน (NAT) จึงเริ่มหน่วงช้ากว่า
+	maxLoginDelay          = time.Minute
+)
+
+type loginThrottleService struct {
+	repo       ports.LoginThrottleRepository
+	settings   ports.SettingService
+	logService ports.LogService
+}
+
+func NewLoginThrottleService(repo ports.LoginThrottleRepository, settings ports.SettingService, logService ports.LogService) ports.LoginThrottleService {
+	return &loginThrottleService{repo: repo, settings: settings, logService: logService}
+}
+
+// Check: ตรวจทั้งบัญชีและ IP คืนเวลารอที่นานที่สุด
+func (s *loginThrottleService) Check(accountKey, ip string) error {
+	now := time.Now()
+	var wait time.Duration
+	if accountKey != "" {
+		if d := s.waitFor(domain.ThrottleScopeAccount, normalizeAccountKey(accountKey), loginDelayAfterAccount, now); d > wait {
+			wait = d
+		}
+	}
+	if ip != "" {
+		if d := s.waitFor(domain.ThrottleScopeIP, ip, loginDelayAfterIP, now); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return &ports.LoginLockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+func (s *loginThrottleService) waitFor(scope, key string, delayAfter int, now time.Time) time.Duration {
+	entry, err := s.repo.Get(scope, key)
+	if err != nil {
+		return 0
+	}
+	if entry.LockedUntil != nil && now.Before(*entry.LockedUntil) {
+		return entry.LockedUntil.Sub(now)
+	}
+	if entry.LastFailedAt.Before(now.Add(-s.window())) {
+		return 0
+	}
+	if until := entry.LastFailedAt.Add(progressiveDelay(entry.Failures, delayAfter)); now.Before(until) {
+		return until.Sub(now)
+	}
+	return 0
+}
+
+// RecordFailure: บันทึกทุกครั้งที่รหัสผ่าน (หรือรหัส 2FA) ไม่ถูกต้อง และล็อกเมื่อครบเกณฑ์
+func (s *loginThrottleService) RecordFailure(accountKey string, userID uint, reason string, client ports.ClientInfo) {
+	accountKey = normalizeAccountKey(accountKey)
+	go s.logService.LogClientAction(userID, "LOGIN_FAILED", fmt.Sprintf("Failed login for %q: %s", accountKey, reason), client)
+
+	now := time.Now()
+	windowStart := now.Add(-s.window())
+	lockFor := time.Duration(s.intSetting("login_lockout_minutes", 15)) * time.Minute
+
+	var uid *uint
+	if userID != 0 {
+		uid = &userID
+	}
+
+	if accountKey != "" {
+		s.record(&domain.LoginThrottle{
+			Scope: domain.ThrottleScopeAccount, Key: accountKey, UserID: uid,
+			LastFailedAt: now, LastIP: client.IP, LastUserAgent: client.UserAgent,
+		}, windowStart, s.intSetting("login_lockout_threshold", 10), lockFor, userID, "ACCOUNT_LOCKED", client)
+	}
+	if client.IP != "" {
+		s.record(&domain.LoginThrottle{
+			Scope: domain.ThrottleScopeIP, Key: client.IP,
+			LastFailedAt: now, LastIP: client.IP, LastUserAgent: client.UserAgent,
+		}, windowStart, s.intSetting("login_ip_lockout_threshold", 50), lockFor, 0, "IP_LOCKED", client)
+	}
+}
+
+func (s *loginThrottleService) record(entry *domain.LoginThrottle, windowStart time.Time, threshold int, lockFor time.Duration, userID uint, action string, client ports.ClientInfo) {
+	updated, err := s.repo.RecordFailure(entry, windowStart)
+	if err != nil {
+		log.Println("Login throttle: failed to record attempt:", err)
+		return
+	}
+	// threshold 0 = ปิดการล็อก (ยังหน่วงเวลาตามปกติ)
+	if threshold <= 0 || updated.Failures < threshold || (updated.LockedUntil != nil && updated.LockedUntil.After(entry.LastFailedAt)) {
+		return
+	}
+
+	until := entry.LastFailedAt.Add(lockFor)
+	if err := s.repo.SetLockedUntil(updated.ID, until); err != nil {
+		log.Println("Login throttle: failed to lock:", err)
+		return
+	}
+	go s.logService.LogClientAction(userID, action, fmt.Sprintf("Locked %s %q after %d failed logins until %s", entry.Scope, entry.Key, updated.Failures, until.Format(time.RFC3339)), client)
+}
+
+// RecordSuccess: ล้างเฉพาะตัวนับของบัญชี (ตัวนับของ IP ไม่ถูกล้าง ไม่ให้ผู้โจมตีใช้บัญชีตัวเองรีเซ็ต)
+func (s *loginThrottleService) RecordSuccess(accountKey string) {
+	if err := s.repo.Reset(domain.ThrottleScopeAccount, normalizeAccountKey(accountKey)); err != nil {
+		log.Println("Login throttle: failed to reset account:", err)
+	}
+}
+
+func (s *loginThrottleService) GetLockouts() ([]domain.LoginThrottle, error) {
+	return s.repo.GetRecent(time.Now().Add(-s.window()))
+}
+
+func (s *loginThrottleService) Clear(id, actorID uint, client ports.ClientInfo) error {
+	entry, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("lockout not found")
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	go s.logService.LogClientAction(actorID, "LOGIN_LOCKOUT_CLEARED", fmt.Sprintf("Cleared login lockout of %s %q (%d failures)", entry.Scope, entry.Key, entry.Failures), client)
+	return nil
+}
+
+// window: ล้มเหลวห่างกันเกินช่วงนี้ เริ่มนับใหม่
+func (s *loginThrottleService) window() time.Duration {
+	return time.Duration(s.intSetting("login_failure_window_minutes", 15)) * time.Minute
+}
+
+func (s *loginThrottleService) intSetting(key string, fallback int) int {
+	v, err := strconv.Atoi(s.settings.GetSettingValue(key))
+	if err != nil || v < 0 {
+		return fallback
+	}
+	return v
+}
+
+// progressiveDelay: 1, 2, 4, 8 ... วินาที (สูงสุด 1 นาที) นับจากครั้งที่ล้มเหลวล่าสุด
+func progressiveDelay(failures, delayAfter int) time.Duration {
+	if failures < delayAfter {
+		return 0
+	}
+	shift := failures - delayAfter
+	if shift > 6 {
+		return maxLoginDelay
+	}
+	if d := time.Second << shift; d < maxLoginDelay {
+		return d
+	}
+	return maxLoginDelay
+}
+
+func normalizeAccountKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
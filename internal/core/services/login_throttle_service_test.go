@@ -0,0 +1,226 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+// fakeThrottleRepo ทำงานเหมือน loginThrottleRepository (upsert ต่อ scope + key)
+type fakeThrottleRepo struct {
+	mu      sync.Mutex
+	entries map[uint]*domain.LoginThrottle
+}
+
+func newFakeThrottleRepo() *fakeThrottleRepo {
+	return &fakeThrottleRepo{entries: map[uint]*domain.LoginThrottle{}}
+}
+
+func (r *fakeThrottleRepo) find(scope, key string) *domain.LoginThrottle {
+	for _, e := range r.entries {
+		if e.Scope == scope && e.Key == key {
+			return e
+		}
+	}
+	return nil
+}
+
+func (r *fakeThrottleRepo) Get(scope, key string) (*domain.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e := r.find(scope, key); e != nil {
+		copied := *e
+		return &copied, nil
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeThrottleRepo) RecordFailure(entry *domain.LoginThrottle, windowStart time.Time) (*domain.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.find(entry.Scope, entry.Key)
+	if e == nil {
+		e = &domain.LoginThrottle{ID: uint(len(r.entries) + 1), Scope: entry.Scope, Key: entry.Key}
+		r.entries[e.ID] = e
+	}
+	if e.LastFailedAt.Before(windowStart) {
+		e.Failures = 0
+	}
+	e.Failures++
+	e.LastFailedAt = entry.LastFailedAt
+	e.UserID = entry.UserID
+	copied := *e
+	return &copied, nil
+}
+
+func (r *fakeThrottleRepo) SetLockedUntil(id uint, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[id].LockedUntil = &until
+	return nil
+}
+
+func (r *fakeThrottleRepo) Reset(scope, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e := r.find(scope, key); e != nil {
+		delete(r.entries, e.ID)
+	}
+	return nil
+}
+
+func (r *fakeThrottleRepo) GetByID(id uint) (*domain.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.entries[id]; ok {
+		copied := *e
+		return &copied, nil
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeThrottleRepo) GetRecent(since time.Time) ([]domain.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var recent []domain.LoginThrottle
+	for _, e := range r.entries {
+		if e.LastFailedAt.After(since) || (e.LockedUntil != nil && e.LockedUntil.After(time.Now())) {
+			recent = append(recent, *e)
+		}
+	}
+	return recent, nil
+}
+
+func (r *fakeThrottleRepo) Delete(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, id)
+	return nil
+}
+
+func newThrottleService(threshold, ipThreshold string) (ports.LoginThrottleService, *fakeThrottleRepo) {
+	repo := newFakeThrottleRepo()
+	settings := NewSettingService(newFakeSettingRepo(
+		domain.Setting{SettingName: "login_lockout_threshold", SettingValue: threshold},
+		domain.Setting{SettingName: "login_ip_lockout_threshold", SettingValue: ipThreshold},
+		domain.Setting{SettingName: "login_lockout_minutes", SettingValue: "15"},
+		domain.Setting{SettingName: "login_failure_window_minutes", SettingValue: "15"},
+	), fakeLogService{}, nil, "")
+	return NewLoginThrottleService(repo, settings, fakeLogService{}), repo
+}
+
+func retryAfter(t *testing.T, err error) time.Duration {
+	t.Helper()
+	var locked *ports.LoginLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("got %v, want *ports.LoginLockedError", err)
+	}
+	return locked.RetryAfter
+}
+
+func TestProgressiveDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{6, 8 * time.Second},
+		{8, 32 * time.Second},
+		{9, time.Minute},
+		{100, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := progressiveDelay(tt.failures, loginDelayAfterAccount); got != tt.want {
+			t.Errorf("progressiveDelay(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestLoginThrottleDelaysThenLocksAccount(t *testing.T) {
+	service, _ := newThrottleService("5", "0")
+	client := ports.ClientInfo{IP: "10.0.0.1"}
+
+	for i := 0; i < loginDelayAfterAccount-1; i++ {
+		service.RecordFailure("somchai", 1, "invalid password", client)
+	}
+	if err := service.Check("somchai", ""); err != nil {
+		t.Fatalf("delayed before %d failures: %v", loginDelayAfterAccount, err)
+	}
+
+	service.RecordFailure("somchai", 1, "invalid password", client)
+	if wait := retryAfter(t, service.Check("somchai", "")); wait <= 0 || wait > time.Second {
+		t.Fatalf("after %d failures: wait %s, want up to 1s", loginDelayAfterAccount, wait)
+	}
+
+	// ครบเกณฑ์ล็อก (5): รอตาม login_lockout_minutes
+	service.RecordFailure("somchai", 1, "invalid password", client)
+	service.RecordFailure("somchai", 1, "invalid password", client)
+	if wait := retryAfter(t, service.Check("somchai", "")); wait < 14*time.Minute {
+		t.Fatalf("after lockout: wait %s, want about 15 minutes", wait)
+	}
+
+	// บัญชีอื่นไม่ได้รับผล และชื่อบัญชีไม่สนตัวพิมพ์ / ช่องว่าง
+	if err := service.Check("somsri", ""); err != nil {
+		t.Fatalf("another account is throttled: %v", err)
+	}
+	retryAfter(t, service.Check(" SomChai ", ""))
+}
+
+func TestLoginThrottleZeroThresholdNeverLocks(t *testing.T) {
+	service, repo := newThrottleService("0", "0")
+	for i := 0; i < 20; i++ {
+		service.RecordFailure("somchai", 1, "invalid password", ports.ClientInfo{})
+	}
+	entry, _ := repo.Get(domain.ThrottleScopeAccount, "somchai")
+	if entry.LockedUntil != nil {
+		t.Fatal("threshold 0 must not lock the account")
+	}
+	// ยังหน่วงเวลาตามปกติ
+	if wait := retryAfter(t, service.Check("somchai", "")); wait > maxLoginDelay {
+		t.Fatalf("wait %s exceeds the maximum delay", wait)
+	}
+}
+
+func TestLoginThrottleSuccessResetsAccountButNotIP(t *testing.T) {
+	service, _ := newThrottleService("0", "0")
+	client := ports.ClientInfo{IP: "10.0.0.1"}
+
+	// ผู้โจมตีไล่เดาหลายบัญชีจาก IP เดียว
+	for i := 0; i < loginDelayAfterIP; i++ {
+		service.RecordFailure("somchai", 1, "invalid password", client)
+	}
+	service.RecordSuccess("somchai")
+
+	if err := service.Check("somchai", ""); err != nil {
+		t.Fatalf("account still throttled after a successful login: %v", err)
+	}
+	retryAfter(t, service.Check("", "10.0.0.1"))
+	retryAfter(t, service.Check("somsri", "10.0.0.1"))
+}
+
+func TestLoginThrottleClear(t *testing.T) {
+	service, _ := newThrottleService("3", "0")
+	for i := 0; i < 3; i++ {
+		service.RecordFailure("somchai", 1, "invalid password", ports.ClientInfo{})
+	}
+
+	lockouts, err := service.GetLockouts()
+	if err != nil || len(lockouts) != 1 {
+		t.Fatalf("lockouts = %v, %v", lockouts, err)
+	}
+	if err := service.Clear(lockouts[0].ID, 99, ports.ClientInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Check("somchai", ""); err != nil {
+		t.Fatalf("still locked after Clear: %v", err)
+	}
+	if err := service.Clear(lockouts[0].ID, 99, ports.ClientInfo{}); err == nil {
+		t.Fatal("clearing a missing lockout must fail")
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+type notificationPreferenceService struct {
+	repo ports.NotificationPreferenceRepository
+}
+
+func NewNotificationPreferenceService(repo ports.NotificationPreferenceRepository) ports.NotificationPreferenceService {
+	return &notificationPreferenceService{repo: repo}
+}
+
+// GetPreferences: คืนค่าครบทุกเหตุการณ์ x ทุกช่องทาง (ที่ยังไม่ตั้งใช้ค่าเริ่มต้น)
+func (s *notificationPreferenceService) GetPreferences(userID uint) (*ports.NotificationPreferences, error) {
+	prefs, err := s.repo.GetByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ports.NotificationPreferences{
+		Events:     make(map[string]map[string]bool),
+		QuietHours: domain.NotificationQuietHours{Start: "22:00", End: "07:00"},
+	}
+	for _, event := range domain.NotificationEvents {
+		result.Events[event] = make(map[string]bool)
+		for _, channel := range domain.NotificationChannels {
+			result.Events[event][channel] = domain.DefaultChannelEnabled(channel)
+		}
+	}
+	for _, p := range prefs {
+		if channels, ok := result.Events[p.EventType]; ok {
+			channels[p.Channel] = p.Enabled
+		}
+	}
+
+	if quiet, err := s.repo.GetQuietHours(userID); err == nil {
+		result.QuietHours = *quiet
+	}
+
+	return result, nil
+}
+
+func (s *notificationPreferenceService) UpdatePreferences(userID uint, input *ports.NotificationPreferences) error {
+	var prefs []domain.NotificationPreference
+	for event, channels := range input.Events {
+		if !contains(domain.NotificationEvents, event) {
+			return fmt.Errorf("unknown event type: %s", event)
+		}
+		for channel, enabled := range channels {
+			if !contains(domain.NotificationChannels, channel) {
+				return fmt.Errorf("unknown channel: %s", channel)
+			}
+			prefs = append(prefs, domain.NotificationPreference{
+				UserID:    userID,
+				EventType: event,
+				Channel:   channel,
+				Enabled:   enabled,
+			})
+		}
+	}
+
+	quiet := input.QuietHours
+	if quiet.Enabled {
+		if _, err := parseClock(quiet.Start); err != nil {
+			return errors.New("quiet_hours.start must be in HH:MM format")
+		}
+		if _, err := parseClock(quiet.End); err != nil {
+			return errors.New("quiet_hours.end must be in HH:MM format")
+		}
+	}
+
+	return s.repo.Save(userID, prefs, &quiet)
+}
+
+func (s *notificationPreferenceService) IsEnabled(userID uint, eventType, channel string) bool {
+	prefs, err := s.repo.GetByUser(userID)
+	if err != nil {
+		return domain.DefaultChannelEnabled(channel)
+	}
+	for _, p := range prefs {
+		if p.EventType == eventType && p.Channel == channel {
+			return p.Enabled
+		}
+	}
+	return domain.DefaultChannelEnabled(channel)
+}
+
+// QuietUntil: รองรับช่วงข้ามคืน เช่น 22:00 - 07:00 (เวลาตาม systemLocation)
+func (s *notificationPreferenceService) QuietUntil(userID uint, now time.Time) (time.Time, bool) {
+	quiet, err := s.repo.GetQuietHours(userID)
+	if err != nil || !quiet.Enabled {
+		return time.Time{}, false
+	}
+	now = now.In(systemLocation)
+
+	start, err1 := parseClock(quiet.Start)
+	end, err2 := parseClock(quiet.End)
+	if err1 != nil || err2 != nil || start == end {
+		return time.Time{}, false
+	}
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	sinceMidnight := now.Sub(midnight)
+
+	if start < end {
+		if sinceMidnight >= start && sinceMidnight < end {
+			return midnight.Add(end), true
+		}
+		return time.Time{}, false
+	}
+
+	// ข้ามคืน
+	if sinceMidnight >= start {
+		return midnight.AddDate(0, 0, 1).Add(end), true
+	}
+	if sinceMidnight < end {
+		return midnight.Add(end), true
+	}
+	return time.Time{}, false
+}
+
+// systemLocation เขตเวลาที่ใช้ตีความเวลา "HH:MM" (digest_time / ช่วงห้ามรบกวน) และวันที่ "วันนี้/พรุ่งนี้"
+// ตรงกับ TimeZone ใน DSN ของ postgres.go ไม่ใช้ time.Local เพราะใน Container (Render) เป็น UTC
+var systemLocation = func() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Bangkok"); err == nil {
+		return loc
+	}
+	return time.FixedZone("Asia/Bangkok", 7*60*60) // ไม่มี tzdata: ไทยไม่มีเวลาออมแสง ใช้ +07:00 ได้ตรงกัน
+}()
+
+// parseClock แปลง "HH:MM" เป็นระยะเวลานับจากเที่ยงคืน
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
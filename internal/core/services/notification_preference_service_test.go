@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+type fakePreferenceRepo struct {
+	ports.NotificationPreferenceRepository
+	quiet domain.NotificationQuietHours
+}
+
+func (r *fakePreferenceRepo) GetQuietHours(userID uint) (*domain.NotificationQuietHours, error) {
+	quiet := r.quiet
+	return &quiet, nil
+}
+
+func TestQuietUntilUsesSchoolTimezone(t *testing.T) {
+	service := NewNotificationPreferenceService(&fakePreferenceRepo{quiet: domain.NotificationQuietHours{Enabled: true, Start: "22:00", End: "07:00"}})
+
+	tests := []struct {
+		name  string
+		now   time.Time // เวลาของเครื่อง (UTC)
+		quiet bool
+		until time.Time
+	}{
+		{"23:00 Bangkok", time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC), true, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+		{"06:00 Bangkok", time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC), true, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+		// 22:30 UTC จะอยู่ในช่วงห้ามรบกวนถ้าตีความตามเวลาเครื่อง แต่ที่กรุงเทพเป็น 05:30 ของวันถัดไป
+		{"05:30 Bangkok", time.Date(2026, 10, 19, 22, 30, 0, 0, time.UTC), true, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+		{"08:00 Bangkok", time.Date(2026, 10, 20, 1, 0, 0, 0, time.UTC), false, time.Time{}},
+		{"21:00 Bangkok", time.Date(2026, 10, 20, 14, 0, 0, 0, time.UTC), false, time.Time{}},
+	}
+	for _, tt := range tests {
+		until, quiet := service.QuietUntil(1, tt.now)
+		if quiet != tt.quiet || !until.Equal(tt.until) {
+			t.Errorf("%s: QuietUntil = %v, %v, want %v, %v", tt.name, until, quiet, tt.until, tt.quiet)
+		}
+	}
+}
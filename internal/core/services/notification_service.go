@@ -1,26 +1,416 @@
 package services
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"html"
+	"mime"
+	"net"
 	"net/http"
+	"net/smtp"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 	"tunorth-brms-backend/internal/core/domain"
 	"tunorth-brms-backend/internal/core/ports"
 )
 
 type notificationService struct {
-	settings ports.SettingService
-	roomRepo ports.RoomRepository
-	userRepo ports.UserRepository
+	settings     ports.SettingService
+	roomRepo     ports.RoomRepository
+	userRepo     ports.UserRepository
+	bookingRepo  ports.BookingRepository
+	inbox        ports.UserNotificationRepository
+	outboxRepo   ports.OutboxRepository
+	preferences  ports.NotificationPreferenceService
+	templates    ports.NotificationTemplateService
+	chatWebhooks ports.ChatWebhookService
+	client       *http.Client
 }
 
-func NewNotificationService(settings ports.SettingService, roomRepo ports.RoomRepository, userRepo ports.UserRepository) ports.NotificationService {
+// notifyHTTPTimeout: Telegram / LINE ค้างได้ไม่เกินนี้ ไม่ให้ Dispatcher ค้างทั้งรอบ
+const notifyHTTPTimeout = 10 * time.Second
+
+func NewNotificationService(settings ports.SettingService, roomRepo ports.RoomRepository, userRepo ports.UserRepository, bookingRepo ports.BookingRepository, inbox ports.UserNotificationRepository, outboxRepo ports.OutboxRepository, preferences ports.NotificationPreferenceService, templates ports.NotificationTemplateService, chatWebhooks ports.ChatWebhookService) ports.NotificationService {
 	return &notificationService{
-		settings: settings,
-		roomRepo: roomRepo,
-		userRepo: userRepo,
+		settings:     settings,
+		roomRepo:     roomRepo,
+		userRepo:     userRepo,
+		bookingRepo:  bookingRepo,
+		inbox:        inbox,
+		outboxRepo:   outboxRepo,
+		preferences:  preferences,
+		templates:    templates,
+		chatWebhooks: chatWebhooks,
+		client:       &http.Client{Timeout: notifyHTTPTimeout},
+	}
+}
+
+// withoutURL: *url.Error มี URL เต็ม (Telegram ใส่ Bot Token ใน path, Slack/Discord/Teams ใส่ใน URL ของ Webhook)
+// ตัดออกก่อนคืน error ที่จะถูกเก็บใน Outbox และแสดงในหน้าผู้ดูแล
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s request failed: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
+
+// deferError: ยังไม่ถึงเวลาส่ง (เช่น อยู่ในช่วงห้ามรบกวน) ให้ Dispatcher เลื่อนไปโดยไม่นับเป็นความผิดพลาด
+type deferError struct {
+	until time.Time
+}
+
+func (e *deferError) Error() string {
+	return fmt.Sprintf("deferred until %s", e.until.Format(time.RFC3339))
+}
+
+// Deliver: แปลงข้อความใน Outbox เป็นการแจ้งเตือนจริง
+// ข้อความที่ยังไม่มีผู้รับ (RecipientID = nil) จะถูกส่งเข้ากลุ่ม แล้วแตกเป็นรายคน
+func (s *notificationService) Deliver(msg *domain.OutboxMessage) error {
+	// สรุปประจำวันไม่ผูกกับการจองรายการใด
+	if msg.EventType == domain.EventDailyDigest {
+		return s.deliverDigest(msg)
+	}
+
+	if msg.BookingID == nil {
+		return fmt.Errorf("outbox message %d has no booking", msg.ID)
+	}
+
+	// การยกเลิกคือการลบ (Soft Delete) จึงต้องดึงรวมรายการที่ถูกลบด้วย
+	booking, err := s.bookingRepo.GetByIDWithDeleted(*msg.BookingID)
+	if err != nil {
+		return fmt.Errorf("booking %d not found: %w", *msg.BookingID, err)
+	}
+
+	var payload domain.OutboxPayload
+	if msg.Payload != "" {
+		if err := json.Unmarshal([]byte(msg.Payload), &payload); err != nil {
+			return fmt.Errorf("invalid payload: %w", err)
+		}
+	}
+
+	// ใช้สถานะ ณ เวลาที่เกิดเหตุการณ์ ไม่ใช่สถานะล่าสุด
+	if msg.EventType == domain.EventBookingStatusChanged && payload.Status != "" {
+		booking.Status = payload.Status
+	}
+
+	if msg.ChatWebhookID != nil {
+		return s.deliverChatWebhook(msg, *msg.ChatWebhookID, booking, &payload)
+	}
+	if msg.RecipientID == nil {
+		return s.fanOut(msg, booking, &payload)
+	}
+	return s.deliverToUser(msg, *msg.RecipientID, booking, &payload)
+}
+
+// fanOut: สร้างข้อความรายคนลง Outbox (กันซ้ำด้วย DedupKey) แล้วส่งเข้ากลุ่ม Telegram เดิม
+func (s *notificationService) fanOut(msg *domain.OutboxMessage, booking *domain.Booking, payload *domain.OutboxPayload) error {
+	recipients, err := s.recipients(msg.EventType, booking, payload)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range recipients {
+		child := newOutboxMessage(msg.EventType, msg.Channel, nil)
+		child.Payload = msg.Payload
+		child.BookingID = msg.BookingID
+		recipientID := userID
+		child.RecipientID = &recipientID
+		key := fmt.Sprintf("fanout:%d:%d", msg.ID, userID)
+		child.DedupKey = &key
+
+		if _, err := s.outboxRepo.CreateUnique(&child); err != nil {
+			return err
+		}
+	}
+
+	if msg.Channel != domain.ChannelTelegram {
+		return nil
+	}
+
+	// ห้องแชท Slack/Discord/Teams ใช้เหตุการณ์เดียวกับกลุ่ม Telegram (แยกข้อความเพื่อ retry แยกกัน)
+	targets, err := s.chatWebhooks.Targets(msg.EventType, booking)
+	if err != nil {
+		return err
+	}
+	for _, target := range targets {
+		child := newOutboxMessage(msg.EventType, domain.ChannelChatWebhook, nil)
+		child.Payload = msg.Payload
+		child.BookingID = msg.BookingID
+		webhookID := target.ID
+		child.ChatWebhookID = &webhookID
+		key := fmt.Sprintf("chat:%d:%d", msg.ID, target.ID)
+		child.DedupKey = &key
+
+		if _, err := s.outboxRepo.CreateUnique(&child); err != nil {
+			return err
+		}
+	}
+
+	switch msg.EventType {
+	case domain.EventBookingCreated:
+		return s.NotifyAdminNewBooking(booking)
+	case domain.EventBookingStatusChanged:
+		return s.NotifyUserStatusChange(booking)
+	case domain.EventBookingCancelled:
+		return s.NotifyAdminBookingCancelled(booking)
+	}
+	return nil
+}
+
+// recipients: ผู้ใช้ที่เกี่ยวข้องกับเหตุการณ์
+func (s *notificationService) recipients(eventType string, booking *domain.Booking, payload *domain.OutboxPayload) ([]uint, error) {
+	switch eventType {
+	case domain.EventBookingCreated:
+		// แจ้งผู้อนุมัติเฉพาะการจองที่รออนุมัติ
+		if booking.Status != "pending" {
+			return nil, nil
+		}
+		approvers, err := s.userRepo.GetByRoles("admin", "approver")
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]uint, 0, len(approvers))
+		for _, u := range approvers {
+			ids = append(ids, u.ID)
+		}
+		return ids, nil
+	case domain.EventBookingStatusChanged, domain.EventBookingCancelled:
+		return []uint{booking.UserID}, nil
+	case domain.EventBookingReminder:
+		return []uint{payload.UserID}, nil
+	default:
+		return nil, fmt.Errorf("unknown event type: %s", eventType)
+	}
+}
+
+// deliverToUser: ส่งถึงผู้ใช้ 1 คน ตามค่าที่ผู้ใช้เลือกไว้
+func (s *notificationService) deliverToUser(msg *domain.OutboxMessage, userID uint, booking *domain.Booking, payload *domain.OutboxPayload) error {
+	if !s.preferences.IsEnabled(userID, msg.EventType, msg.Channel) {
+		return nil
+	}
+
+	now := time.Now()
+	if msg.EventType == domain.EventBookingReminder {
+		// การจองถูกยกเลิก/เปลี่ยนสถานะ หรือเริ่มไปแล้ว ไม่ต้องเตือน
+		if booking.Status != "approved" || booking.DeletedAt.Valid || !booking.StartTime.After(now) {
+			return nil
+		}
+	}
+
+	// ช่วงห้ามรบกวน: เลื่อนไปส่งตอนหมดช่วง (ยกเว้นแจ้งเตือนในระบบ ที่ไม่รบกวนอยู่แล้ว)
+	if msg.Channel != domain.ChannelInApp {
+		if until, quiet := s.preferences.QuietUntil(userID, now); quiet {
+			return &deferError{until: until}
+		}
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil // ผู้ใช้ถูกลบไปแล้ว
+	}
+
+	bookingID := booking.ID
+	return s.sendToUser(user, msg, s.templateData(booking, payload), &bookingID)
+}
+
+// deliverChatWebhook: ส่งไปยังห้องแชท ตามภาษาที่ตั้งไว้ (ไม่ตั้ง = ภาษาเริ่มต้นของระบบ)
+func (s *notificationService) deliverChatWebhook(msg *domain.OutboxMessage, webhookID uint, booking *domain.Booking, payload *domain.OutboxPayload) error {
+	webhook, err := s.chatWebhooks.GetWebhook(webhookID)
+	if err != nil || !webhook.IsActive {
+		return nil // ถูกลบหรือปิดไปแล้ว
+	}
+
+	language := webhook.Language
+	if language == "" {
+		language = s.settings.GetSettingValue("default_language")
+	}
+
+	data := s.templateData(booking, payload)
+	content, err := s.templates.Render(msg.EventType, domain.ChannelChatWebhook, language, data)
+	if err != nil {
+		return err
+	}
+
+	link := data.BaseURL
+	if msg.EventType == domain.EventBookingCreated {
+		link = data.AdminLink
+	}
+	return s.chatWebhooks.Post(webhook, ports.ChatMessage{
+		Title: content.Subject,
+		Text:  content.Body,
+		Link:  link,
+		Color: booking.Room.Color,
+	})
+}
+
+// deliverDigest: สรุปประจำวันสำหรับผู้อนุมัติ (ไม่ส่งถ้าไม่มีอะไรต้องสรุป)
+func (s *notificationService) deliverDigest(msg *domain.OutboxMessage) error {
+	if msg.RecipientID == nil {
+		return fmt.Errorf("digest message %d has no recipient", msg.ID)
+	}
+	userID := *msg.RecipientID
+
+	if !s.preferences.IsEnabled(userID, msg.EventType, msg.Channel) {
+		return nil
+	}
+
+	now := time.Now()
+	if msg.Channel != domain.ChannelInApp {
+		if until, quiet := s.preferences.QuietUntil(userID, now); quiet {
+			return &deferError{until: until}
+		}
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil
+	}
+
+	data, err := s.digestData(now)
+	if err != nil {
+		return err
+	}
+	if len(data.Pending) == 0 && len(data.Tomorrow) == 0 {
+		return nil
+	}
+
+	return s.sendToUser(user, msg, data, nil)
+}
+
+// digestData: รวบรวมการจองที่รออนุมัติ (และที่รอนานเกินกำหนด) กับการจองที่อนุมัติแล้วของพรุ่งนี้แยกตามห้อง
+func (s *notificationService) digestData(now time.Time) (*ports.NotificationTemplateData, error) {
+	now = now.In(systemLocation) // "พรุ่งนี้" ตามวันที่ของโรงเรียน ไม่ใช่ของเครื่อง
+	overdueHours, err := strconv.Atoi(s.settings.GetSettingValue("digest_overdue_hours"))
+	if err != nil || overdueHours <= 0 {
+		overdueHours = 24
 	}
+
+	baseURL := publicBaseURL(s.settings)
+	data := &ports.NotificationTemplateData{
+		SiteName:     s.settings.GetSettingValue("site_name"),
+		BaseURL:      baseURL,
+		AdminLink:    baseURL + "/admin/bookings",
+		Date:         now.Format("02/01/2006"),
+		OverdueHours: overdueHours,
+	}
+
+	pending, err := s.bookingRepo.GetByStatus("pending")
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range pending {
+		item := digestBooking(&b, now)
+		data.Pending = append(data.Pending, item)
+		if item.WaitingHours >= overdueHours {
+			data.Overdue = append(data.Overdue, item)
+		}
+	}
+
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	bookings, err := s.bookingRepo.GetByDateRange(tomorrow, tomorrow.Add(24*time.Hour-time.Second))
+	if err != nil {
+		return nil, err
+	}
+	roomIndex := make(map[uint]int)
+	for _, b := range bookings {
+		if b.Status != "approved" {
+			continue
+		}
+		idx, ok := roomIndex[b.RoomID]
+		if !ok {
+			idx = len(data.Tomorrow)
+			roomIndex[b.RoomID] = idx
+			data.Tomorrow = append(data.Tomorrow, ports.DigestRoom{RoomName: b.Room.RoomName})
+		}
+		data.Tomorrow[idx].Bookings = append(data.Tomorrow[idx].Bookings, digestBooking(&b, now))
+	}
+
+	data.PendingCount = len(data.Pending)
+	return data, nil
+}
+
+func digestBooking(b *domain.Booking, now time.Time) ports.DigestBooking {
+	return ports.DigestBooking{
+		ID:           b.ID,
+		Subject:      b.Subject,
+		RoomName:     b.Room.RoomName,
+		UserName:     b.User.FullName,
+		Start:        b.StartTime.Format("02/01/2006 15:04"),
+		End:          b.EndTime.Format("15:04"),
+		WaitingHours: int(now.Sub(b.CreatedAt).Hours()),
+	}
+}
+
+// sendToUser: Render Template ตามภาษาของผู้ใช้ แล้วส่งตามช่องทางของข้อความ
+func (s *notificationService) sendToUser(user *domain.User, msg *domain.OutboxMessage, data *ports.NotificationTemplateData, bookingID *uint) error {
+	content, err := s.templates.Render(msg.EventType, msg.Channel, user.Language, data)
+	if err != nil {
+		return err
+	}
+
+	switch msg.Channel {
+	case domain.ChannelInApp:
+		return s.inbox.CreateBatch([]domain.UserNotification{{
+			UserID:    user.ID,
+			EventType: msg.EventType,
+			Title:     content.Subject,
+			Message:   content.Body,
+			BookingID: bookingID,
+		}})
+	case domain.ChannelTelegram:
+		return s.SendTelegram(user.TelegramChatID, content.Body)
+	case domain.ChannelEmail:
+		return s.SendEmail(user.Email, content.Subject, content.Body)
+	case domain.ChannelLine:
+		return s.SendLine(user.LineUserID, content.Subject+"\n\n"+content.Body)
+	default:
+		return fmt.Errorf("unknown channel: %s", msg.Channel)
+	}
+}
+
+// templateData: ข้อมูลการจองสำหรับใส่ใน Template
+func (s *notificationService) templateData(booking *domain.Booking, payload *domain.OutboxPayload) *ports.NotificationTemplateData {
+	roomName := booking.Room.RoomName
+	if roomName == "" {
+		roomName = fmt.Sprintf("ID %d", booking.RoomID)
+	}
+	userName := booking.User.FullName
+	if userName == "" {
+		userName = fmt.Sprintf("ID %d", booking.UserID)
+	}
+
+	baseURL := publicBaseURL(s.settings)
+	before := time.Duration(payload.BeforeMinutes) * time.Minute
+
+	return &ports.NotificationTemplateData{
+		SiteName:      s.settings.GetSettingValue("site_name"),
+		BaseURL:       baseURL,
+		AdminLink:     baseURL + "/admin/bookings",
+		BookingID:     booking.ID,
+		Subject:       booking.Subject,
+		RoomName:      roomName,
+		UserName:      userName,
+		Department:    booking.Department,
+		Start:         booking.StartTime.Format("02/01/2006 15:04"),
+		End:           booking.EndTime.Format("15:04"),
+		Status:        booking.Status,
+		StatusLabel:   statusLabel(booking.Status),
+		BeforeMinutes: payload.BeforeMinutes,
+		Before:        formatDuration(before),
+	}
+}
+
+// sendGroup: ส่งข้อความเข้ากลุ่ม Telegram ด้วย Template ของกลุ่ม (ภาษาเริ่มต้นของระบบ)
+func (s *notificationService) sendGroup(chatID, eventType string, booking *domain.Booking) error {
+	content, err := s.templates.Render(eventType, domain.ChannelTelegramGroup, s.settings.GetSettingValue("default_language"), s.templateData(booking, &domain.OutboxPayload{}))
+	if err != nil {
+		return err
+	}
+	return s.SendTelegram(chatID, content.Body)
 }
 
 func (s *notificationService) SendTelegram(chatID, message string) error {
@@ -31,14 +421,14 @@ func (s *notificationService) SendTelegram(chatID, message string) error {
 
 	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", token)
 	formData := url.Values{
-		"chat_id": {chatID},
-		"text":    {message},
+		"chat_id":    {chatID},
+		"text":       {message},
 		"parse_mode": {"HTML"},
 	}
 
-	resp, err := http.PostForm(apiURL, formData)
+	resp, err := s.client.PostForm(apiURL, formData)
 	if err != nil {
-		return err
+		return withoutURL(err)
 	}
 	defer resp.Body.Close()
 
@@ -49,52 +439,81 @@ func (s *notificationService) SendTelegram(chatID, message string) error {
 	return nil
 }
 
-func (s *notificationService) NotifyAdminNewBooking(booking *domain.Booking) error {
-	// เช็คว่าเปิดแจ้งเตือนไหม
-	if s.settings.GetSettingValue("notify_admin") != "true" {
-		return nil
+// SendEmail: ส่งอีเมลผ่าน SMTP ตามค่าใน Settings
+func (s *notificationService) SendEmail(to, subject, body string) error {
+	host := s.settings.GetSettingValue("smtp_host")
+	from := s.settings.GetSettingValue("smtp_from")
+	if host == "" || from == "" || to == "" {
+		return nil // ไม่ error แต่ไม่ส่ง
 	}
 
-	adminChatID := s.settings.GetSettingValue("telegram_admin_chat_id")
-	if adminChatID == "" {
-		return nil
+	port := s.settings.GetSettingValue("smtp_port")
+	if port == "" {
+		port = "587"
 	}
 
-	// Fetch Room Name
-	roomName := fmt.Sprintf("ID %d", booking.RoomID)
-	if room, err := s.roomRepo.GetByID(booking.RoomID); err == nil {
-		roomName = room.RoomName
+	var auth smtp.Auth
+	if username := s.settings.GetSettingValue("smtp_username"); username != "" {
+		auth = smtp.PlainAuth("", username, s.settings.GetSettingValue("smtp_password"), host)
 	}
 
-	// Fetch User Name
-	userName := fmt.Sprintf("ID %d", booking.UserID)
-	if user, err := s.userRepo.GetByID(booking.UserID); err == nil {
-		userName = user.FullName
+	msg := "From: " + from + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body
+
+	return smtp.SendMail(net.JoinHostPort(host, port), auth, from, []string{to}, []byte(msg))
+}
+
+// SendLine: Push Message ผ่าน LINE Messaging API
+func (s *notificationService) SendLine(to, message string) error {
+	token := s.settings.GetSettingValue("line_channel_access_token")
+	if token == "" || to == "" {
+		return nil // ไม่ error แต่ไม่ส่ง
 	}
 
-	// Link (Use 127.0.0.1 instead of localhost which Telegram often strips)
-	link := `<a href="http://127.0.0.1:3000/admin/bookings">คลิกเพื่อดูรายละเอียดและอนุมัติ</a>`
+	body, err := json.Marshal(map[string]interface{}{
+		"to":       to,
+		"messages": []map[string]string{{"type": "text", "text": message}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.line.me/v2/bot/message/push", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
 
-	// Escape strings to prevent HTML parse errors
-	subject := html.EscapeString(booking.Subject)
-	rName := html.EscapeString(roomName)
-	uName := html.EscapeString(userName)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return withoutURL(err)
+	}
+	defer resp.Body.Close()
 
-	msg := fmt.Sprintf(
-		"🔔 <b>มีการจองห้องประชุมใหม่</b> 🔔\n\n"+
-			"📝 <b>หัวข้อ:</b> %s\n"+
-			"🏢 <b>ห้อง:</b> %s\n"+
-			"📅 <b>เวลา:</b> %s\n"+
-			"👤 <b>ผู้จอง:</b> %s\n\n"+
-			"🔗 <b>Link :</b> %s",
-		subject,
-		rName,
-		booking.StartTime.Format("02/01/2006 15:04"),
-		uName,
-		link,
-	)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to send line message, status: %d", resp.StatusCode)
+	}
 
-	return s.SendTelegram(adminChatID, msg)
+	return nil
+}
+
+func (s *notificationService) NotifyAdminNewBooking(booking *domain.Booking) error {
+	// เช็คว่าเปิดแจ้งเตือนไหม
+	if s.settings.GetSettingValue("notify_admin") != "true" {
+		return nil
+	}
+
+	adminChatID := s.settings.GetSettingValue("telegram_admin_chat_id")
+	if adminChatID == "" {
+		return nil
+	}
+
+	return s.sendGroup(adminChatID, domain.EventBookingCreated, booking)
 }
 
 func (s *notificationService) NotifyUserStatusChange(booking *domain.Booking) error {
@@ -103,33 +522,64 @@ func (s *notificationService) NotifyUserStatusChange(booking *domain.Booking) er
 		return nil
 	}
 
-	// อันนี้อาจต้อง mapping User ID -> Telegram Chat ID ของ user คนนั้น
-	// แต่ใน requirement ตอนนี้อาจจะส่งเข้า Group รวม หรือถ้ามี field telegram_id ใน user ก็ใช้ได้
-	// สมมติส่งเข้า User Chat ID กลางที่ตั้งไว้ใน Settings ก่อน
+	// ผู้จองที่มี Chat ID ส่วนตัวจะได้รับแบบรายคนอยู่แล้ว ที่นี่ส่งเข้า Group กลางแทนเฉพาะคนที่ไม่มี
+	if booking.User.TelegramChatID != "" {
+		return nil
+	}
 	userChatID := s.settings.GetSettingValue("telegram_user_chat_id")
-	
-	// TODO: ถ้าอนาคต Users มี telegram_id ส่วนตัว ให้ดึงจาก userRepo.GetByID(booking.UserID).TelegramID
-	
 	if userChatID == "" {
 		return nil
 	}
-	
-	statusText := "รออนุมัติ"
-	if booking.Status == "approved" {
-		statusText = "✅ อนุมัติแล้ว"
-	} else if booking.Status == "rejected" {
-		statusText = "❌ ไม่อนุมัติ"
+
+	return s.sendGroup(userChatID, domain.EventBookingStatusChanged, booking)
+}
+
+// NotifyAdminBookingCancelled: แจ้งแอดมินเมื่อมีการยกเลิกการจอง
+func (s *notificationService) NotifyAdminBookingCancelled(booking *domain.Booking) error {
+	if s.settings.GetSettingValue("notify_admin") != "true" {
+		return nil
+	}
+
+	adminChatID := s.settings.GetSettingValue("telegram_admin_chat_id")
+	if adminChatID == "" {
+		return nil
 	}
 
-	subject := html.EscapeString(booking.Subject)
+	return s.sendGroup(adminChatID, domain.EventBookingCancelled, booking)
+}
 
-	msg := fmt.Sprintf(
-		"🔔 <b>สถานะการจองอัปเดต</b>\n\n"+
-			"📝 <b>หัวข้อ:</b> %s\n"+
-			"สถานะใหม่: <b>%s</b>",
-		subject,
-		statusText,
-	)
+// publicBaseURL: URL หน้าเว็บ Frontend สำหรับสร้างลิงก์ในข้อความ
+func publicBaseURL(settings ports.SettingService) string {
+	baseURL := strings.TrimRight(settings.GetSettingValue("public_base_url"), "/")
+	if baseURL == "" {
+		// ใช้ 127.0.0.1 แทน localhost เพราะ Telegram มักตัดลิงก์ localhost ทิ้ง
+		baseURL = "http://127.0.0.1:3000"
+	}
+	return baseURL
+}
 
-	return s.SendTelegram(userChatID, msg)
+// formatDuration แปลงเป็นข้อความภาษาไทย เช่น "1 วัน", "15 นาที"
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%d วัน", int(d/(24*time.Hour)))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%d ชั่วโมง", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%d นาที", int(d/time.Minute))
+	}
+}
+
+// statusLabel ข้อความสถานะสำหรับแสดงผล
+func statusLabel(status string) string {
+	switch status {
+	case "approved":
+		return "✅ อนุมัติแล้ว"
+	case "rejected":
+		return "❌ ไม่อนุมัติ"
+	case "cancelled":
+		return "ยกเลิกแล้ว"
+	default:
+		return "รออนุมัติ"
+	}
 }
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"tunorth-brms-backend/internal/core/domain"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestSendErrorsDoNotLeakTokens(t *testing.T) {
+	settings := NewSettingService(newFakeSettingRepo(
+		domain.Setting{SettingName: "telegram_bot_token", SettingValue: "123456:bot-secret"},
+		domain.Setting{SettingName: "line_channel_access_token", SettingValue: "line-secret"},
+	), fakeLogService{}, nil, "")
+	service := NewNotificationService(settings, nil, nil, nil, nil, nil, nil, nil, nil).(*notificationService)
+	if service.client.Timeout == 0 {
+		t.Fatal("notification HTTP client has no timeout")
+	}
+	service.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	for name, send := range map[string]func() error{
+		"telegram": func() error { return service.SendTelegram("42", "hello") },
+		"line":     func() error { return service.SendLine("U42", "hello") },
+	} {
+		err := send()
+		if err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+		if msg := err.Error(); strings.Contains(msg, "secret") || strings.Contains(msg, "https://") {
+			t.Errorf("%s error exposes the request URL: %s", name, msg)
+		}
+	}
+}
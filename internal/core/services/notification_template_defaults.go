@@ -0,0 +1,158 @@
+package services
+
+import "tunorth-brms-backend/internal/core/domain"
+
+// เนื้อหาเริ่มต้นของแต่ละเหตุการณ์ (ใช้สร้าง Template ครั้งแรก และใช้แทนเมื่อไม่พบใน DB)
+type defaultTemplateText struct {
+	Subject string
+	Body    string
+}
+
+var defaultTemplateTexts = map[string]map[string]defaultTemplateText{
+	domain.LanguageThai: {
+		domain.EventBookingCreated: {
+			Subject: "มีการจองใหม่รออนุมัติ",
+			Body:    "หัวข้อ: {{.Subject}}\nห้อง: {{.RoomName}}\nเวลา: {{.Start}} - {{.End}}\nผู้จอง: {{.UserName}}\n\nดูรายละเอียดและอนุมัติ: {{.AdminLink}}",
+		},
+		domain.EventBookingStatusChanged: {
+			Subject: "สถานะการจองอัปเดต",
+			Body:    "หัวข้อ: {{.Subject}}\nห้อง: {{.RoomName}}\nเวลา: {{.Start}} - {{.End}}\nสถานะใหม่: {{.StatusLabel}}",
+		},
+		domain.EventBookingReminder: {
+			Subject: "การประชุมจะเริ่มในอีก {{.Before}}",
+			Body:    "หัวข้อ: {{.Subject}}\nห้อง: {{.RoomName}}\nเวลา: {{.Start}} - {{.End}}",
+		},
+		domain.EventBookingCancelled: {
+			Subject: "การจองถูกยกเลิก",
+			Body:    "หัวข้อ: {{.Subject}}\nห้อง: {{.RoomName}}\nเวลา: {{.Start}} - {{.End}}",
+		},
+		domain.EventDailyDigest: {
+			Subject: "สรุปประจำวัน {{.Date}}: รออนุมัติ {{.PendingCount}} รายการ",
+			Body: "รออนุมัติ {{.PendingCount}} รายการ\n" +
+				"{{range .Pending}}- {{.Subject}} ({{.RoomName}}) {{.Start}} - {{.End}} โดย {{.UserName}}\n{{end}}" +
+				"{{if .Overdue}}\nรอนานเกิน {{.OverdueHours}} ชั่วโมง:\n{{range .Overdue}}- {{.Subject}} ({{.RoomName}}) รอมาแล้ว {{.WaitingHours}} ชั่วโมง\n{{end}}{{end}}" +
+				"{{if .Tomorrow}}\nการจองที่อนุมัติแล้วของพรุ่งนี้:\n{{range .Tomorrow}}{{.RoomName}}\n{{range .Bookings}}  - {{.Start}} - {{.End}} {{.Subject}}\n{{end}}{{end}}{{end}}" +
+				"\nดูรายละเอียดและอนุมัติ: {{.AdminLink}}",
+		},
+	},
+	domain.LanguageEnglish: {
+		domain.EventBookingCreated: {
+			Subject: "New booking awaiting approval",
+			Body:    "Subject: {{.Subject}}\nRoom: {{.RoomName}}\nTime: {{.Start}} - {{.End}}\nBooked by: {{.UserName}}\n\nReview and approve: {{.AdminLink}}",
+		},
+		domain.EventBookingStatusChanged: {
+			Subject: "Booking status updated",
+			Body:    "Subject: {{.Subject}}\nRoom: {{.RoomName}}\nTime: {{.Start}} - {{.End}}\nNew status: {{.Status}}",
+		},
+		domain.EventBookingReminder: {
+			Subject: "Your meeting starts in {{.BeforeMinutes}} minutes",
+			Body:    "Subject: {{.Subject}}\nRoom: {{.RoomName}}\nTime: {{.Start}} - {{.End}}",
+		},
+		domain.EventBookingCancelled: {
+			Subject: "Booking cancelled",
+			Body:    "Subject: {{.Subject}}\nRoom: {{.RoomName}}\nTime: {{.Start}} - {{.End}}",
+		},
+		domain.EventDailyDigest: {
+			Subject: "Daily digest {{.Date}}: {{.PendingCount}} pending",
+			Body: "{{.PendingCount}} bookings awaiting approval\n" +
+				"{{range .Pending}}- {{.Subject}} ({{.RoomName}}) {{.Start}} - {{.End}} by {{.UserName}}\n{{end}}" +
+				"{{if .Overdue}}\nWaiting more than {{.OverdueHours}} hours:\n{{range .Overdue}}- {{.Subject}} ({{.RoomName}}) waiting {{.WaitingHours}} hours\n{{end}}{{end}}" +
+				"{{if .Tomorrow}}\nTomorrow's approved bookings:\n{{range .Tomorrow}}{{.RoomName}}\n{{range .Bookings}}  - {{.Start}} - {{.End}} {{.Subject}}\n{{end}}{{end}}{{end}}" +
+				"\nReview and approve: {{.AdminLink}}",
+		},
+	},
+}
+
+// ข้อความเข้ากลุ่ม Telegram (เดิม hard-code อยู่ใน NotifyAdminNewBooking / NotifyUserStatusChange)
+var defaultGroupTemplateTexts = map[string]map[string]defaultTemplateText{
+	domain.LanguageThai: {
+		domain.EventBookingCreated: {
+			Subject: "มีการจองห้องประชุมใหม่",
+			Body: "🔔 <b>มีการจองห้องประชุมใหม่</b> 🔔\n\n" +
+				"📝 <b>หัวข้อ:</b> {{.Subject}}\n" +
+				"🏢 <b>ห้อง:</b> {{.RoomName}}\n" +
+				"📅 <b>เวลา:</b> {{.Start}}\n" +
+				"👤 <b>ผู้จอง:</b> {{.UserName}}\n\n" +
+				"🔗 <b>Link :</b> <a href=\"{{.AdminLink}}\">คลิกเพื่อดูรายละเอียดและอนุมัติ</a>",
+		},
+		domain.EventBookingStatusChanged: {
+			Subject: "สถานะการจองอัปเดต",
+			Body: "🔔 <b>สถานะการจองอัปเดต</b>\n\n" +
+				"📝 <b>หัวข้อ:</b> {{.Subject}}\n" +
+				"สถานะใหม่: <b>{{.StatusLabel}}</b>",
+		},
+		domain.EventBookingCancelled: {
+			Subject: "การจองถูกยกเลิก",
+			Body: "🗑 <b>การจองถูกยกเลิก</b>\n\n" +
+				"📝 <b>หัวข้อ:</b> {{.Subject}}\n" +
+				"🏢 <b>ห้อง:</b> {{.RoomName}}\n" +
+				"📅 <b>เวลา:</b> {{.Start}}",
+		},
+	},
+	domain.LanguageEnglish: {
+		domain.EventBookingCreated: {
+			Subject: "New booking",
+			Body: "🔔 <b>New booking</b> 🔔\n\n" +
+				"📝 <b>Subject:</b> {{.Subject}}\n" +
+				"🏢 <b>Room:</b> {{.RoomName}}\n" +
+				"📅 <b>Time:</b> {{.Start}}\n" +
+				"👤 <b>Booked by:</b> {{.UserName}}\n\n" +
+				"🔗 <b>Link :</b> <a href=\"{{.AdminLink}}\">Review and approve</a>",
+		},
+		domain.EventBookingStatusChanged: {
+			Subject: "Booking status updated",
+			Body: "🔔 <b>Booking status updated</b>\n\n" +
+				"📝 <b>Subject:</b> {{.Subject}}\n" +
+				"New status: <b>{{.Status}}</b>",
+		},
+		domain.EventBookingCancelled: {
+			Subject: "Booking cancelled",
+			Body: "🗑 <b>Booking cancelled</b>\n\n" +
+				"📝 <b>Subject:</b> {{.Subject}}\n" +
+				"🏢 <b>Room:</b> {{.RoomName}}\n" +
+				"📅 <b>Time:</b> {{.Start}}",
+		},
+	},
+}
+
+// defaultTemplate: Template เริ่มต้นของ (เหตุการณ์, ช่องทาง, ภาษา)
+func defaultTemplate(eventType, channel, language string) (domain.NotificationTemplate, bool) {
+	if channel == domain.ChannelTelegramGroup {
+		text, ok := defaultGroupTemplateTexts[language][eventType]
+		if !ok {
+			return domain.NotificationTemplate{}, false
+		}
+		return domain.NotificationTemplate{EventType: eventType, Channel: channel, Language: language, Subject: text.Subject, Body: text.Body}, true
+	}
+
+	if channel == domain.ChannelChatWebhook && !contains(domain.ChatGroupEvents, eventType) {
+		return domain.NotificationTemplate{}, false
+	}
+
+	text, ok := defaultTemplateTexts[language][eventType]
+	if !ok {
+		return domain.NotificationTemplate{}, false
+	}
+	body := text.Body
+	if channel == domain.ChannelTelegram {
+		// Telegram รายบุคคล: ใส่หัวข้อตัวหนาไว้บรรทัดแรก
+		body = "🔔 <b>" + text.Subject + "</b>\n\n" + body
+	}
+	return domain.NotificationTemplate{EventType: eventType, Channel: channel, Language: language, Subject: text.Subject, Body: body}, true
+}
+
+// defaultTemplates: ทุก Template เริ่มต้นที่ต้องมีในระบบ
+func defaultTemplates() []domain.NotificationTemplate {
+	var templates []domain.NotificationTemplate
+	channels := append([]string{domain.ChannelTelegramGroup, domain.ChannelChatWebhook}, domain.NotificationChannels...)
+	for _, language := range domain.NotificationLanguages {
+		for _, event := range domain.NotificationEvents {
+			for _, channel := range channels {
+				if tpl, ok := defaultTemplate(event, channel, language); ok {
+					templates = append(templates, tpl)
+				}
+			}
+		}
+	}
+	return templates
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+type notificationTemplateService struct {
+	repo       ports.NotificationTemplateRepository
+	settings   ports.SettingService
+	logService ports.LogService
+}
+
+func NewNotificationTemplateService(repo ports.NotificationTemplateRepository, settings ports.SettingService, logService ports.LogService) ports.NotificationTemplateService {
+	return &notificationTemplateService{repo: repo, settings: settings, logService: logService}
+}
+
+func (s *notificationTemplateService) GetTemplates(eventType, channel, language string) ([]domain.NotificationTemplate, error) {
+	all, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []domain.NotificationTemplate
+	for _, t := range all {
+		if (eventType == "" || t.EventType == eventType) &&
+			(channel == "" || t.Channel == channel) &&
+			(language == "" || t.Language == language) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
+}
+
+// CreateTemplate: ใช้เพิ่มภาษาใหม่ให้กับเหตุการณ์/ช่องทางที่มีอยู่
+func (s *notificationTemplateService) CreateTemplate(tpl *domain.NotificationTemplate, actorID uint) error {
+	if err := s.validate(tpl); err != nil {
+		return err
+	}
+	if _, err := s.repo.Find(tpl.EventType, tpl.Channel, tpl.Language); err == nil {
+		return errors.New("template already exists for this event, channel and language")
+	}
+
+	tpl.ID = 0
+	if err := s.repo.Create(tpl); err != nil {
+		return err
+	}
+
+	go s.logService.LogAction(actorID, "CREATE_NOTIFICATION_TEMPLATE", fmt.Sprintf("Created template %s/%s/%s", tpl.EventType, tpl.Channel, tpl.Language), "", "")
+	return nil
+}
+
+// UpdateTemplate: แก้ได้เฉพาะหัวข้อและเนื้อหา
+func (s *notificationTemplateService) UpdateTemplate(id uint, input *domain.NotificationTemplate, actorID uint) error {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("template not found")
+	}
+
+	existing.Subject = input.Subject
+	existing.Body = input.Body
+	if err := s.validate(existing); err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(existing); err != nil {
+		return err
+	}
+
+	go s.logService.LogAction(actorID, "UPDATE_NOTIFICATION_TEMPLATE", fmt.Sprintf("Updated template ID: %d", id), "", "")
+	return nil
+}
+
+func (s *notificationTemplateService) Preview(tpl *domain.NotificationTemplate) (*ports.RenderedNotification, error) {
+	// ไม่ได้ส่งเนื้อหามา ให้ใช้ที่บันทึกไว้
+	if tpl.Subject == "" && tpl.Body == "" {
+		stored, err := s.lookup(tpl.EventType, tpl.Channel, tpl.Language)
+		if err != nil {
+			return nil, err
+		}
+		tpl = stored
+	}
+	return renderTemplate(tpl, s.sampleData())
+}
+
+// Render: หา Template ตามภาษา ถ้าไม่มีใช้ภาษาเริ่มต้นของระบบ แล้วค่อยใช้ค่าเริ่มต้นในโค้ด
+func (s *notificationTemplateService) Render(eventType, channel, language string, data *ports.NotificationTemplateData) (*ports.RenderedNotification, error) {
+	tpl, err := s.lookup(eventType, channel, language)
+	if err != nil {
+		return nil, err
+	}
+	return renderTemplate(tpl, data)
+}
+
+func (s *notificationTemplateService) lookup(eventType, channel, language string) (*domain.NotificationTemplate, error) {
+	for _, lang := range []string{language, s.settings.GetSettingValue("default_language"), domain.LanguageThai} {
+		if lang == "" {
+			continue
+		}
+		if tpl, err := s.repo.Find(eventType, channel, lang); err == nil {
+			return tpl, nil
+		}
+	}
+	if tpl, ok := defaultTemplate(eventType, channel, domain.LanguageThai); ok {
+		return &tpl, nil
+	}
+	return nil, fmt.Errorf("no template for %s/%s", eventType, channel)
+}
+
+func (s *notificationTemplateService) InitializeDefaults() error {
+	for _, d := range defaultTemplates() {
+		if _, err := s.repo.Find(d.EventType, d.Channel, d.Language); err == nil {
+			continue
+		}
+		tpl := d
+		if err := s.repo.Create(&tpl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate: ตรวจชื่อเหตุการณ์/ช่องทาง/ภาษา และลอง render กับข้อมูลตัวอย่าง (จับตัวแปรที่ไม่มีอยู่จริง)
+func (s *notificationTemplateService) validate(tpl *domain.NotificationTemplate) error {
+	if !contains(domain.NotificationEvents, tpl.EventType) {
+		return fmt.Errorf("unknown event type: %s", tpl.EventType)
+	}
+	if tpl.Channel != domain.ChannelTelegramGroup && tpl.Channel != domain.ChannelChatWebhook && !contains(domain.NotificationChannels, tpl.Channel) {
+		return fmt.Errorf("unknown channel: %s", tpl.Channel)
+	}
+	if tpl.Language == "" || len(tpl.Language) > 5 {
+		return errors.New("language is required (e.g. th, en)")
+	}
+	if tpl.Body == "" {
+		return errors.New("body is required")
+	}
+	if _, err := renderTemplate(tpl, s.sampleData()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// sampleData: การจองตัวอย่างสำหรับ Preview และตรวจสอบ Template
+func (s *notificationTemplateService) sampleData() *ports.NotificationTemplateData {
+	baseURL := publicBaseURL(s.settings)
+	sample := ports.DigestBooking{
+		ID:           123,
+		Subject:      "ประชุมคณะกรรมการ",
+		RoomName:     "ห้องประชุม 1",
+		UserName:     "สมชาย ใจดี",
+		Start:        "20/10/2026 09:00",
+		End:          "12:00",
+		WaitingHours: 30,
+	}
+	return &ports.NotificationTemplateData{
+		SiteName:      s.settings.GetSettingValue("site_name"),
+		BaseURL:       baseURL,
+		AdminLink:     baseURL + "/admin/bookings",
+		BookingID:     123,
+		Subject:       "ประชุมคณะกรรมการ",
+		RoomName:      "ห้องประชุม 1",
+		UserName:      "สมชาย ใจดี",
+		Department:    "ฝ่ายวิชาการ",
+		Start:         "20/10/2026 09:00",
+		End:           "12:00",
+		Status:        "approved",
+		StatusLabel:   statusLabel("approved"),
+		BeforeMinutes: 15,
+		Before:        formatDuration(15 * time.Minute),
+		Date:          "19/10/2026",
+		PendingCount:  1,
+		Pending:       []ports.DigestBooking{sample},
+		Overdue:       []ports.DigestBooking{sample},
+		OverdueHours:  24,
+		Tomorrow:      []ports.DigestRoom{{RoomName: sample.RoomName, Bookings: []ports.DigestBooking{sample}}},
+	}
+}
+
+// renderTemplate: Telegram (HTML parse mode) ใช้ html/template เพื่อ escape ค่าอัตโนมัติ
+func renderTemplate(tpl *domain.NotificationTemplate, data *ports.NotificationTemplateData) (*ports.RenderedNotification, error) {
+	subjectTpl, err := texttemplate.New("subject").Parse(tpl.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template: %w", err)
+	}
+	var subject bytes.Buffer
+	if err := subjectTpl.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("invalid subject template: %w", err)
+	}
+
+	var body bytes.Buffer
+	if tpl.Channel == domain.ChannelTelegram || tpl.Channel == domain.ChannelTelegramGroup {
+		bodyTpl, err := htmltemplate.New("body").Parse(tpl.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body template: %w", err)
+		}
+		if err := bodyTpl.Execute(&body, data); err != nil {
+			return nil, fmt.Errorf("invalid body template: %w", err)
+		}
+	} else {
+		bodyTpl, err := texttemplate.New("body").Parse(tpl.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body template: %w", err)
+		}
+		if err := bodyTpl.Execute(&body, data); err != nil {
+			return nil, fmt.Errorf("invalid body template: %w", err)
+		}
+	}
+
+	return &ports.RenderedNotification{Subject: subject.String(), Body: body.String()}, nil
+}
@@ -0,0 +1,424 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/oauth2"
+)
+
+const (
+	oidcStateTTL    = 10 * time.Minute // เวลาที่ผู้ใช้มีในการ Login ที่ผู้ให้บริการ
+	oidcExchangeTTL = time.Minute      // รหัสแลก Token ที่ส่งให้ Frontend
+	oidcHTTPTimeout = 15 * time.Second
+)
+
+var errOIDCLoginExpired = errors.New("single sign-on session is invalid or has expired, please try again")
+
+type oidcService struct {
+	repo       ports.OIDCLoginRepository
+	userRepo   ports.UserRepository
+	tokens     ports.TokenService
+	settings   ports.SettingService
+	twoFactor  ports.TwoFactorService
+	throttle   ports.LoginThrottleService
+	logService ports.LogService
+
+	// Provider จาก discovery (/.well-known/openid-configuration) แยกตาม issuer
+	mu        sync.Mutex
+	providers map[string]*oidc.Provider
+}
+
+func NewOIDCService(repo ports.OIDCLoginRepository, userRepo ports.UserRepository, tokens ports.TokenService, settings ports.SettingService, twoFactor ports.TwoFactorService, throttle ports.LoginThrottleService, logService ports.LogService) ports.OIDCService {
+	s := &oidcService{
+		repo:       repo,
+		userRepo:   userRepo,
+		tokens:     tokens,
+		settings:   settings,
+		twoFactor:  twoFactor,
+		throttle:   throttle,
+		logService: logService,
+		providers:  make(map[string]*oidc.Provider),
+	}
+
+	// เปลี่ยนผู้ให้บริการแล้ว ไม่ต้องเก็บ Provider เดิมไว้ (ทำ discovery ใหม่ตอนใช้ครั้งถัดไป)
+	settings.Subscribe(func(keys []string) {
+		if ports.SettingsAffected(keys, "oidc_issuer_url") {
+			s.mu.Lock()
+			s.providers = make(map[string]*oidc.Provider)
+			s.mu.Unlock()
+		}
+	})
+	return s
+}
+
+func (s *oidcService) Enabled() bool {
+	return s.settings.GetSettingValue("oidc_enabled") == "true" &&
+		s.settings.GetSettingValue("oidc_issuer_url") != "" &&
+		s.settings.GetSettingValue("oidc_client_id") != ""
+}
+
+func (s *oidcService) LoginURL(client ports.ClientInfo) (string, string, error) {
+	if !s.Enabled() {
+		return "", "", errors.New("single sign-on is not enabled")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcHTTPTimeout)
+	defer cancel()
+	_, config, err := s.oauthConfig(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomURLToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomURLToken()
+	if err != nil {
+		return "", "", err
+	}
+	// browser อยู่ใน Cookie ของผู้ที่เริ่ม Login เท่านั้น: ลิงก์ callback ที่ถูกส่งต่อไปให้คนอื่นใช้ไม่ได้
+	browser, err := randomURLToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	now := time.Now()
+	s.repo.DeleteExpired(now)
+	if err := s.repo.Create(&domain.OIDCLogin{
+		StateHash:    hashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		BrowserHash:  hashToken(browser),
+		ExpiresAt:    now.Add(oidcStateTTL),
+		IPAddress:    client.IP,
+	}); err != nil {
+		return "", "", err
+	}
+
+	return config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), browser, nil
+}
+
+func (s *oidcService) Callback(state, code, browser string, client ports.ClientInfo) (string, error) {
+	login, err := s.repo.GetByStateHash(hashToken(state))
+	if err != nil || login.ExchangeHash != nil || time.Now().After(login.ExpiresAt) {
+		return "", errOIDCLoginExpired
+	}
+	if browser == "" || login.BrowserHash == "" || subtle.ConstantTimeCompare([]byte(hashToken(browser)), []byte(login.BrowserHash)) != 1 {
+		log.Printf("OIDC: callback from a browser that did not start the sign-in (started from %s, callback from %s)", login.IPAddress, client.IP)
+		return "", errOIDCLoginExpired
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcHTTPTimeout)
+	defer cancel()
+	provider, config, err := s.oauthConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(login.CodeVerifier))
+	if err != nil {
+		log.Println("OIDC: code exchange failed:", err)
+		return "", errors.New("could not complete sign-in with the identity provider")
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return "", errors.New("identity provider did not return an ID token")
+	}
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: config.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		log.Println("OIDC: ID token verification failed:", err)
+		return "", errors.New("could not verify the identity provider's response")
+	}
+	if idToken.Nonce != login.Nonce {
+		return "", errOIDCLoginExpired
+	}
+
+	claims := map[string]interface{}{}
+	if err := idToken.Claims(&claims); err != nil {
+		return "", err
+	}
+	// บางผู้ให้บริการไม่ใส่อีเมลใน ID Token: ถามจาก UserInfo เพิ่ม
+	if claimString(claims, s.claimName("oidc_email_claim", "email")) == "" {
+		if info, err := provider.UserInfo(ctx, oauth2.StaticTokenSource(token)); err == nil {
+			info.Claims(&claims)
+		}
+	}
+
+	user, err := s.resolveUser(claims, idToken.Subject, client)
+	if err != nil {
+		return "", err
+	}
+
+	exchange, err := randomURLToken()
+	if err != nil {
+		return "", err
+	}
+	ok, err = s.repo.Complete(login.ID, hashToken(exchange), user.ID, time.Now().Add(oidcExchangeTTL))
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errOIDCLoginExpired
+	}
+	return exchange, nil
+}
+
+func (s *oidcService) Exchange(code string, client ports.ClientInfo) (*ports.TokenPair, uint, error) {
+	login, err := s.repo.ConsumeExchange(hashToken(code), time.Now())
+	if err != nil || login.UserID == nil {
+		return nil, 0, errOIDCLoginExpired
+	}
+
+	user, err := s.userRepo.GetByID(*login.UserID)
+	if err != nil || !user.IsActive() {
+		return nil, 0, errors.New("account is not active")
+	}
+
+	// บัญชีที่ถูกล็อก (Login ผิดเกินกำหนด) เข้าทาง SSO ไม่ได้เช่นกัน
+	if err := s.throttle.Check(user.Username, client.IP); err != nil {
+		return nil, 0, err
+	}
+
+	// 2FA เหมือน Login ด้วยรหัสผ่าน: คืน *ports.TwoFactorRequiredError ให้ Frontend ส่งรหัสไปที่ /api/login/2fa
+	if s.twoFactor.IsRequired(user) {
+		challenge, err := s.twoFactor.StartChallenge(user, client)
+		if err != nil {
+			return nil, 0, err
+		}
+		return nil, user.ID, challenge
+	}
+
+	tokens, err := s.tokens.Issue(user, client)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.throttle.RecordSuccess(user.Username)
+	return tokens, user.ID, nil
+}
+
+// resolveUser: ผูกกับบัญชีเดิมด้วยอีเมล หรือสร้างบัญชีใหม่ (ถ้าเปิด oidc_auto_provision)
+func (s *oidcService) resolveUser(claims map[string]interface{}, subject string, client ports.ClientInfo) (*domain.User, error) {
+	email := strings.TrimSpace(claimString(claims, s.claimName("oidc_email_claim", "email")))
+	if !strings.Contains(email, "@") {
+		return nil, errors.New("identity provider did not return an email address")
+	}
+	// ไม่มี claim email_verified = ถือว่ายังไม่ยืนยัน (ห้ามผูก / สร้างบัญชีจากอีเมลที่ IdP ไม่รับรอง)
+	if !claimTrue(claims, "email_verified") {
+		return nil, errors.New("email address is not verified by the identity provider")
+	}
+	if !emailDomainAllowed(email, s.settings.GetSettingValue("oidc_allowed_email_domains")) {
+		return nil, errors.New("single sign-on is not allowed for this email domain")
+	}
+
+	role, mapped := s.roleFromClaims(claims)
+	fullName := claimString(claims, s.claimName("oidc_name_claim", "name"))
+	if fullName == "" {
+		fullName = email
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		user, err = s.userRepo.GetByEmail(strings.ToLower(email))
+	}
+	if err != nil {
+		if s.settings.GetSettingValue("oidc_auto_provision") != "true" {
+			return nil, errors.New("no account is registered with this email address")
+		}
+		return s.provision(email, fullName, role, subject, client)
+	}
+
+	if !user.IsActive() {
+		return nil, errors.New("your account is not active")
+	}
+	// ผูกกับบัญชีเดิมได้เฉพาะบัญชีที่ยืนยันอีเมลแล้ว ไม่งั้นใครก็ตั้งอีเมลค้างไว้รอให้ SSO มาผูกได้
+	if user.EmailVerifiedAt == nil {
+		return nil, errors.New("the account with this email address has not verified its email, sign in with your password and verify it first")
+	}
+
+	// role ตาม claim ใช้กับบัญชีที่สร้างจาก SSO เท่านั้น (บัญชี local / LDAP ที่ผูกด้วยอีเมลคง role เดิม)
+	if mapped && user.AuthSource == domain.AuthSourceOIDC && user.Role != role {
+		user.Role = role
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, err
+		}
+		if err := s.tokens.RevokeUserTokens(user.ID); err != nil {
+			return nil, err
+		}
+		go s.logService.LogClientAction(user.ID, "OIDC_ROLE_SYNC", fmt.Sprintf("Role of %s set to %s from identity provider claims", user.Username, role), client)
+	}
+	return user, nil
+}
+
+func (s *oidcService) provision(email, fullName, role, subject string, client ports.ClientInfo) (*domain.User, error) {
+	if role == "" {
+		role = domain.RoleUser
+	}
+
+	// username จากส่วนหน้าของอีเมล ถ้าซ้ำใช้อีเมลทั้งหมด
+	username := strings.ToLower(email[:strings.LastIndex(email, "@")])
+	if _, err := s.userRepo.GetByUsername(username); err == nil {
+		username = strings.ToLower(email)
+	}
+
+	random, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(random), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &domain.User{
+		Username:        username,
+		Password:        string(hashedPassword),
+		FullName:        fullName,
+		Role:            role,
+		Email:           strings.ToLower(email),
+		Status:          domain.UserStatusActive,
+		EmailVerifiedAt: &now,
+		AuthSource:      domain.AuthSourceOIDC,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		log.Println("OIDC: failed to provision user:", err)
+		return nil, errors.New("could not create an account for this identity")
+	}
+
+	go s.logService.LogClientAction(user.ID, "OIDC_PROVISION_USER", fmt.Sprintf("Created user %s from single sign-on (subject %s, role: %s)", user.Username, subject, role), client)
+	return user, nil
+}
+
+// roleFromClaims: ค่าใน claim ตรงกับ oidc_admin_values / oidc_approver_values (คั่นด้วยจุลภาค)
+func (s *oidcService) roleFromClaims(claims map[string]interface{}) (string, bool) {
+	claim := strings.TrimSpace(s.settings.GetSettingValue("oidc_role_claim"))
+	if claim == "" {
+		return "", false
+	}
+
+	values := claimStrings(claims[claim])
+	matches := func(setting string) bool {
+		for _, want := range strings.Split(s.settings.GetSettingValue(setting), ",") {
+			want = strings.TrimSpace(want)
+			if want == "" {
+				continue
+			}
+			for _, v := range values {
+				if strings.EqualFold(v, want) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	switch {
+	case matches("oidc_admin_values"):
+		return domain.RoleAdmin, true
+	case matches("oidc_approver_values"):
+		return domain.RoleApprover, true
+	default:
+		return domain.RoleUser, true
+	}
+}
+
+func (s *oidcService) oauthConfig(ctx context.Context) (*oidc.Provider, *oauth2.Config, error) {
+	issuer := strings.TrimSpace(s.settings.GetSettingValue("oidc_issuer_url"))
+	redirectURL := strings.TrimSpace(s.settings.GetSettingValue("oidc_redirect_url"))
+	if redirectURL == "" {
+		return nil, nil, errors.New("oidc_redirect_url is not configured")
+	}
+
+	s.mu.Lock()
+	provider, ok := s.providers[issuer]
+	s.mu.Unlock()
+	if !ok {
+		var err error
+		provider, err = oidc.NewProvider(ctx, issuer)
+		if err != nil {
+			log.Println("OIDC: discovery failed:", err)
+			return nil, nil, errors.New("could not reach the identity provider")
+		}
+		s.mu.Lock()
+		s.providers[issuer] = provider
+		s.mu.Unlock()
+	}
+
+	scopes := strings.Fields(strings.ReplaceAll(s.settings.GetSettingValue("oidc_scopes"), ",", " "))
+	if !contains(scopes, oidc.ScopeOpenID) {
+		scopes = append([]string{oidc.ScopeOpenID}, scopes...)
+	}
+
+	return provider, &oauth2.Config{
+		ClientID:     s.settings.GetSettingValue("oidc_client_id"),
+		ClientSecret: s.settings.GetSettingValue("oidc_client_secret"),
+		RedirectURL:  redirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       scopes,
+	}, nil
+}
+
+func (s *oidcService) claimName(key, fallback string) string {
+	if v := strings.TrimSpace(s.settings.GetSettingValue(key)); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func claimString(claims map[string]interface{}, name string) string {
+	if v, ok := claims[name].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// claimTrue: บาง IdP ส่ง boolean เป็น string "true"
+func claimTrue(claims map[string]interface{}, name string) bool {
+	switch v := claims[name].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+// claimStrings: claim อาจเป็น array หรือ string คั่นด้วยช่องว่าง/จุลภาค
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.FieldsFunc(v, func(r rune) bool { return r == ' ' || r == ',' })
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func randomURLToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
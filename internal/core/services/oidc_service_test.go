@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+type fakeOIDCLoginRepo struct {
+	mu     sync.Mutex
+	logins []*domain.OIDCLogin
+}
+
+func (r *fakeOIDCLoginRepo) Create(login *domain.OIDCLogin) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	login.ID = uint(len(r.logins) + 1)
+	stored := *login
+	r.logins = append(r.logins, &stored)
+	return nil
+}
+
+func (r *fakeOIDCLoginRepo) GetByStateHash(hash string) (*domain.OIDCLogin, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, login := range r.logins {
+		if login.StateHash == hash {
+			found := *login
+			return &found, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeOIDCLoginRepo) Complete(id uint, exchangeHash string, userID uint, expiresAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	login := r.logins[id-1]
+	if login.ExchangeHash != nil {
+		return false, nil
+	}
+	login.ExchangeHash, login.UserID, login.ExpiresAt = &exchangeHash, &userID, expiresAt
+	return true, nil
+}
+
+func (r *fakeOIDCLoginRepo) ConsumeExchange(exchangeHash string, at time.Time) (*domain.OIDCLogin, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, login := range r.logins {
+		if login.ExchangeHash != nil && *login.ExchangeHash == exchangeHash && login.ConsumedAt == nil && login.ExpiresAt.After(at) {
+			login.ConsumedAt = &at
+			found := *login
+			return &found, nil
+		}
+	}
+	return nil, errors.New("exchange code already used or expired")
+}
+
+func (r *fakeOIDCLoginRepo) DeleteExpired(before time.Time) error { return nil }
+
+func TestOIDCCallbackRequiresBrowserThatStartedLogin(t *testing.T) {
+	repo := &fakeOIDCLoginRepo{}
+	settings := NewSettingService(newFakeSettingRepo(), fakeLogService{}, nil, "")
+	service := NewOIDCService(repo, newFakeUserRepo(), &fakeTokenService{}, settings, noTwoFactor{}, &fakeThrottle{}, fakeLogService{})
+	repo.Create(&domain.OIDCLogin{StateHash: hashToken("state"), BrowserHash: hashToken("browser"), ExpiresAt: time.Now().Add(oidcStateTTL)})
+
+	// ลิงก์ callback ที่ถูกส่งต่อมา: Browser ไม่มี Cookie หรือมีของการ Login อื่น
+	for _, browser := range []string{"", "other-browser"} {
+		if _, err := service.Callback("state", "code", browser, ports.ClientInfo{}); !errors.Is(err, errOIDCLoginExpired) {
+			t.Errorf("browser %q: got %v, want errOIDCLoginExpired", browser, err)
+		}
+	}
+
+	// Browser เดียวกันผ่านการตรวจไปถึงขั้นคุยกับผู้ให้บริการ (ในเทสต์ยังไม่ได้ตั้งค่า จึง error อื่น)
+	if _, err := service.Callback("state", "code", "browser", ports.ClientInfo{}); err == nil || errors.Is(err, errOIDCLoginExpired) {
+		t.Fatalf("browser that started the login: got %v", err)
+	}
+}
+
+func newResolveFixture(settings []domain.Setting, users ...*domain.User) (*oidcService, *fakeUserRepo) {
+	userRepo := newFakeUserRepo(users...)
+	settingService := NewSettingService(newFakeSettingRepo(settings...), fakeLogService{}, nil, "")
+	return NewOIDCService(&fakeOIDCLoginRepo{}, userRepo, &fakeTokenService{}, settingService, noTwoFactor{}, &fakeThrottle{}, fakeLogService{}).(*oidcService), userRepo
+}
+
+func TestResolveUserRequiresVerifiedEmail(t *testing.T) {
+	verifiedAt := time.Now()
+	service, _ := newResolveFixture(nil, &domain.User{Username: "somchai", Email: "somchai@example.com", EmailVerifiedAt: &verifiedAt})
+
+	for name, claims := range map[string]map[string]interface{}{
+		"missing":      {"email": "somchai@example.com"},
+		"false":        {"email": "somchai@example.com", "email_verified": false},
+		"string false": {"email": "somchai@example.com", "email_verified": "false"},
+	} {
+		if user, err := service.resolveUser(claims, "sub", ports.ClientInfo{}); err == nil {
+			t.Errorf("%s email_verified: resolved to %q, want error", name, user.Username)
+		}
+	}
+
+	// บาง IdP ส่งค่าเป็น string
+	if _, err := service.resolveUser(map[string]interface{}{"email": "somchai@example.com", "email_verified": "true"}, "sub", ports.ClientInfo{}); err != nil {
+		t.Fatalf("string true email_verified: %v", err)
+	}
+}
+
+func TestResolveUserEnforcesAllowedDomains(t *testing.T) {
+	verifiedAt := time.Now()
+	service, _ := newResolveFixture(
+		[]domain.Setting{{SettingName: "oidc_allowed_email_domains", SettingValue: "tu.ac.th, example.com"}},
+		&domain.User{Username: "outsider", Email: "outsider@evil.com", EmailVerifiedAt: &verifiedAt},
+		&domain.User{Username: "somchai", Email: "somchai@example.com", EmailVerifiedAt: &verifiedAt},
+	)
+
+	if _, err := service.resolveUser(map[string]interface{}{"email": "outsider@evil.com", "email_verified": true}, "sub", ports.ClientInfo{}); err == nil {
+		t.Fatal("email outside the allowed domains was accepted")
+	}
+	if user, err := service.resolveUser(map[string]interface{}{"email": "somchai@example.com", "email_verified": true}, "sub", ports.ClientInfo{}); err != nil || user.Username != "somchai" {
+		t.Fatalf("allowed domain: user = %v, err = %v", user, err)
+	}
+}
+
+func TestResolveUserLinksOnlyVerifiedAccounts(t *testing.T) {
+	verifiedAt := time.Now()
+	service, _ := newResolveFixture(nil,
+		&domain.User{Username: "pending", Email: "pending@example.com"},
+		&domain.User{Username: "somchai", Email: "somchai@example.com", EmailVerifiedAt: &verifiedAt},
+		&domain.User{Username: "disabled", Email: "disabled@example.com", EmailVerifiedAt: &verifiedAt, Status: domain.UserStatusDisabled},
+	)
+
+	// บัญชีที่ยังไม่ยืนยันอีเมล / ถูกปิดใช้งาน ห้ามผูกกับ SSO
+	for _, email := range []string{"pending@example.com", "disabled@example.com"} {
+		if user, err := service.resolveUser(map[string]interface{}{"email": email, "email_verified": true}, "sub", ports.ClientInfo{}); err == nil {
+			t.Errorf("%s: linked to %q, want error", email, user.Username)
+		}
+	}
+
+	user, err := service.resolveUser(map[string]interface{}{"email": "somchai@example.com", "email_verified": true}, "sub", ports.ClientInfo{})
+	if err != nil || user.ID != 2 {
+		t.Fatalf("verified account: user = %v, err = %v", user, err)
+	}
+}
+
+func TestResolveUserAutoProvision(t *testing.T) {
+	claims := map[string]interface{}{"email": "Newcomer@Example.com", "email_verified": true, "name": "Newcomer"}
+
+	service, users := newResolveFixture(nil)
+	if _, err := service.resolveUser(claims, "sub", ports.ClientInfo{}); err == nil {
+		t.Fatal("unknown email without auto provision was accepted")
+	}
+	if count, _ := users.Count(); count != 0 {
+		t.Fatalf("created %d accounts without auto provision", count)
+	}
+
+	service, users = newResolveFixture([]domain.Setting{{SettingName: "oidc_auto_provision", SettingValue: "true"}})
+	user, err := service.resolveUser(claims, "sub", ports.ClientInfo{})
+	if err != nil {
+		t.Fatalf("auto provision: %v", err)
+	}
+	if user.Username != "newcomer" || user.Email != "newcomer@example.com" || user.AuthSource != domain.AuthSourceOIDC || user.Role != domain.RoleUser {
+		t.Fatalf("provisioned user = %+v", user)
+	}
+	if count, _ := users.Count(); count != 1 {
+		t.Fatalf("accounts = %d, want 1", count)
+	}
+}
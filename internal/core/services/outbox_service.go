@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+const (
+	outboxPollInterval      = 10 * time.Second
+	outboxBatchSize         = 20
+	outboxLease             = 2 * time.Minute
+	outboxBaseBackoff       = 30 * time.Second
+	outboxMaxBackoff        = time.Hour
+	defaultOutboxMaxAttempt = 5
+)
+
+type outboxService struct {
+	repo       ports.OutboxRepository
+	notifier   ports.NotificationService
+	settings   ports.SettingService
+	logService ports.LogService
+	wake       chan struct{} // ส่งรอบใหม่ทันทีโดยไม่รอ ticker
+}
+
+func NewOutboxService(repo ports.OutboxRepository, notifier ports.NotificationService, settings ports.SettingService, logService ports.LogService) ports.OutboxService {
+	s := &outboxService{
+		repo:       repo,
+		notifier:   notifier,
+		settings:   settings,
+		logService: logService,
+		wake:       make(chan struct{}, 1),
+	}
+
+	// ตั้งค่าช่องทางแจ้งเตือนใหม่แล้ว ส่งข้อความที่ค้างอยู่ได้เลย
+	settings.Subscribe(func(keys []string) {
+		if ports.SettingsAffected(keys, "telegram_", "smtp_", "line_", "notify_") {
+			select {
+			case s.wake <- struct{}{}:
+			default:
+			}
+		}
+	})
+	return s
+}
+
+// Start: Dispatcher ทำงานเบื้องหลัง ดึงข้อความที่ถึงเวลาไปส่งทุกๆ outboxPollInterval
+func (s *outboxService) Start(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.DispatchDue(); err != nil {
+			log.Println("Outbox dispatch error:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// DispatchDue: ส่งข้อความที่ค้างอยู่ 1 รอบ คืนจำนวนที่ส่งสำเร็จ
+func (s *outboxService) DispatchDue() (int, error) {
+	msgs, err := s.repo.ClaimDue(time.Now(), outboxBatchSize, outboxLease)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range msgs {
+		if s.deliver(&msgs[i]) {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+func (s *outboxService) deliver(msg *domain.OutboxMessage) bool {
+	msg.Attempts++
+	if msg.MaxAttempts <= 0 {
+		msg.MaxAttempts = s.maxAttempts()
+	}
+
+	err := s.notifier.Deliver(msg)
+	now := time.Now()
+
+	var deferred *deferError
+	if errors.As(err, &deferred) {
+		// เลื่อนเวลาส่ง ไม่นับเป็นการลองส่ง
+		msg.Attempts--
+		msg.NextAttemptAt = deferred.until
+		if uerr := s.repo.Update(msg); uerr != nil {
+			log.Printf("Outbox: failed to update message %d: %v\n", msg.ID, uerr)
+		}
+		return false
+	}
+
+	if err == nil {
+		msg.Status = domain.OutboxStatusSent
+		msg.SentAt = &now
+		msg.LastError = ""
+	} else if msg.Attempts >= msg.MaxAttempts {
+		msg.Status = domain.OutboxStatusDead
+		msg.LastError = withoutURL(err).Error()
+	} else {
+		msg.Status = domain.OutboxStatusFailed
+		msg.LastError = withoutURL(err).Error()
+		msg.NextAttemptAt = now.Add(backoff(msg.Attempts))
+	}
+
+	if uerr := s.repo.Update(msg); uerr != nil {
+		log.Printf("Outbox: failed to update message %d: %v\n", msg.ID, uerr)
+	}
+	return err == nil
+}
+
+func (s *outboxService) maxAttempts() int {
+	n, err := strconv.Atoi(s.settings.GetSettingValue("notify_max_attempts"))
+	if err != nil || n <= 0 {
+		return defaultOutboxMaxAttempt
+	}
+	return n
+}
+
+// backoff: Exponential backoff (30s, 1m, 2m, 4m, ...) สูงสุดไม่เกิน outboxMaxBackoff
+func backoff(attempts int) time.Duration {
+	d := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return d
+}
+
+func (s *outboxService) GetMessages(status string, limit int) ([]domain.OutboxMessage, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	return s.repo.List(status, limit)
+}
+
+// Retry: ส่งใหม่ (ใช้กับข้อความ dead หรือ failed) โดยเริ่มนับจำนวนครั้งใหม่
+func (s *outboxService) Retry(id uint, actorID uint) error {
+	msg, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("notification not found")
+	}
+	if msg.Status == domain.OutboxStatusSent {
+		return errors.New("notification was already sent")
+	}
+
+	msg.Status = domain.OutboxStatusPending
+	msg.Attempts = 0
+	msg.NextAttemptAt = time.Now()
+	if err := s.repo.Update(msg); err != nil {
+		return err
+	}
+
+	go s.logService.LogAction(actorID, "RETRY_NOTIFICATION", fmt.Sprintf("Retry notification ID: %d", id), "", "")
+	return nil
+}
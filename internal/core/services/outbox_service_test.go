@@ -0,0 +1,155 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+// ClaimDue เลียนแบบ outboxRepository: ดึงที่ถึงเวลาแล้วเลื่อน next_attempt_at ออกไปเท่ากับ lease
+func (r *fakeOutboxRepo) ClaimDue(now time.Time, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []domain.OutboxMessage
+	for i := range r.messages {
+		m := &r.messages[i]
+		if len(claimed) == limit {
+			break
+		}
+		if (m.Status == domain.OutboxStatusPending || m.Status == domain.OutboxStatusFailed) && !m.NextAttemptAt.After(now) {
+			claimed = append(claimed, *m)
+			m.NextAttemptAt = now.Add(lease)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *fakeOutboxRepo) Update(msg *domain.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.messages {
+		if r.messages[i].ID == msg.ID {
+			r.messages[i] = *msg
+			return nil
+		}
+	}
+	return errors.New("record not found")
+}
+
+func (r *fakeOutboxRepo) message(id uint) domain.OutboxMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range r.messages {
+		if m.ID == id {
+			return m
+		}
+	}
+	return domain.OutboxMessage{}
+}
+
+// fakeNotifier คืน error ตามลำดับที่กำหนด (หมดแล้ว = ส่งสำเร็จ)
+type fakeNotifier struct {
+	ports.NotificationService
+	errs      []error
+	delivered int
+}
+
+func (n *fakeNotifier) Deliver(msg *domain.OutboxMessage) error {
+	n.delivered++
+	if len(n.errs) == 0 {
+		return nil
+	}
+	err := n.errs[0]
+	n.errs = n.errs[1:]
+	return err
+}
+
+func newOutboxFixture(maxAttempts string, errs ...error) (*outboxService, *fakeOutboxRepo, *fakeNotifier) {
+	repo := &fakeOutboxRepo{messages: []domain.OutboxMessage{{ID: 1, Status: domain.OutboxStatusPending, NextAttemptAt: time.Now().Add(-time.Minute)}}}
+	notifier := &fakeNotifier{errs: errs}
+	settings := NewSettingService(newFakeSettingRepo(domain.Setting{SettingName: "notify_max_attempts", SettingValue: maxAttempts}), fakeLogService{}, nil, "")
+	return NewOutboxService(repo, notifier, settings, fakeLogService{}).(*outboxService), repo, notifier
+}
+
+func TestBackoff(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		8:  outboxMaxBackoff, // 30s * 2^7 = 64m เกินเพดาน
+		20: outboxMaxBackoff,
+	} {
+		if got := backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestDeliverRetriesThenDeadLetters(t *testing.T) {
+	failure := errors.New("smtp: connection refused")
+	service, repo, _ := newOutboxFixture("3", failure, failure, failure)
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		msg := repo.message(1)
+		before := time.Now()
+		if service.deliver(&msg) {
+			t.Fatalf("attempt %d reported success", attempt)
+		}
+		stored := repo.message(1)
+		if stored.Attempts != attempt || stored.MaxAttempts != 3 || stored.LastError != failure.Error() {
+			t.Fatalf("attempt %d: stored %+v", attempt, stored)
+		}
+		if attempt < 3 {
+			if stored.Status != domain.OutboxStatusFailed {
+				t.Fatalf("attempt %d: status %s, want failed", attempt, stored.Status)
+			}
+			if wait := stored.NextAttemptAt.Sub(before); wait < backoff(attempt) || wait > backoff(attempt)+time.Second {
+				t.Fatalf("attempt %d: next attempt in %v, want %v", attempt, wait, backoff(attempt))
+			}
+		} else if stored.Status != domain.OutboxStatusDead {
+			t.Fatalf("status after the last attempt = %s, want dead", stored.Status)
+		}
+	}
+}
+
+func TestDeliverSuccessAfterFailure(t *testing.T) {
+	service, repo, _ := newOutboxFixture("5", errors.New("timeout"))
+
+	msg := repo.message(1)
+	service.deliver(&msg)
+	msg = repo.message(1)
+	if !service.deliver(&msg) {
+		t.Fatal("second attempt reported failure")
+	}
+	stored := repo.message(1)
+	if stored.Status != domain.OutboxStatusSent || stored.SentAt == nil || stored.LastError != "" || stored.Attempts != 2 {
+		t.Fatalf("stored %+v", stored)
+	}
+}
+
+func TestDeliverDeferralDoesNotCountAttempt(t *testing.T) {
+	until := time.Now().Add(8 * time.Hour)
+	service, repo, _ := newOutboxFixture("1", &deferError{until: until})
+
+	msg := repo.message(1)
+	service.deliver(&msg)
+	stored := repo.message(1)
+	// max attempts = 1: ถ้านับเป็นการลองส่งจะกลายเป็น dead ทันที
+	if stored.Status != domain.OutboxStatusPending || stored.Attempts != 0 || !stored.NextAttemptAt.Equal(until) || stored.LastError != "" {
+		t.Fatalf("deferred message stored as %+v", stored)
+	}
+}
+
+func TestDispatchDueSkipsMessagesNotDue(t *testing.T) {
+	service, _, notifier := newOutboxFixture("5", errors.New("timeout"))
+
+	if _, err := service.DispatchDue(); err != nil {
+		t.Fatal(err)
+	}
+	// รอบถัดไปทันที: ข้อความที่ล้มเหลวรอ backoff อยู่ ต้องไม่ถูกส่งซ้ำ
+	if sent, _ := service.DispatchDue(); sent != 0 || notifier.delivered != 1 {
+		t.Fatalf("second round sent %d, delivered %d times in total", sent, notifier.delivered)
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	defaultPasswordResetTTL = 30 * time.Minute
+	passwordResetPerHour    = 3 // จำนวนอีเมลสูงสุดต่อบัญชีต่อชั่วโมง
+	minPasswordLength       = 8
+)
+
+var errInvalidResetToken = errors.New("invalid or expired reset token")
+
+type passwordResetService struct {
+	repo       ports.PasswordResetRepository
+	userRepo   ports.UserRepository
+	notifier   ports.NotificationService
+	tokens     ports.TokenService
+	settings   ports.SettingService
+	logService ports.LogService
+}
+
+func NewPasswordResetService(repo ports.PasswordResetRepository, userRepo ports.UserRepository, notifier ports.NotificationService, tokens ports.TokenService, settings ports.SettingService, logService ports.LogService) ports.PasswordResetService {
+	return &passwordResetService{
+		repo:       repo,
+		userRepo:   userRepo,
+		notifier:   notifier,
+		tokens:     tokens,
+		settings:   settings,
+		logService: logService,
+	}
+}
+
+// RequestReset: ผลลัพธ์ต่อผู้เรียกเหมือนกันเสมอ ความผิดพลาดจะถูกเขียนลง log ของเซิร์ฟเวอร์เท่านั้น
+func (s *passwordResetService) RequestReset(email string, client ports.ClientInfo) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return
+	}
+
+	// บัญชีจาก Directory เปลี่ยนรหัสผ่านที่ Directory เท่านั้น
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil || !user.IsLocal() {
+		return
+	}
+
+	// จำกัดจำนวนอีเมลต่อบัญชี (กันการใช้ระบบยิงอีเมลใส่ผู้อื่น)
+	now := time.Now()
+	count, err := s.repo.CountSince(user.ID, now.Add(-time.Hour))
+	if err != nil || count >= passwordResetPerHour {
+		return
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		log.Println("Password reset: failed to generate token:", err)
+		return
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	ttl := s.ttl()
+	if err := s.repo.Create(&domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(ttl),
+		IPAddress: client.IP,
+	}); err != nil {
+		log.Println("Password reset: failed to store token:", err)
+		return
+	}
+
+	link := publicBaseURL(s.settings) + "/reset-password?token=" + url.QueryEscape(token)
+	subject, body := passwordResetEmail(user, link, ttl, s.settings.GetSettingValue("site_name"))
+	if err := s.notifier.SendEmail(user.Email, subject, body); err != nil {
+		log.Println("Password reset: failed to send email:", err)
+		return
+	}
+
+	go s.logService.LogClientAction(user.ID, "PASSWORD_RESET_REQUEST", "ขอรีเซ็ตรหัสผ่านทางอีเมล", client)
+}
+
+// ResetPassword: ตั้งรหัสผ่านใหม่ แล้วเพิกถอนทุก Token/ลิงก์รีเซ็ตอื่นของผู้ใช้
+func (s *passwordResetService) ResetPassword(token, newPassword string, client ports.ClientInfo) error {
+	if len(newPassword) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	stored, err := s.repo.GetByHash(hashToken(token))
+	if err != nil || stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return errInvalidResetToken
+	}
+
+	user, err := s.userRepo.GetByID(stored.UserID)
+	if err != nil || !user.IsLocal() {
+		return errInvalidResetToken
+	}
+
+	now := time.Now()
+	ok, err := s.repo.MarkUsed(stored.ID, now)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errInvalidResetToken
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.Password = string(hashedPassword)
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	if err := s.repo.InvalidateUser(user.ID, now); err != nil {
+		return err
+	}
+	if err := s.tokens.RevokeUserTokens(user.ID); err != nil {
+		return err
+	}
+
+	go s.logService.LogClientAction(user.ID, "PASSWORD_RESET", "ตั้งรหัสผ่านใหม่ผ่านลิงก์ทางอีเมล", client)
+	return nil
+}
+
+func (s *passwordResetService) ttl() time.Duration {
+	minutes, err := strconv.Atoi(s.settings.GetSettingValue("password_reset_ttl_minutes"))
+	if err != nil || minutes <= 0 {
+		return defaultPasswordResetTTL
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// passwordResetEmail: เนื้อหาอีเมลตามภาษาของผู้ใช้
+func passwordResetEmail(user *domain.User, link string, ttl time.Duration, siteName string) (string, string) {
+	minutes := int(ttl / time.Minute)
+	if user.Language == domain.LanguageEnglish {
+		return fmt.Sprintf("%s: reset your password", siteName),
+			fmt.Sprintf("Hello %s,\n\nWe received a request to reset your password. Open the link below within %d minutes to choose a new one:\n\n%s\n\nIf you did not request this, you can ignore this email.", user.FullName, minutes, link)
+	}
+	return fmt.Sprintf("%s: ตั้งรหัสผ่านใหม่", siteName),
+		fmt.Sprintf("เรียน %s\n\nระบบได้รับคำขอตั้งรหัสผ่านใหม่ของคุณ กรุณาเปิดลิงก์ด้านล่างภายใน %d นาที เพื่อตั้งรหัสผ่านใหม่\n\n%s\n\nหากคุณไม่ได้เป็นผู้ขอ สามารถเพิกเฉยต่ออีเมลฉบับนี้ได้", user.FullName, minutes, link)
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakePasswordResetRepo เก็บลิงก์รีเซ็ตในหน่วยความจำ
+type fakePasswordResetRepo struct {
+	mu     sync.Mutex
+	tokens []*domain.PasswordResetToken
+}
+
+func (r *fakePasswordResetRepo) Create(token *domain.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token.ID = uint(len(r.tokens) + 1)
+	token.CreatedAt = time.Now()
+	stored := *token
+	r.tokens = append(r.tokens, &stored)
+	return nil
+}
+
+func (r *fakePasswordResetRepo) GetByHash(hash string) (*domain.PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.TokenHash == hash {
+			found := *t
+			return &found, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakePasswordResetRepo) CountSince(userID uint, since time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.CreatedAt.After(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakePasswordResetRepo) MarkUsed(id uint, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.ID == id && t.UsedAt == nil {
+			t.UsedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakePasswordResetRepo) InvalidateUser(userID uint, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.UsedAt == nil {
+			t.UsedAt = &at
+		}
+	}
+	return nil
+}
+
+// fakeMailer จดอีเมลที่ส่งออก
+type fakeMailer struct {
+	ports.NotificationService
+	sent []string
+}
+
+func (m *fakeMailer) SendEmail(to, subject, body string) error {
+	m.sent = append(m.sent, to)
+	return nil
+}
+
+type passwordResetFixture struct {
+	service *passwordResetService
+	repo    *fakePasswordResetRepo
+	users   *fakeUserRepo
+	mailer  *fakeMailer
+	tokens  *fakeTokenService
+}
+
+func newPasswordResetFixture(users ...*domain.User) *passwordResetFixture {
+	f := &passwordResetFixture{
+		repo:   &fakePasswordResetRepo{},
+		users:  newFakeUserRepo(users...),
+		mailer: &fakeMailer{},
+		tokens: &fakeTokenService{},
+	}
+	settings := NewSettingService(newFakeSettingRepo(), fakeLogService{}, nil, "")
+	f.service = NewPasswordResetService(f.repo, f.users, f.mailer, f.tokens, settings, fakeLogService{}).(*passwordResetService)
+	return f
+}
+
+// addToken สร้างลิงก์รีเซ็ตที่รู้ค่า token ล่วงหน้า
+func (f *passwordResetFixture) addToken(userID uint, token string, expiresAt time.Time) {
+	_ = f.repo.Create(&domain.PasswordResetToken{UserID: userID, TokenHash: hashToken(token), ExpiresAt: expiresAt})
+}
+
+func TestResetPasswordIsSingleUse(t *testing.T) {
+	f := newPasswordResetFixture(&domain.User{Username: "somchai", Email: "somchai@example.com"})
+	f.addToken(1, "reset-token", time.Now().Add(time.Hour))
+	f.addToken(1, "other-token", time.Now().Add(time.Hour))
+
+	if err := f.service.ResetPassword("reset-token", "new-password-1", ports.ClientInfo{}); err != nil {
+		t.Fatalf("first reset: %v", err)
+	}
+	user, _ := f.users.GetByID(1)
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("new-password-1")) != nil {
+		t.Fatal("password was not updated")
+	}
+	if len(f.tokens.revoked) != 1 || f.tokens.revoked[0] != 1 {
+		t.Fatalf("revoked = %v, want [1]", f.tokens.revoked)
+	}
+
+	if err := f.service.ResetPassword("reset-token", "new-password-2", ports.ClientInfo{}); !errors.Is(err, errInvalidResetToken) {
+		t.Fatalf("reused token: err = %v, want errInvalidResetToken", err)
+	}
+	// ลิงก์อื่นที่ยังไม่ใช้ต้องถูกยกเลิกไปพร้อมกัน
+	if err := f.service.ResetPassword("other-token", "new-password-3", ports.ClientInfo{}); !errors.Is(err, errInvalidResetToken) {
+		t.Fatalf("sibling token: err = %v, want errInvalidResetToken", err)
+	}
+	user, _ = f.users.GetByID(1)
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("new-password-1")) != nil {
+		t.Fatal("password changed by a used token")
+	}
+}
+
+func TestResetPasswordRejectsExpiredToken(t *testing.T) {
+	f := newPasswordResetFixture(&domain.User{Username: "somchai", Email: "somchai@example.com", Password: "old-hash"})
+	f.addToken(1, "reset-token", time.Now().Add(-time.Minute))
+
+	if err := f.service.ResetPassword("reset-token", "new-password-1", ports.ClientInfo{}); !errors.Is(err, errInvalidResetToken) {
+		t.Fatalf("err = %v, want errInvalidResetToken", err)
+	}
+	user, _ := f.users.GetByID(1)
+	if user.Password != "old-hash" || len(f.tokens.revoked) != 0 {
+		t.Fatal("expired token changed the account")
+	}
+}
+
+func TestResetPasswordRejectsDirectoryAccount(t *testing.T) {
+	f := newPasswordResetFixture(&domain.User{Username: "ldapuser", Email: "ldap@example.com", AuthSource: domain.AuthSourceLDAP})
+	f.addToken(1, "reset-token", time.Now().Add(time.Hour))
+
+	if err := f.service.ResetPassword("reset-token", "new-password-1", ports.ClientInfo{}); !errors.Is(err, errInvalidResetToken) {
+		t.Fatalf("err = %v, want errInvalidResetToken", err)
+	}
+}
+
+func TestRequestResetCapsPerHour(t *testing.T) {
+	f := newPasswordResetFixture(&domain.User{Username: "somchai", Email: "somchai@example.com"})
+
+	for i := 0; i < passwordResetPerHour+2; i++ {
+		f.service.RequestReset("somchai@example.com", ports.ClientInfo{})
+	}
+	if len(f.mailer.sent) != passwordResetPerHour {
+		t.Fatalf("sent %d emails, want %d", len(f.mailer.sent), passwordResetPerHour)
+	}
+	if len(f.repo.tokens) != passwordResetPerHour {
+		t.Fatalf("stored %d tokens, want %d", len(f.repo.tokens), passwordResetPerHour)
+	}
+
+	// คำขอเก่ากว่าหนึ่งชั่วโมงไม่นับรวม
+	for _, token := range f.repo.tokens {
+		token.CreatedAt = time.Now().Add(-2 * time.Hour)
+	}
+	f.service.RequestReset("somchai@example.com", ports.ClientInfo{})
+	if len(f.mailer.sent) != passwordResetPerHour+1 {
+		t.Fatalf("sent %d emails after the window, want %d", len(f.mailer.sent), passwordResetPerHour+1)
+	}
+}
+
+func TestRequestResetSkipsDirectoryAndUnknownAccounts(t *testing.T) {
+	f := newPasswordResetFixture(
+		&domain.User{Username: "ldapuser", Email: "ldap@example.com", AuthSource: domain.AuthSourceLDAP},
+		&domain.User{Username: "oidcuser", Email: "oidc@example.com", AuthSource: domain.AuthSourceOIDC},
+	)
+
+	for _, email := range []string{"ldap@example.com", "oidc@example.com", "nobody@example.com", "  "} {
+		f.service.RequestReset(email, ports.ClientInfo{})
+	}
+	if len(f.mailer.sent) != 0 || len(f.repo.tokens) != 0 {
+		t.Fatalf("sent = %v, tokens = %d, want none", f.mailer.sent, len(f.repo.tokens))
+	}
+}
+
+func TestRequestResetEmailsLink(t *testing.T) {
+	f := newPasswordResetFixture(&domain.User{Username: "somchai", Email: "somchai@example.com"})
+
+	f.service.RequestReset(" somchai@example.com ", ports.ClientInfo{IP: "10.0.0.1"})
+	if len(f.mailer.sent) != 1 || f.mailer.sent[0] != "somchai@example.com" {
+		t.Fatalf("sent = %v", f.mailer.sent)
+	}
+	stored := f.repo.tokens[0]
+	if stored.UserID != 1 || stored.IPAddress != "10.0.0.1" || strings.TrimSpace(stored.TokenHash) == "" {
+		t.Fatalf("stored token = %+v", stored)
+	}
+	if ttl := time.Until(stored.ExpiresAt); ttl <= 0 || ttl > defaultPasswordResetTTL {
+		t.Fatalf("token expires in %v, want within %v", ttl, defaultPasswordResetTTL)
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+const reminderPollInterval = time.Minute
+
+type reminderService struct {
+	bookingRepo  ports.BookingRepository
+	attendeeRepo ports.BookingAttendeeRepository
+	outboxRepo   ports.OutboxRepository
+	settings     ports.SettingService
+}
+
+func NewReminderService(bookingRepo ports.BookingRepository, attendeeRepo ports.BookingAttendeeRepository, outboxRepo ports.OutboxRepository, settings ports.SettingService) ports.ReminderService {
+	return &reminderService{
+		bookingRepo:  bookingRepo,
+		attendeeRepo: attendeeRepo,
+		outboxRepo:   outboxRepo,
+		settings:     settings,
+	}
+}
+
+func (s *reminderService) Start(ctx context.Context) {
+	ticker := time.NewTicker(reminderPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ScheduleDue(time.Now()); err != nil {
+			log.Println("Reminder scheduler error:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ScheduleDue: หาการจองที่อนุมัติแล้วและใกล้ถึงเวลา แล้วใส่ลง Outbox
+// ใช้ DedupKey (booking + user + ระยะเวลา + เวลาเริ่ม) ทำให้หลาย instance รันพร้อมกันก็ไม่ส่งซ้ำ
+func (s *reminderService) ScheduleDue(now time.Time) (int, error) {
+	if s.settings.GetSettingValue("reminder_enabled") != "true" {
+		return 0, nil
+	}
+
+	offsets := parseReminderOffsets(s.settings.GetSettingValue("reminder_offsets"))
+	if len(offsets) == 0 {
+		return 0, nil
+	}
+
+	bookings, err := s.bookingRepo.GetByDateRange(now, now.Add(offsets[len(offsets)-1]))
+	if err != nil {
+		return 0, err
+	}
+
+	// ผู้เข้าร่วมที่ตอบรับแล้วของทุกการจองในช่วงนี้ (query เดียว)
+	var bookingIDs []uint
+	for _, b := range bookings {
+		if b.Status == "approved" && b.StartTime.After(now) {
+			bookingIDs = append(bookingIDs, b.ID)
+		}
+	}
+	accepted, err := s.attendeeRepo.GetAccepted(bookingIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, b := range bookings {
+		if b.Status != "approved" || !b.StartTime.After(now) {
+			continue
+		}
+
+		// เลือกช่วงที่แคบที่สุดที่เข้าเงื่อนไข (จองกระชั้นชิดจะได้แค่การเตือนครั้งล่าสุด ไม่ใช่ทุกครั้งพร้อมกัน)
+		until := b.StartTime.Sub(now)
+		var before time.Duration
+		for _, o := range offsets {
+			if until <= o {
+				before = o
+				break
+			}
+		}
+		if before == 0 {
+			continue
+		}
+
+		for _, userID := range reminderRecipients(&b, accepted[b.ID]) {
+			payload := &domain.OutboxPayload{UserID: userID, BeforeMinutes: int(before / time.Minute)}
+			for _, msg := range newOutboxMessages(domain.EventBookingReminder, payload) {
+				key := fmt.Sprintf("reminder:%d:%d:%d:%d:%s", b.ID, userID, payload.BeforeMinutes, b.StartTime.Unix(), msg.Channel)
+				bookingID, recipientID := b.ID, userID
+				msg.BookingID = &bookingID
+				msg.RecipientID = &recipientID
+				msg.DedupKey = &key
+
+				ok, err := s.outboxRepo.CreateUnique(&msg)
+				if err != nil {
+					return created, err
+				}
+				if ok {
+					created++
+				}
+			}
+		}
+	}
+	return created, nil
+}
+
+// reminderRecipients: ผู้จอง ตามด้วยผู้เข้าร่วมที่ตอบรับแล้ว (ไม่ซ้ำ)
+func reminderRecipients(b *domain.Booking, accepted []uint) []uint {
+	recipients := []uint{b.UserID}
+	seen := map[uint]bool{b.UserID: true}
+	for _, userID := range accepted {
+		if !seen[userID] {
+			seen[userID] = true
+			recipients = append(recipients, userID)
+		}
+	}
+	return recipients
+}
+
+// parseReminderOffsets แปลง "1440,15" (นาที) เป็นรายการ Duration เรียงจากน้อยไปมาก
+func parseReminderOffsets(value string) []time.Duration {
+	var offsets []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		minutes, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || minutes <= 0 {
+			continue
+		}
+		offsets = append(offsets, time.Duration(minutes)*time.Minute)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+type fakeBookingRepo struct {
+	ports.BookingRepository
+	bookings []domain.Booking
+}
+
+func (r *fakeBookingRepo) GetByDateRange(start, end time.Time) ([]domain.Booking, error) {
+	var found []domain.Booking
+	for _, b := range r.bookings {
+		if b.StartTime.Before(end) && b.EndTime.After(start) {
+			found = append(found, b)
+		}
+	}
+	return found, nil
+}
+
+// fakeOutboxRepo ใช้ DedupKey แบบเดียวกับ unique index ในฐานข้อมูล
+type fakeOutboxRepo struct {
+	ports.OutboxRepository
+	mu       sync.Mutex
+	messages []domain.OutboxMessage
+	keys     map[string]bool
+}
+
+func (r *fakeOutboxRepo) CreateUnique(msg *domain.OutboxMessage) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.keys == nil {
+		r.keys = map[string]bool{}
+	}
+	if msg.DedupKey != nil {
+		if r.keys[*msg.DedupKey] {
+			return false, nil
+		}
+		r.keys[*msg.DedupKey] = true
+	}
+	r.messages = append(r.messages, *msg)
+	return true, nil
+}
+
+func (r *fakeOutboxRepo) recipients() map[uint]bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recipients := map[uint]bool{}
+	for _, m := range r.messages {
+		recipients[*m.RecipientID] = true
+	}
+	return recipients
+}
+
+type fakeAttendeeRepo struct {
+	ports.BookingAttendeeRepository
+	attendees []domain.BookingAttendee
+}
+
+func (r *fakeAttendeeRepo) GetAccepted(bookingIDs []uint) (map[uint][]uint, error) {
+	wanted := map[uint]bool{}
+	for _, id := range bookingIDs {
+		wanted[id] = true
+	}
+	accepted := map[uint][]uint{}
+	for _, a := range r.attendees {
+		if a.Status == domain.AttendeeStatusAccepted && wanted[a.BookingID] {
+			accepted[a.BookingID] = append(accepted[a.BookingID], a.UserID)
+		}
+	}
+	return accepted, nil
+}
+
+func TestScheduleDueRemindsOrganiserAndAcceptedAttendees(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	bookings := &fakeBookingRepo{bookings: []domain.Booking{
+		{ID: 1, UserID: 10, Status: "approved", StartTime: now.Add(10 * time.Minute), EndTime: now.Add(70 * time.Minute)},
+		// ยังไม่อนุมัติ: ไม่เตือนใครเลย
+		{ID: 2, UserID: 20, Status: "pending", StartTime: now.Add(10 * time.Minute), EndTime: now.Add(70 * time.Minute)},
+	}}
+	attendees := &fakeAttendeeRepo{attendees: []domain.BookingAttendee{
+		{BookingID: 1, UserID: 11, Status: domain.AttendeeStatusAccepted},
+		{BookingID: 1, UserID: 12, Status: domain.AttendeeStatusInvited},
+		{BookingID: 1, UserID: 13, Status: domain.AttendeeStatusDeclined},
+		{BookingID: 1, UserID: 10, Status: domain.AttendeeStatusAccepted}, // ผู้จองอยู่ในรายชื่อด้วย: ต้องไม่ได้ซ้ำ
+		{BookingID: 2, UserID: 21, Status: domain.AttendeeStatusAccepted},
+	}}
+	outbox := &fakeOutboxRepo{}
+	settings := NewSettingService(newFakeSettingRepo(
+		domain.Setting{SettingName: "reminder_enabled", SettingValue: "true"},
+		domain.Setting{SettingName: "reminder_offsets", SettingValue: "1440,15"},
+	), fakeLogService{}, nil, "")
+	service := NewReminderService(bookings, attendees, outbox, settings)
+
+	created, err := service.ScheduleDue(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 2 * len(domain.NotificationChannels); created != want {
+		t.Fatalf("created %d messages, want %d (organiser + 1 accepted attendee, every channel)", created, want)
+	}
+	recipients := outbox.recipients()
+	if len(recipients) != 2 || !recipients[10] || !recipients[11] {
+		t.Fatalf("recipients = %v, want organiser 10 and accepted attendee 11", recipients)
+	}
+
+	// รอบถัดไป (หรือ instance อื่น) ต้องไม่ส่งซ้ำ
+	again, err := service.ScheduleDue(now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != 0 {
+		t.Fatalf("second run created %d messages, want 0", again)
+	}
+}
+
+func TestReminderRecipients(t *testing.T) {
+	got := reminderRecipients(&domain.Booking{UserID: 1}, []uint{2, 1, 3, 2})
+	want := []uint{1, 2, 3}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -10,11 +10,12 @@ import (
 type roomService struct {
 	repo       ports.RoomRepository
 	logService ports.LogService
+	events     ports.EventPublisher
 }
 
 // NewRoomService รับ Repository เข้ามาเพื่อใช้งานต่อ
-func NewRoomService(repo ports.RoomRepository, logService ports.LogService) ports.RoomService {
-	return &roomService{repo: repo, logService: logService}
+func NewRoomService(repo ports.RoomRepository, logService ports.LogService, events ports.EventPublisher) ports.RoomService {
+	return &roomService{repo: repo, logService: logService, events: events}
 }
 
 func (s *roomService) CreateRoom(room *domain.Room) error {
@@ -30,6 +31,7 @@ func (s *roomService) CreateRoom(room *domain.Room) error {
 
 	// Log
 	go s.logService.LogAction(0, "CREATE_ROOM", fmt.Sprintf("Created room: %s", room.RoomName), "", "")
+	go s.events.Publish(domain.WebhookEventRoomCreated, *room)
 	
 	return nil
 }
@@ -64,6 +66,7 @@ func (s *roomService) UpdateRoom(id uint, input *domain.Room) error {
 
 	// Log
 	go s.logService.LogAction(0, "UPDATE_ROOM", fmt.Sprintf("Updated room ID: %d", id), "", "")
+	go s.events.Publish(domain.WebhookEventRoomUpdated, *existingRoom)
 
 	return nil
 }
@@ -72,6 +75,7 @@ func (s *roomService) DeleteRoom(id uint) error {
 	err := s.repo.Delete(id)
 	if err == nil {
 		go s.logService.LogAction(0, "DELETE_ROOM", fmt.Sprintf("Deleted room ID: %d", id), "", "")
+		go s.events.Publish(domain.WebhookEventRoomDeleted, map[string]uint{"id": id})
 	}
 	return err
 }
\ No newline at end of file
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// encryptedPrefix นำหน้าค่าที่เข้ารหัสแล้วในฐานข้อมูล (v1 = AES-256-GCM)
+const encryptedPrefix = "enc:v1:"
+
+// secretBox เข้ารหัสค่าตั้งค่าลับด้วย AES-256-GCM
+// กุญแจได้จาก SHA-256 ของ SETTINGS_ENCRYPTION_KEY (ใช้สตริงสุ่มยาว ๆ เช่น openssl rand -base64 32)
+type secretBox struct {
+	aead cipher.AEAD
+}
+
+func newSecretBox(key string) (*secretBox, error) {
+	if key == "" {
+		return nil, errors.New("encryption key is empty")
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &secretBox{aead: aead}, nil
+}
+
+// seal: nonce สุ่มใหม่ทุกครั้ง เก็บไว้หน้าข้อความที่เข้ารหัส
+func (b *secretBox) seal(plain string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (b *secretBox) open(stored string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, encryptedPrefix))
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < b.aead.NonceSize() {
+		return "", errors.New("encrypted value is too short")
+	}
+	nonce, sealed := raw[:b.aead.NonceSize()], raw[b.aead.NonceSize():]
+	plain, err := b.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", errors.New("cannot decrypt value (wrong SETTINGS_ENCRYPTION_KEY?)")
+	}
+	return string(plain), nil
+}
+
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
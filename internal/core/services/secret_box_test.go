@@ -0,0 +1,83 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSecretBoxRoundTrip(t *testing.T) {
+	box, err := newSecretBox("test-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := box.seal("smtp-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEncrypted(sealed) || strings.Contains(sealed, "smtp-password") {
+		t.Fatalf("sealed value %q is not encrypted", sealed)
+	}
+
+	plain, err := box.open(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain != "smtp-password" {
+		t.Fatalf("got %q, want %q", plain, "smtp-password")
+	}
+}
+
+func TestSecretBoxUsesFreshNonce(t *testing.T) {
+	box, _ := newSecretBox("test-key")
+	first, _ := box.seal("same value")
+	second, _ := box.seal("same value")
+	if first == second {
+		t.Fatal("sealing the same value twice returned the same ciphertext")
+	}
+}
+
+func TestSecretBoxRejectsWrongKeyAndTampering(t *testing.T) {
+	box, _ := newSecretBox("test-key")
+	other, _ := newSecretBox("other-key")
+	sealed, _ := box.seal("value")
+
+	if _, err := other.open(sealed); err == nil {
+		t.Error("opened a value sealed with a different key")
+	}
+
+	// แก้ตัวอักษรสุดท้ายของ ciphertext: GCM ต้องตรวจพบ
+	tampered := sealed[:len(sealed)-2] + "AA"
+	if tampered == sealed {
+		tampered = sealed[:len(sealed)-2] + "BB"
+	}
+	if _, err := box.open(tampered); err == nil {
+		t.Error("opened a tampered value")
+	}
+
+	if _, err := box.open(encryptedPrefix + "AAAA"); err == nil {
+		t.Error("opened a value shorter than the nonce")
+	}
+}
+
+func TestNewSecretBoxRequiresKey(t *testing.T) {
+	if box, err := newSecretBox(""); err == nil || box != nil {
+		t.Fatal("empty key must return an error and no box")
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	for value, want := range map[string]bool{
+		"":                  false,
+		"plain":             false,
+		"enc:v1:":           true,
+		"enc:v1:abc":        true,
+		"enc:v2:abc":        false,
+		" enc:v1:abc":       false,
+		"prefix enc:v1:abc": false,
+	} {
+		if got := isEncrypted(value); got != want {
+			t.Errorf("isEncrypted(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
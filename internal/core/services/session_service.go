@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+type sessionService struct {
+	repo       ports.SessionRepository
+	tokens     ports.TokenService
+	userRepo   ports.UserRepository
+	logService ports.LogService
+}
+
+func NewSessionService(repo ports.SessionRepository, tokens ports.TokenService, userRepo ports.UserRepository, logService ports.LogService) ports.SessionService {
+	return &sessionService{repo: repo, tokens: tokens, userRepo: userRepo, logService: logService}
+}
+
+func (s *sessionService) GetSessions(userID uint, currentID string) ([]domain.Session, error) {
+	sessions, err := s.repo.GetActiveByUser(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+func (s *sessionService) Revoke(userID uint, sessionID string, client ports.ClientInfo) error {
+	session, err := s.repo.GetByID(sessionID)
+	if err != nil || session.UserID != userID || session.RevokedAt != nil {
+		return errors.New("session not found")
+	}
+	if err := s.tokens.RevokeSession(sessionID); err != nil {
+		return err
+	}
+
+	go s.logService.LogClientAction(userID, "SESSION_REVOKED", fmt.Sprintf("ออกจากระบบเครื่อง %s (%s)", session.Device, session.IPAddress), client)
+	return nil
+}
+
+func (s *sessionService) RevokeAll(userID, actorID uint, client ports.ClientInfo) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if err := s.tokens.RevokeUserTokens(userID); err != nil {
+		return err
+	}
+
+	go s.logService.LogClientAction(actorID, "FORCE_LOGOUT", fmt.Sprintf("Logged out all sessions of %s (ID: %d)", user.Username, userID), client)
+	return nil
+}
+
+// deviceName: สรุป User-Agent แบบคร่าว ๆ ให้ผู้ใช้จำเครื่องได้ (ไม่ต้องแม่นยำ)
+func deviceName(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	for _, b := range []struct{ token, name string }{
+		{"edg/", "Edge"},
+		{"opr/", "Opera"},
+		{"firefox/", "Firefox"},
+		{"chrome/", "Chrome"},
+		{"safari/", "Safari"},
+		{"curl/", "curl"},
+		{"python-requests", "Python"},
+		{"postman", "Postman"},
+	} {
+		if strings.Contains(ua, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	for _, o := range []struct{ token, name string }{
+		{"android", "Android"},
+		{"iphone", "iPhone"},
+		{"ipad", "iPad"},
+		{"windows", "Windows"},
+		{"mac os", "macOS"},
+		{"cros", "ChromeOS"},
+		{"linux", "Linux"},
+	} {
+		if strings.Contains(ua, o.token) {
+			return browser + " on " + o.name
+		}
+	}
+	return browser
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+// settingRule ข้อจำกัดเพิ่มเติมจาก Type ใน settingDefaults
+type settingRule struct {
+	Required bool
+	Options  []string
+	Min, Max *int
+	Pattern  string
+	Hint     string
+}
+
+const (
+	patternHexColor = `^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`
+	patternImage    = `^$|^(https?://|/)\S*$`
+	patternHTTPURL  = `^https?://[^\s/]+\S*$`
+)
+
+func intRange(min, max int) (*int, *int) {
+	return &min, &max
+}
+
+// settingRules ค่าที่ไม่อยู่ในนี้ตรวจตาม Type อย่างเดียว
+var settingRules = func() map[string]settingRule {
+	rules := map[string]settingRule{
+		"site_name":                      {Required: true},
+		"public_base_url":                {Required: true, Pattern: patternHTTPURL, Hint: "must start with http:// or https://"},
+		"default_language":               {Options: []string{domain.LanguageThai, domain.LanguageEnglish}},
+		"default_booking_status":         {Options: []string{"pending", "approved"}},
+		"two_factor_required_roles":      {Pattern: `^$|^\s*(admin|approver|user)\s*(,\s*(admin|approver|user)\s*)*$`, Hint: "comma separated roles: admin, approver, user"},
+		"register_allowed_email_domains": {Pattern: `^$|^\s*@?[\w.-]+\.[a-zA-Z]{2,}\s*(,\s*@?[\w.-]+\.[a-zA-Z]{2,}\s*)*$`, Hint: "comma separated domains, e.g. school.ac.th"},
+		"oidc_allowed_email_domains":     {Pattern: `^$|^\s*@?[\w.-]+\.[a-zA-Z]{2,}\s*(,\s*@?[\w.-]+\.[a-zA-Z]{2,}\s*)*$`, Hint: "comma separated domains, e.g. school.ac.th"},
+		"ldap_url":                       {Pattern: `^$|^ldaps?://[^\s/]+\S*$`, Hint: "must start with ldap:// or ldaps://"},
+		"oidc_issuer_url":                {Pattern: `^$|` + patternHTTPURL, Hint: "must start with http:// or https://"},
+		"oidc_redirect_url":              {Pattern: `^$|` + patternHTTPURL, Hint: "must start with http:// or https://"},
+		"popup_link":                     {Pattern: `^$|` + patternHTTPURL, Hint: "must start with http:// or https://"},
+		"reminder_offsets":               {Pattern: `^$|^\s*\d+\s*(,\s*\d+\s*)*$`, Hint: "comma separated minutes before start, e.g. 1440,15"},
+		"digest_time":                    {Pattern: `^([01]\d|2[0-3]):[0-5]\d$`, Hint: "HH:MM (24-hour)"},
+	}
+
+	ranges := map[string][2]int{
+		"email_verification_ttl_hours": {1, 720},
+		"advance_booking_days":         {0, 365},
+		"access_token_ttl_minutes":     {1, 1440},
+		"refresh_token_ttl_days":       {1, 365},
+		"impersonation_ttl_minutes":    {5, 480},
+		"api_key_max_days":             {0, 3650},
+		"login_lockout_threshold":      {0, 1000},
+		"login_ip_lockout_threshold":   {0, 100000},
+		"login_lockout_minutes":        {1, 1440},
+		"login_failure_window_minutes": {1, 1440},
+		"password_reset_ttl_minutes":   {5, 1440},
+		"ldap_timeout_seconds":         {1, 120},
+		"smtp_port":                    {1, 65535},
+		"digest_overdue_hours":         {1, 720},
+		"notify_max_attempts":          {1, 50},
+		"webhook_max_attempts":         {1, 50},
+	}
+	for name, r := range ranges {
+		rule := rules[name]
+		rule.Min, rule.Max = intRange(r[0], r[1])
+		rules[name] = rule
+	}
+	return rules
+}()
+
+func (s *settingService) GetSchema() []domain.SettingField {
+	fields := make([]domain.SettingField, 0, len(settingDefaults))
+	for _, d := range settingDefaults {
+		fields = append(fields, settingField(d))
+	}
+	return fields
+}
+
+func settingField(d domain.Setting) domain.SettingField {
+	rule := settingRules[d.SettingName]
+	field := domain.SettingField{
+		Name:        d.SettingName,
+		Group:       d.Group,
+		Type:        d.Type,
+		Label:       d.Label,
+		Description: d.Description,
+		Visibility:  d.Visibility,
+		Default:     d.SettingValue,
+		Required:    rule.Required,
+		Options:     rule.Options,
+		Min:         rule.Min,
+		Max:         rule.Max,
+		Pattern:     rule.Pattern,
+		Hint:        rule.Hint,
+	}
+	if field.Visibility == "" {
+		field.Visibility = domain.SettingVisibilityInternal
+	}
+	switch d.Type {
+	case "boolean":
+		field.Options = []string{"true", "false"}
+	case "color":
+		field.Pattern, field.Hint = patternHexColor, "hex color, e.g. #db2777"
+	case "image":
+		if field.Pattern == "" {
+			field.Pattern, field.Hint = patternImage, "image URL"
+		}
+	}
+	if field.Visibility == domain.SettingVisibilitySecret {
+		field.Default = ""
+	}
+	return field
+}
+
+// validateSettings คืน *ports.SettingValidationError รวมทุกช่องที่ผิด (ไม่หยุดที่ช่องแรก)
+func validateSettings(updates []domain.Setting) error {
+	fields := make(map[string]domain.SettingField, len(settingDefaults))
+	for _, d := range settingDefaults {
+		fields[d.SettingName] = settingField(d)
+	}
+
+	errs := map[string]string{}
+	for _, u := range updates {
+		field, ok := fields[u.SettingName]
+		if !ok {
+			errs[u.SettingName] = "unknown setting"
+			continue
+		}
+		// secret ค่าว่าง = ไม่เปลี่ยน
+		if field.Visibility == domain.SettingVisibilitySecret && (u.SettingValue == "" || u.Clear) {
+			continue
+		}
+		if msg := validateSettingValue(field, u.SettingValue); msg != "" {
+			errs[u.SettingName] = msg
+		}
+	}
+	if len(errs) > 0 {
+		return &ports.SettingValidationError{Fields: errs}
+	}
+	return nil
+}
+
+func validateSettingValue(field domain.SettingField, value string) string {
+	if strings.TrimSpace(value) == "" {
+		if field.Required {
+			return "is required"
+		}
+		if field.Type == "text" || field.Type == "image" || field.Type == "password" {
+			return ""
+		}
+	}
+
+	switch field.Type {
+	case "number":
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return "must be a whole number"
+		}
+		if field.Min != nil && n < *field.Min {
+			return fmt.Sprintf("must be at least %d", *field.Min)
+		}
+		if field.Max != nil && n > *field.Max {
+			return fmt.Sprintf("must be at most %d", *field.Max)
+		}
+		return ""
+	}
+
+	if len(field.Options) > 0 && !contains(field.Options, value) {
+		return "must be one of: " + strings.Join(field.Options, ", ")
+	}
+	if field.Pattern != "" && !regexp.MustCompile(field.Pattern).MatchString(value) {
+		if field.Hint != "" {
+			return field.Hint
+		}
+		return "has an invalid format"
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+// ค่าเริ่มต้นทุกค่าต้องผ่านกฎของตัวเอง ไม่งั้นผู้ดูแลกดบันทึกหน้าตั้งค่าโดยไม่แก้อะไรก็ไม่ผ่าน
+func TestSettingDefaultsPassTheirOwnRules(t *testing.T) {
+	seen := map[string]bool{}
+	for _, d := range settingDefaults {
+		if seen[d.SettingName] {
+			t.Errorf("%s is declared twice in settingDefaults", d.SettingName)
+		}
+		seen[d.SettingName] = true
+
+		field := settingField(d)
+		if field.Pattern != "" {
+			if _, err := regexp.Compile(field.Pattern); err != nil {
+				t.Errorf("%s: invalid pattern: %v", d.SettingName, err)
+			}
+		}
+		if field.Visibility == domain.SettingVisibilitySecret {
+			continue
+		}
+		if msg := validateSettingValue(field, d.SettingValue); msg != "" {
+			t.Errorf("default %s=%q fails validation: %s", d.SettingName, d.SettingValue, msg)
+		}
+	}
+
+	// กฎที่ไม่มีค่าตั้งค่ารองรับ = พิมพ์ชื่อผิด
+	for name := range settingRules {
+		if !seen[name] {
+			t.Errorf("settingRules has %s, which is not in settingDefaults", name)
+		}
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	tests := []struct {
+		name, value string
+		valid       bool
+	}{
+		{"site_name", "Booking", true},
+		{"site_name", "  ", false}, // required
+		{"smtp_port", "587", true},
+		{"smtp_port", "0", false},
+		{"smtp_port", "70000", false},
+		{"smtp_port", "58a", false},
+		{"theme_color", "#db2777", true},
+		{"theme_color", "#FFF", true},
+		{"theme_color", "pink", false},
+		{"default_language", "en", true},
+		{"default_language", "fr", false},
+		{"public_base_url", "https://booking.example.ac.th", true},
+		{"public_base_url", "booking.example.ac.th", false},
+		{"register_allowed_email_domains", "", true},
+		{"register_allowed_email_domains", "tu.ac.th, @dome.tu.ac.th", true},
+		{"register_allowed_email_domains", "not a domain", false},
+		{"two_factor_required_roles", "admin,approver", true},
+		{"two_factor_required_roles", "root", false},
+		{"reminder_offsets", "1440, 15", true},
+		{"reminder_offsets", "1 day", false},
+		{"digest_time", "08:30", true},
+		{"digest_time", "24:00", false},
+		{"ldap_url", "ldaps://ldap.example.ac.th:636", true},
+		{"ldap_url", "https://ldap.example.ac.th", false},
+		// secret ว่าง = ไม่เปลี่ยน
+		{"ldap_bind_password", "", true},
+	}
+	for _, tt := range tests {
+		err := validateSettings([]domain.Setting{{SettingName: tt.name, SettingValue: tt.value}})
+		if tt.valid && err != nil {
+			t.Errorf("%s=%q: unexpected error %v", tt.name, tt.value, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s=%q: expected a validation error", tt.name, tt.value)
+		}
+	}
+}
+
+func TestValidateSettingsReportsEveryField(t *testing.T) {
+	err := validateSettings([]domain.Setting{
+		{SettingName: "smtp_port", SettingValue: "abc"},
+		{SettingName: "theme_color", SettingValue: "pink"},
+		{SettingName: "no_such_setting", SettingValue: "x"},
+		{SettingName: "site_name", SettingValue: "ok"},
+	})
+
+	var invalid *ports.SettingValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("got %v, want *ports.SettingValidationError", err)
+	}
+	want := map[string]string{
+		"smtp_port":       "must be a whole number",
+		"theme_color":     "hex color, e.g. #db2777",
+		"no_such_setting": "unknown setting",
+	}
+	if len(invalid.Fields) != len(want) {
+		t.Fatalf("fields = %v, want %v", invalid.Fields, want)
+	}
+	for name, msg := range want {
+		if invalid.Fields[name] != msg {
+			t.Errorf("%s: got %q, want %q", name, invalid.Fields[name], msg)
+		}
+	}
+}
+
+func TestGetSchemaHidesSecretDefaults(t *testing.T) {
+	service := NewSettingService(newFakeSettingRepo(), fakeLogService{}, nil, "")
+	for _, field := range service.GetSchema() {
+		if field.Visibility == domain.SettingVisibilitySecret && field.Default != "" {
+			t.Errorf("schema exposes the default of secret %s", field.Name)
+		}
+		if field.Type == "boolean" && len(field.Options) != 2 {
+			t.Errorf("boolean %s has options %v", field.Name, field.Options)
+		}
+	}
+}
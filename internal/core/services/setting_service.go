@@ -1,7 +1,12 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"sync"
 	"tunorth-brms-backend/internal/core/domain"
 	"tunorth-brms-backend/internal/core/ports"
 )
@@ -9,90 +14,476 @@ import (
 type settingService struct {
 	repo       ports.SettingRepository
 	logService ports.LogService
+	box        *secretBox             // nil = ไม่ได้ตั้ง SETTINGS_ENCRYPTION_KEY (แก้ค่า secret ไม่ได้)
+	bus        ports.SettingChangeBus // nil = instance เดียว ไม่ต้องแจ้งใคร
+
+	// cache ของค่าตั้งค่าทั้งหมด โหลดจาก Database ครั้งแรกที่ใช้ และล้างทิ้งเมื่อมีการเปลี่ยนค่า
+	mu          sync.RWMutex
+	settings    []domain.Setting  // ตามที่เก็บใน Database (secret ยังเข้ารหัสอยู่)
+	values      map[string]string // ค่าจริงที่ถอดรหัสแล้ว nil = ต้องโหลดใหม่
+	subscribers []ports.SettingChangeFunc
 }
 
-func NewSettingService(repo ports.SettingRepository, logService ports.LogService) ports.SettingService {
-	return &settingService{repo: repo, logService: logService}
+func NewSettingService(repo ports.SettingRepository, logService ports.LogService, bus ports.SettingChangeBus, encryptionKey string) ports.SettingService {
+	box, err := newSecretBox(encryptionKey)
+	if err != nil {
+		log.Println("Warning: SETTINGS_ENCRYPTION_KEY is not set, secret settings cannot be changed until it is set")
+	}
+	return &settingService{repo: repo, logService: logService, bus: bus, box: box}
 }
 
-func (s *settingService) GetAllSettings() (map[string]interface{}, []domain.Setting, error) {
-	settings, err := s.repo.GetAll()
+func (s *settingService) GetAllSettings() ([]domain.Setting, error) {
+	cached, _, err := s.snapshot()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	settings := make([]domain.Setting, len(cached))
+	copy(settings, cached)
 
-	resultMap := make(map[string]interface{})
-	for _, setting := range settings {
-		resultMap[setting.SettingName] = setting.SettingValue
+	// ค่า secret ไม่ออกจาก Backend: แจ้งแค่ว่าตั้งค่าแล้วหรือยัง
+	for i := range settings {
+		if settings[i].Visibility == domain.SettingVisibilitySecret {
+			settings[i].IsSet = settings[i].SettingValue != ""
+			settings[i].SettingValue = ""
+		}
+	}
+	return settings, nil
+}
+
+func (s *settingService) GetPublicSettings() (map[string]string, error) {
+	settings, _, err := s.snapshot()
+	if err != nil {
+		return nil, err
 	}
 
-	return resultMap, settings, nil
+	result := make(map[string]string)
+	for _, setting := range settings {
+		if setting.Visibility == domain.SettingVisibilityPublic {
+			result[setting.SettingName] = setting.SettingValue
+		}
+	}
+	return result, nil
 }
 
+// UpdateSettings: secret ที่ส่งค่าว่างมา = ไม่เปลี่ยน (หน้าเว็บไม่มีค่าเดิมให้ส่งกลับ) ล้างค่าด้วย clear: true
 func (s *settingService) UpdateSettings(updates []domain.Setting, actorID uint) error {
-	if err := s.repo.UpdateBatch(updates); err != nil {
+	if err := validateSettings(updates); err != nil {
+		return err
+	}
+
+	current, values, err := s.snapshot()
+	if err != nil {
+		return err
+	}
+	visibility := make(map[string]string, len(current))
+	for _, setting := range current {
+		visibility[setting.SettingName] = setting.Visibility
+	}
+
+	changes := make([]domain.Setting, 0, len(updates))
+	for _, u := range updates {
+		if visibility[u.SettingName] == domain.SettingVisibilitySecret {
+			// ciphertext ใหม่ทุกครั้ง: เทียบค่าจริงที่นี่ ไม่งั้น Repository เห็นว่าเปลี่ยนทุกครั้งที่กดบันทึก
+			if u.Clear {
+				u.SettingValue = ""
+			} else if u.SettingValue == "" || u.SettingValue == values[u.SettingName] {
+				continue
+			} else if u.SettingValue, err = s.encrypt(u.SettingValue); err != nil {
+				return err
+			}
+		}
+		changes = append(changes, u)
+	}
+
+	changeSet := &domain.SettingChangeSet{ActorID: actorID, Source: domain.SettingChangeSourceUpdate}
+	if err := s.apply(changes, changeSet); err != nil {
+		return err
+	}
+	if len(changeSet.Changes) > 0 {
+		go s.logService.LogAction(actorID, "UPDATE_SETTINGS", "Updated "+describeChangeSet(changeSet), "", "")
+	}
+	return nil
+}
+
+func (s *settingService) GetHistory(settingName string, limit int) ([]domain.SettingChangeSet, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	sets, err := s.repo.GetHistory(settingName, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sets {
+		maskChangeSet(&sets[i])
+	}
+	return sets, nil
+}
+
+// Rollback: ค่าเดิมของชุดที่เลือกถูกบันทึกทับค่าปัจจุบัน (รวมถึงค่าที่ถูกแก้ต่อจากชุดนั้นแล้ว)
+func (s *settingService) Rollback(changeSetID uint, settingName string, actorID uint) (*domain.SettingChangeSet, error) {
+	target, err := s.repo.GetChangeSet(changeSetID)
+	if err != nil {
+		return nil, errors.New("change set not found")
+	}
+
+	current, values, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	visibility := make(map[string]string, len(current))
+	for _, setting := range current {
+		visibility[setting.SettingName] = setting.Visibility
+	}
+
+	var restore, check []domain.Setting
+	matched := false
+	for _, change := range target.Changes {
+		if settingName != "" && change.SettingName != settingName {
+			continue
+		}
+		matched = true
+		setting := domain.Setting{SettingName: change.SettingName, SettingValue: change.OldValue}
+		if visibility[change.SettingName] == domain.SettingVisibilitySecret {
+			// ค่าจริงเท่าค่าปัจจุบันแล้ว (ciphertext ต่างกันได้): ไม่ต้องบันทึก
+			plain := setting.SettingValue
+			if isEncrypted(plain) {
+				plain = s.decrypt(&setting)
+			}
+			if plain == values[change.SettingName] {
+				continue
+			}
+			// ค่าจากก่อนเปิดการเข้ารหัส: เข้ารหัสก่อนบันทึกกลับ
+			if !isEncrypted(setting.SettingValue) {
+				if setting.SettingValue, err = s.encrypt(setting.SettingValue); err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			// ค่าเดิมอาจไม่ผ่านข้อกำหนดปัจจุบันแล้ว
+			check = append(check, setting)
+		}
+		restore = append(restore, setting)
+	}
+	if !matched {
+		return nil, fmt.Errorf("setting %s was not changed in change set #%d", settingName, changeSetID)
+	}
+	if err := validateSettings(check); err != nil {
+		return nil, err
+	}
+
+	rollback := &domain.SettingChangeSet{ActorID: actorID, Source: domain.SettingChangeSourceRollback, RollbackOf: &target.ID}
+	if err := s.apply(restore, rollback); err != nil {
+		return nil, err
+	}
+	if len(rollback.Changes) > 0 {
+		go s.logService.LogAction(actorID, "ROLLBACK_SETTINGS", fmt.Sprintf("Rolled back change set #%d: %s", target.ID, describeChangeSet(rollback)), "", "")
+	}
+	maskChangeSet(rollback)
+	return rollback, nil
+}
+
+// apply บันทึกค่าพร้อมประวัติ แล้วล้าง cache เฉพาะเมื่อมีค่าเปลี่ยนจริง
+func (s *settingService) apply(settings []domain.Setting, changeSet *domain.SettingChangeSet) error {
+	if err := s.repo.UpdateBatch(settings, changeSet); err != nil {
 		return err
 	}
-	go s.logService.LogAction(actorID, "UPDATE_SETTINGS", fmt.Sprintf("Updated %d settings", len(updates)), "", "")
+	if len(changeSet.Changes) == 0 {
+		return nil
+	}
+	keys := make([]string, len(changeSet.Changes))
+	for i, change := range changeSet.Changes {
+		keys[i] = change.SettingName
+	}
+	s.changed(keys)
 	return nil
 }
 
+// describeChangeSet ข้อความสำหรับ Log เช่น "3 settings (change set #12, secrets: smtp_password)"
+func describeChangeSet(set *domain.SettingChangeSet) string {
+	var secrets []string
+	for _, change := range set.Changes {
+		if change.Secret {
+			secrets = append(secrets, change.SettingName)
+		}
+	}
+	description := fmt.Sprintf("%d settings (change set #%d", len(set.Changes), set.ID)
+	if len(secrets) > 0 {
+		description += ", secrets: " + strings.Join(secrets, ", ")
+	}
+	return description + ")"
+}
+
+// maskChangeSet ค่า secret ไม่ออกจาก Backend แม้จะเป็นค่าที่เข้ารหัสแล้ว
+func maskChangeSet(set *domain.SettingChangeSet) {
+	for i := range set.Changes {
+		change := &set.Changes[i]
+		if !change.Secret {
+			continue
+		}
+		if change.OldValue != "" {
+			change.OldValue = domain.SettingSecretMask
+		}
+		if change.NewValue != "" {
+			change.NewValue = domain.SettingSecretMask
+		}
+	}
+}
+
 func (s *settingService) GetSettingValue(key string) string {
-	setting, err := s.repo.GetByName(key)
+	_, values, err := s.snapshot()
+	if err != nil {
+		return ""
+	}
+	return values[key]
+}
+
+func (s *settingService) Subscribe(fn ports.SettingChangeFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Start: รับการแจ้งเปลี่ยนค่าจาก instance อื่น แล้วล้าง cache ของ instance นี้
+func (s *settingService) Start(ctx context.Context) {
+	if s.bus == nil {
+		return
+	}
+	s.bus.Listen(ctx, s.invalidate)
+}
+
+// snapshot คืนค่าตั้งค่าจาก cache (โหลดจาก Database ถ้ายังไม่มี) ผู้เรียกห้ามแก้ไขสิ่งที่ได้รับ
+func (s *settingService) snapshot() ([]domain.Setting, map[string]string, error) {
+	s.mu.RLock()
+	settings, values := s.settings, s.values
+	s.mu.RUnlock()
+	if values != nil {
+		return settings, values, nil
+	}
+
+	// ถือ lock ระหว่างโหลด: request ที่เข้ามาพร้อมกันรอผลเดียวกัน และ invalidate ที่ตามมาจะไม่ถูกทับด้วยค่าเก่า
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values != nil {
+		return s.settings, s.values, nil
+	}
+	settings, err := s.repo.GetAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	values = make(map[string]string, len(settings))
+	for i := range settings {
+		values[settings[i].SettingName] = settings[i].SettingValue
+		if isEncrypted(settings[i].SettingValue) {
+			values[settings[i].SettingName] = s.decrypt(&settings[i])
+		}
+	}
+	s.settings, s.values = settings, values
+	return settings, values, nil
+}
+
+func (s *settingService) Refresh(keys []string) {
+	s.changed(keys)
+}
+
+// changed: ค่าใน Database เปลี่ยนจาก instance นี้ ล้าง cache ของตัวเองแล้วแจ้ง instance อื่น
+func (s *settingService) changed(keys []string) {
+	s.invalidate(keys)
+	if s.bus == nil {
+		return
+	}
+	if err := s.bus.Publish(keys); err != nil {
+		log.Println("Failed to publish settings change:", err)
+	}
+}
+
+// invalidate ล้าง cache และแจ้งผู้ที่ Subscribe ไว้ (keys = nil คือทุกค่า)
+func (s *settingService) invalidate(keys []string) {
+	s.mu.Lock()
+	s.settings, s.values = nil, nil
+	subscribers := append([]ports.SettingChangeFunc(nil), s.subscribers...)
+	s.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(keys)
+	}
+}
+
+// encrypt: ไม่มีกุญแจ = ปฏิเสธ (ล้างค่าได้)
+func (s *settingService) encrypt(value string) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+	if s.box == nil {
+		return "", ports.ErrSettingsEncryptionKeyMissing
+	}
+	return s.box.seal(value)
+}
+
+func (s *settingService) decrypt(setting *domain.Setting) string {
+	if s.box == nil {
+		log.Printf("Setting %s is encrypted but SETTINGS_ENCRYPTION_KEY is not set", setting.SettingName)
+		return ""
+	}
+	value, err := s.box.open(setting.SettingValue)
 	if err != nil {
+		log.Printf("Setting %s: %v", setting.SettingName, err)
 		return ""
 	}
-	return setting.SettingValue
+	return value
+}
+
+// settingDefaults ค่าเริ่มต้นของทุกค่าตั้งค่า และเป็นทะเบียนของ schema (ข้อจำกัดเพิ่มเติมอยู่ใน setting_schema.go)
+var settingDefaults = []domain.Setting{
+	// General
+	{SettingName: "site_name", SettingValue: "TUNorth-BRMS", Group: "general", Type: "text", Label: "ระบบชื่อ", Description: "ชื่อระบบที่แสดงบนแถบ Title", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "site_description", SettingValue: "ระบบจองห้องประชุมออนไลน์", Group: "general", Type: "text", Label: "คำอธิบายระบบ", Description: "คำอธิบายสั้นๆ เกี่ยวกับระบบ", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "copyright_text", SettingValue: "© 2026 Triam Udom Suksa", Group: "general", Type: "text", Label: "ข้อความลิขสิทธิ์", Description: "ข้อความ Footer", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "institute_name", SettingValue: "Triam Udom Suksa", Group: "general", Type: "text", Label: "ชื่อสถาบัน", Description: "ชื่อสถาบันต้นสังกัด", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "public_base_url", SettingValue: "http://127.0.0.1:3000", Group: "general", Type: "text", Label: "URL หน้าเว็บ", Description: "ใช้สร้างลิงก์ในข้อความแจ้งเตือน เช่น https://brms.example.com", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "default_language", SettingValue: "th", Group: "general", Type: "select", Label: "ภาษาเริ่มต้น", Description: "th หรือ en (ใช้กับข้อความแจ้งเตือนเข้ากลุ่ม)", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "enable_register", SettingValue: "true", Group: "general", Type: "boolean", Label: "เปิดรับสมัครสมาชิก", Description: "เปิด/ปิด การลงทะเบียนสมัครสมาชิกใหม่", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "register_require_email_verification", SettingValue: "true", Group: "general", Type: "boolean", Label: "ต้องยืนยันอีเมลก่อนใช้งาน", Description: "ผู้สมัครต้องกดลิงก์ในอีเมลก่อนจึงจะ Login ได้", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "register_allowed_email_domains", SettingValue: "", Group: "general", Type: "text", Label: "โดเมนอีเมลที่สมัครได้", Description: "คั่นด้วยจุลภาค เช่น example.ac.th (ว่าง = ทุกโดเมน)", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "register_require_admin_approval", SettingValue: "false", Group: "general", Type: "boolean", Label: "ต้องรอผู้ดูแลอนุมัติบัญชี", Description: "บัญชีที่สมัครเองจะอยู่ในสถานะรออนุมัติจนกว่าผู้ดูแลจะเปิดใช้งาน", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "two_factor_required_roles", SettingValue: "", Group: "security", Type: "text", Label: "บังคับใช้ 2FA สำหรับ role", Description: "คั่นด้วยจุลภาค เช่น admin,approver (ยังไม่ได้ตั้งค่าจะต้องตั้งค่าตอน Login)"},
+	{SettingName: "email_verification_ttl_hours", SettingValue: "48", Group: "security", Type: "number", Label: "อายุลิงก์ยืนยันอีเมล (ชั่วโมง)", Description: "ลิงก์ที่ส่งทางอีเมลใช้ได้ครั้งเดียวภายในเวลานี้"},
+
+	// Images
+	{SettingName: "site_logo", SettingValue: "", Group: "images", Type: "image", Label: "โลโก้เว็บไซต์", Description: "รูปภาพโลโก้หลัก (PNG/JPG)", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "favicon", SettingValue: "", Group: "images", Type: "image", Label: "Favicon", Description: "ไอคอนบน Tab Browser", Visibility: domain.SettingVisibilityPublic},
+
+	// Theme
+	{SettingName: "theme_color", SettingValue: "#db2777", Group: "theme", Type: "color", Label: "สีธีมหลัก", Description: "สีหลักของปุ่มและ Highlight (Default: Pink-600)", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "theme_color_secondary", SettingValue: "#be185d", Group: "theme", Type: "color", Label: "สีธีมรอง (Hover / Highlight)", Description: "สีเมื่อเอาเมาส์ไปชี้ปุ่ม และสีไฮไลท์ข้อความ", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "bg_color_start", SettingValue: "#f8fafc", Group: "theme", Type: "color", Label: "สีพื้นหลัง (เริ่ม)", Description: "Gradient Start", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "bg_color_end", SettingValue: "#f1f5f9", Group: "theme", Type: "color", Label: "สีพื้นหลัง (จบ)", Description: "Gradient End", Visibility: domain.SettingVisibilityPublic},
+
+	// Booking
+	{SettingName: "default_booking_status", SettingValue: "pending", Group: "booking", Type: "select", Label: "สถานะเริ่มต้น", Description: "pending หรือ approved", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "advance_booking_days", SettingValue: "1", Group: "booking", Type: "number", Label: "จองล่วงหน้าอย่างน้อย (วัน)", Description: "จำนวนวันที่ต้องจองล่วงหน้า", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "allow_weekend", SettingValue: "false", Group: "booking", Type: "boolean", Label: "อนุญาตให้จองเสาร์-อาทิตย์", Description: "เปิด/ปิด การจองในวันหยุด", Visibility: domain.SettingVisibilityPublic},
+
+	// Telegram
+	{SettingName: "telegram_bot_token", SettingValue: "", Group: "telegram", Type: "password", Label: "Telegram Bot Token", Description: "Token จาก BotFather", Visibility: domain.SettingVisibilitySecret},
+	{SettingName: "telegram_admin_chat_id", SettingValue: "", Group: "telegram", Type: "text", Label: "Admin Chat ID", Description: "Group ID สำหรับแอดมิน"},
+	{SettingName: "telegram_user_chat_id", SettingValue: "", Group: "telegram", Type: "text", Label: "User Chat ID", Description: "Group ID สำหรับแจ้งเตือนทั่วไป"},
+
+	// Security
+	{SettingName: "access_token_ttl_minutes", SettingValue: "15", Group: "security", Type: "number", Label: "อายุ Access Token (นาที)", Description: "หมดอายุแล้ว Frontend ต้องใช้ Refresh Token ขอใหม่"},
+	{SettingName: "refresh_token_ttl_days", SettingValue: "30", Group: "security", Type: "number", Label: "อายุ Refresh Token (วัน)", Description: "ไม่ได้ใช้งานนานเกินนี้ต้อง Login ใหม่"},
+	{SettingName: "impersonation_enabled", SettingValue: "true", Group: "security", Type: "boolean", Label: "อนุญาตให้ผู้ดูแลสวมสิทธิ์ผู้ใช้", Description: "ใช้ช่วยเหลือผู้ใช้ ทุก Request ระหว่างสวมสิทธิ์ถูกบันทึกพร้อมชื่อผู้ดูแล"},
+	{SettingName: "impersonation_ttl_minutes", SettingValue: "60", Group: "security", Type: "number", Label: "อายุการสวมสิทธิ์ (นาที)", Description: "หมดเวลาแล้วต้องเริ่มใหม่ (ไม่มี Refresh Token)"},
+	{SettingName: "api_keys_enabled", SettingValue: "true", Group: "security", Type: "boolean", Label: "เปิดใช้ API Key", Description: "ผู้ใช้สร้าง API Key ให้สคริปต์เรียก API แทนการ Login ได้ (ปิดแล้ว Key เดิมใช้ไม่ได้)"},
+	{SettingName: "api_key_max_days", SettingValue: "365", Group: "security", Type: "number", Label: "อายุสูงสุดของ API Key (วัน)", Description: "0 = ไม่จำกัด"},
+	{SettingName: "login_lockout_threshold", SettingValue: "10", Group: "security", Type: "number", Label: "ล็อกบัญชีเมื่อ Login ผิดติดกัน (ครั้ง)", Description: "ตั้งแต่ครั้งที่ 3 จะหน่วงเวลาก่อน Login ใหม่ได้ 1, 2, 4 ... วินาที (0 = ไม่ล็อก)"},
+	{SettingName: "login_ip_lockout_threshold", SettingValue: "50", Group: "security", Type: "number", Label: "ล็อก IP เมื่อ Login ผิด (ครั้ง)", Description: "นับทุกบัญชีจาก IP เดียวกัน (0 = ไม่ล็อก)"},
+	{SettingName: "login_lockout_minutes", SettingValue: "15", Group: "security", Type: "number", Label: "ระยะเวลาล็อก (นาที)", Description: "ผู้ดูแลปลดล็อกก่อนกำหนดได้ที่เมนูผู้ใช้"},
+	{SettingName: "login_failure_window_minutes", SettingValue: "15", Group: "security", Type: "number", Label: "ช่วงเวลานับ Login ผิด (นาที)", Description: "ไม่มี Login ผิดนานเกินนี้จะเริ่มนับใหม่"},
+	{SettingName: "password_reset_ttl_minutes", SettingValue: "30", Group: "security", Type: "number", Label: "อายุลิงก์ตั้งรหัสผ่านใหม่ (นาที)", Description: "ลิงก์ที่ส่งทางอีเมลใช้ได้ครั้งเดียวภายในเวลานี้"},
+
+	// LDAP / Active Directory (บัญชี local เช่น admin เริ่มต้น ยังใช้รหัสผ่านในระบบได้เสมอ)
+	{SettingName: "ldap_enabled", SettingValue: "false", Group: "ldap", Type: "boolean", Label: "เปิดใช้ LDAP / Active Directory", Description: "ผู้ใช้ที่ไม่มีบัญชีในระบบจะตรวจรหัสผ่านกับ Directory และสร้างบัญชีให้อัตโนมัติ", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "ldap_url", SettingValue: "", Group: "ldap", Type: "text", Label: "LDAP URL", Description: "เช่น ldaps://dc.example.ac.th:636 หรือ ldap://localhost:389"},
+	{SettingName: "ldap_start_tls", SettingValue: "false", Group: "ldap", Type: "boolean", Label: "ใช้ StartTLS", Description: "อัปเกรดการเชื่อมต่อ ldap:// เป็น TLS"},
+	{SettingName: "ldap_insecure_skip_verify", SettingValue: "false", Group: "ldap", Type: "boolean", Label: "ไม่ตรวจใบรับรอง TLS", Description: "ใช้เฉพาะตอนทดสอบเท่านั้น"},
+	{SettingName: "ldap_ca_cert", SettingValue: "", Group: "ldap", Type: "text", Label: "CA Certificate (PEM)", Description: "ใบรับรองของ CA ภายในองค์กร (ว่าง = ใช้ของระบบปฏิบัติการ)"},
+	{SettingName: "ldap_bind_dn", SettingValue: "", Group: "ldap", Type: "text", Label: "Bind DN", Description: "บัญชีบริการสำหรับค้นหาผู้ใช้ เช่น CN=brms,OU=Service,DC=example,DC=ac,DC=th"},
+	{SettingName: "ldap_bind_password", SettingValue: "", Group: "ldap", Type: "password", Label: "Bind Password", Description: "รหัสผ่านของบัญชีบริการ", Visibility: domain.SettingVisibilitySecret},
+	{SettingName: "ldap_base_dn", SettingValue: "", Group: "ldap", Type: "text", Label: "Base DN", Description: "เช่น DC=example,DC=ac,DC=th"},
+	{SettingName: "ldap_user_filter", SettingValue: "(&(objectClass=person)(|(sAMAccountName={username})(userPrincipalName={username})(mail={username})))", Group: "ldap", Type: "text", Label: "User Search Filter", Description: "{username} จะถูกแทนด้วยชื่อที่กรอกตอน Login"},
+	{SettingName: "ldap_attr_username", SettingValue: "sAMAccountName", Group: "ldap", Type: "text", Label: "Attribute ชื่อผู้ใช้", Description: "OpenLDAP ใช้ uid"},
+	{SettingName: "ldap_attr_full_name", SettingValue: "displayName", Group: "ldap", Type: "text", Label: "Attribute ชื่อ-นามสกุล", Description: "ถ้าไม่มีจะใช้ cn"},
+	{SettingName: "ldap_attr_department", SettingValue: "department", Group: "ldap", Type: "text", Label: "Attribute หน่วยงาน", Description: "OpenLDAP มักใช้ ou"},
+	{SettingName: "ldap_attr_email", SettingValue: "mail", Group: "ldap", Type: "text", Label: "Attribute อีเมล", Description: ""},
+	{SettingName: "ldap_attr_groups", SettingValue: "memberOf", Group: "ldap", Type: "text", Label: "Attribute กลุ่ม", Description: "รายชื่อกลุ่มของผู้ใช้ (DN)"},
+	{SettingName: "ldap_admin_groups", SettingValue: "", Group: "ldap", Type: "text", Label: "กลุ่มที่เป็น admin", Description: "DN หรือชื่อ CN คั่นด้วย ; (role จะถูกปรับตามกลุ่มทุกครั้งที่ Login)"},
+	{SettingName: "ldap_approver_groups", SettingValue: "", Group: "ldap", Type: "text", Label: "กลุ่มที่เป็น approver", Description: "DN หรือชื่อ CN คั่นด้วย ; (นอกนั้นเป็น user)"},
+	{SettingName: "ldap_allowed_groups", SettingValue: "", Group: "ldap", Type: "text", Label: "กลุ่มที่อนุญาตให้ใช้ระบบ", Description: "DN หรือชื่อ CN คั่นด้วย ; (ว่าง = ทุกคนใน Directory)"},
+	{SettingName: "ldap_timeout_seconds", SettingValue: "10", Group: "ldap", Type: "number", Label: "Timeout (วินาที)", Description: "เวลารอเซิร์ฟเวอร์ LDAP สูงสุด"},
+
+	// OpenID Connect SSO (Google Workspace / Microsoft 365)
+	{SettingName: "oidc_enabled", SettingValue: "false", Group: "oidc", Type: "boolean", Label: "เปิดใช้ Single Sign-On (OIDC)", Description: "แสดงปุ่ม Login ผ่านผู้ให้บริการ (Frontend เรียก GET /api/oidc/login)", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "oidc_provider_name", SettingValue: "Google", Group: "oidc", Type: "text", Label: "ชื่อผู้ให้บริการ", Description: "ข้อความบนปุ่ม Login", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "oidc_issuer_url", SettingValue: "", Group: "oidc", Type: "text", Label: "Issuer URL", Description: "เช่น https://accounts.google.com หรือ https://login.microsoftonline.com/<tenant-id>/v2.0"},
+	{SettingName: "oidc_client_id", SettingValue: "", Group: "oidc", Type: "text", Label: "Client ID", Description: ""},
+	{SettingName: "oidc_client_secret", SettingValue: "", Group: "oidc", Type: "password", Label: "Client Secret", Description: "", Visibility: domain.SettingVisibilitySecret},
+	{SettingName: "oidc_redirect_url", SettingValue: "", Group: "oidc", Type: "text", Label: "Redirect URL", Description: "URL ของ API เช่น https://api.example.com/api/oidc/callback (ต้องลงทะเบียนไว้ที่ผู้ให้บริการ)"},
+	{SettingName: "oidc_scopes", SettingValue: "openid email profile", Group: "oidc", Type: "text", Label: "Scopes", Description: "คั่นด้วยช่องว่าง"},
+	{SettingName: "oidc_email_claim", SettingValue: "email", Group: "oidc", Type: "text", Label: "Claim อีเมล", Description: "ใช้ผูกกับบัญชีในระบบ"},
+	{SettingName: "oidc_name_claim", SettingValue: "name", Group: "oidc", Type: "text", Label: "Claim ชื่อ-นามสกุล", Description: ""},
+	{SettingName: "oidc_allowed_email_domains", SettingValue: "", Group: "oidc", Type: "text", Label: "โดเมนอีเมลที่อนุญาต", Description: "คั่นด้วยจุลภาค (ว่าง = ทุกโดเมน)"},
+	{SettingName: "oidc_auto_provision", SettingValue: "true", Group: "oidc", Type: "boolean", Label: "สร้างบัญชีอัตโนมัติ", Description: "ไม่พบบัญชีที่มีอีเมลนี้ให้สร้างใหม่ (ปิด = ต้องมีบัญชีอยู่ก่อน)"},
+	{SettingName: "oidc_role_claim", SettingValue: "", Group: "oidc", Type: "text", Label: "Claim สำหรับกำหนด role", Description: "เช่น groups หรือ roles (ใช้กับบัญชีที่สร้างจาก SSO เท่านั้น)"},
+	{SettingName: "oidc_admin_values", SettingValue: "", Group: "oidc", Type: "text", Label: "ค่าใน claim ที่เป็น admin", Description: "คั่นด้วยจุลภาค"},
+	{SettingName: "oidc_approver_values", SettingValue: "", Group: "oidc", Type: "text", Label: "ค่าใน claim ที่เป็น approver", Description: "คั่นด้วยจุลภาค (นอกนั้นเป็น user)"},
+
+	// Email (SMTP)
+	{SettingName: "smtp_host", SettingValue: "", Group: "email", Type: "text", Label: "SMTP Host", Description: "เช่น smtp.gmail.com"},
+	{SettingName: "smtp_port", SettingValue: "587", Group: "email", Type: "number", Label: "SMTP Port", Description: "ปกติ 587 (STARTTLS)"},
+	{SettingName: "smtp_username", SettingValue: "", Group: "email", Type: "text", Label: "SMTP Username", Description: "ชื่อผู้ใช้สำหรับส่งอีเมล"},
+	{SettingName: "smtp_password", SettingValue: "", Group: "email", Type: "password", Label: "SMTP Password", Description: "รหัสผ่าน / App Password", Visibility: domain.SettingVisibilitySecret},
+	{SettingName: "smtp_from", SettingValue: "", Group: "email", Type: "text", Label: "อีเมลผู้ส่ง", Description: "เช่น noreply@example.com"},
+
+	// LINE
+	{SettingName: "line_channel_access_token", SettingValue: "", Group: "line", Type: "password", Label: "LINE Channel Access Token", Description: "จาก LINE Developers (Messaging API)", Visibility: domain.SettingVisibilitySecret},
+
+	// Notifications
+	{SettingName: "notify_admin", SettingValue: "true", Group: "notification", Type: "boolean", Label: "แจ้งเตือนแอดมิน", Description: "ส่งเข้า Group แอดมินใน Telegram เมื่อมีการจองใหม่/ยกเลิก (รายบุคคลตั้งค่าที่โปรไฟล์ผู้ใช้)"},
+	{SettingName: "notify_user", SettingValue: "true", Group: "notification", Type: "boolean", Label: "แจ้งเตือนผู้ใช้", Description: "ส่งเข้า Group ผู้ใช้ใน Telegram เมื่อสถานะเปลี่ยน (รายบุคคลตั้งค่าที่โปรไฟล์ผู้ใช้)"},
+	{SettingName: "reminder_enabled", SettingValue: "true", Group: "notification", Type: "boolean", Label: "เตือนก่อนเริ่มประชุม", Description: "ส่งการแจ้งเตือนให้ผู้จองและผู้เข้าร่วมที่ตอบรับแล้วก่อนเวลาประชุม (เฉพาะที่อนุมัติแล้ว)"},
+	{SettingName: "reminder_offsets", SettingValue: "1440,15", Group: "notification", Type: "text", Label: "เตือนล่วงหน้า (นาที)", Description: "คั่นด้วยจุลภาค เช่น 1440,15 = 1 วัน และ 15 นาที"},
+	{SettingName: "digest_enabled", SettingValue: "true", Group: "notification", Type: "boolean", Label: "สรุปประจำวันสำหรับผู้อนุมัติ", Description: "ส่งสรุปรายการรออนุมัติและการจองของพรุ่งนี้วันละครั้ง"},
+	{SettingName: "digest_time", SettingValue: "07:30", Group: "notification", Type: "text", Label: "เวลาส่งสรุปประจำวัน", Description: "รูปแบบ HH:MM"},
+	{SettingName: "digest_overdue_hours", SettingValue: "24", Group: "notification", Type: "number", Label: "รออนุมัตินานเกิน (ชั่วโมง)", Description: "การจองที่รอนานกว่านี้จะถูกเน้นในสรุปประจำวัน"},
+	{SettingName: "notify_max_attempts", SettingValue: "5", Group: "notification", Type: "number", Label: "จำนวนครั้งที่ลองส่งซ้ำสูงสุด", Description: "เกินจำนวนนี้จะย้ายไปสถานะ dead"},
+	{SettingName: "webhook_max_attempts", SettingValue: "8", Group: "webhook", Type: "number", Label: "จำนวนครั้งที่ลองส่ง Webhook ซ้ำสูงสุด", Description: "เกินจำนวนนี้จะย้ายไปสถานะ dead (ส่งซ้ำเองได้จากประวัติการส่ง)"},
+
+	// Popup
+	{SettingName: "popup_enabled", SettingValue: "false", Group: "popup", Type: "boolean", Label: "เปิดใช้งาน Popup", Description: "แสดง Popup เมื่อเข้าสู่ระบบ", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "popup_image", SettingValue: "", Group: "popup", Type: "image", Label: "รูปภาพ Popup / QR", Description: "รูปภาพที่จะแสดงใน Popup", Visibility: domain.SettingVisibilityPublic},
+	{SettingName: "popup_link", SettingValue: "", Group: "popup", Type: "text", Label: "ลิงก์ (เช่น Google Form)", Description: "ลิงก์เมื่อคลิกปุ่ม", Visibility: domain.SettingVisibilityPublic},
+
+	// Cloudinary Storage
+	{SettingName: "cloudinary_cloud_name", SettingValue: "", Group: "storage", Type: "text", Label: "Cloud Name", Description: "Cloudinary Cloud Name"},
+	{SettingName: "cloudinary_api_key", SettingValue: "", Group: "storage", Type: "text", Label: "API Key", Description: "Cloudinary API Key"},
+	{SettingName: "cloudinary_api_secret", SettingValue: "", Group: "storage", Type: "password", Label: "API Secret", Description: "Cloudinary API Secret", Visibility: domain.SettingVisibilitySecret},
 }
 
 func (s *settingService) InitializeDefaults() error {
-	defaults := []domain.Setting{
-		// General
-		{SettingName: "site_name", SettingValue: "TUNorth-BRMS", Group: "general", Type: "text", Label: "ระบบชื่อ", Description: "ชื่อระบบที่แสดงบนแถบ Title"},
-		{SettingName: "site_description", SettingValue: "ระบบจองห้องประชุมออนไลน์", Group: "general", Type: "text", Label: "คำอธิบายระบบ", Description: "คำอธิบายสั้นๆ เกี่ยวกับระบบ"},
-		{SettingName: "copyright_text", SettingValue: "© 2026 Triam Udom Suksa", Group: "general", Type: "text", Label: "ข้อความลิขสิทธิ์", Description: "ข้อความ Footer"},
-		{SettingName: "institute_name", SettingValue: "Triam Udom Suksa", Group: "general", Type: "text", Label: "ชื่อสถาบัน", Description: "ชื่อสถาบันต้นสังกัด"},
-		{SettingName: "enable_register", SettingValue: "true", Group: "general", Type: "boolean", Label: "เปิดรับสมัครสมาชิก", Description: "เปิด/ปิด การลงทะเบียนสมัครสมาชิกใหม่"},
-
-		// Images
-		{SettingName: "site_logo", SettingValue: "", Group: "images", Type: "image", Label: "โลโก้เว็บไซต์", Description: "รูปภาพโลโก้หลัก (PNG/JPG)"},
-		{SettingName: "favicon", SettingValue: "", Group: "images", Type: "image", Label: "Favicon", Description: "ไอคอนบน Tab Browser"},
-
-		// Theme
-		{SettingName: "theme_color", SettingValue: "#db2777", Group: "theme", Type: "color", Label: "สีธีมหลัก", Description: "สีหลักของปุ่มและ Highlight (Default: Pink-600)"},
-		{SettingName: "theme_color_secondary", SettingValue: "#be185d", Group: "theme", Type: "color", Label: "สีธีมรอง (Hover / Highlight)", Description: "สีเมื่อเอาเมาส์ไปชี้ปุ่ม และสีไฮไลท์ข้อความ"},
-		{SettingName: "bg_color_start", SettingValue: "#f8fafc", Group: "theme", Type: "color", Label: "สีพื้นหลัง (เริ่ม)", Description: "Gradient Start"},
-		{SettingName: "bg_color_end", SettingValue: "#f1f5f9", Group: "theme", Type: "color", Label: "สีพื้นหลัง (จบ)", Description: "Gradient End"},
-
-		// Booking
-		{SettingName: "default_booking_status", SettingValue: "pending", Group: "booking", Type: "select", Label: "สถานะเริ่มต้น", Description: "pending หรือ approved"},
-		{SettingName: "advance_booking_days", SettingValue: "1", Group: "booking", Type: "number", Label: "จองล่วงหน้าอย่างน้อย (วัน)", Description: "จำนวนวันที่ต้องจองล่วงหน้า"},
-		{SettingName: "allow_weekend", SettingValue: "false", Group: "booking", Type: "boolean", Label: "อนุญาตให้จองเสาร์-อาทิตย์", Description: "เปิด/ปิด การจองในวันหยุด"},
-
-		// Telegram
-		{SettingName: "telegram_bot_token", SettingValue: "", Group: "telegram", Type: "password", Label: "Telegram Bot Token", Description: "Token จาก BotFather"},
-		{SettingName: "telegram_admin_chat_id", SettingValue: "", Group: "telegram", Type: "text", Label: "Admin Chat ID", Description: "Group ID สำหรับแอดมิน"},
-		{SettingName: "telegram_user_chat_id", SettingValue: "", Group: "telegram", Type: "text", Label: "User Chat ID", Description: "Group ID สำหรับแจ้งเตือนทั่วไป"},
-
-		// Notifications
-		{SettingName: "notify_admin", SettingValue: "true", Group: "notification", Type: "boolean", Label: "แจ้งเตือนแอดมิน", Description: "เมื่อมีการจองใหม่"},
-		{SettingName: "notify_user", SettingValue: "true", Group: "notification", Type: "boolean", Label: "แจ้งเตือนผู้ใช้", Description: "เมื่อสถานะเปลี่ยน"},
-
-		// Popup
-		{SettingName: "popup_enabled", SettingValue: "false", Group: "popup", Type: "boolean", Label: "เปิดใช้งาน Popup", Description: "แสดง Popup เมื่อเข้าสู่ระบบ"},
-		{SettingName: "popup_image", SettingValue: "", Group: "popup", Type: "image", Label: "รูปภาพ Popup / QR", Description: "รูปภาพที่จะแสดงใน Popup"},
-		{SettingName: "popup_link", SettingValue: "", Group: "popup", Type: "text", Label: "ลิงก์ (เช่น Google Form)", Description: "ลิงก์เมื่อคลิกปุ่ม"},
-
-		// Cloudinary Storage
-		{SettingName: "cloudinary_cloud_name", SettingValue: "", Group: "storage", Type: "text", Label: "Cloud Name", Description: "Cloudinary Cloud Name"},
-		{SettingName: "cloudinary_api_key", SettingValue: "", Group: "storage", Type: "text", Label: "API Key", Description: "Cloudinary API Key"},
-		{SettingName: "cloudinary_api_secret", SettingValue: "", Group: "storage", Type: "password", Label: "API Secret", Description: "Cloudinary API Secret"},
-	}
-
-	for _, d := range defaults {
+	updated := false
+	defer func() {
+		if updated {
+			s.changed(nil)
+		}
+	}()
+
+	for _, d := range settingDefaults {
+		if d.Visibility == "" {
+			d.Visibility = domain.SettingVisibilityInternal
+		}
 		existing, err := s.repo.GetByName(d.SettingName)
 		if err != nil || existing.SettingName == "" {
 			_ = s.repo.Update(&d) // Create if not exists (using Save/Update logic)
+			updated = true
+			continue
+		}
+
+		// การจัดประเภทยึดตามโค้ด และเข้ารหัส secret ที่ยังเป็นข้อความธรรมดา (ค่าจากก่อนมีการเข้ารหัส)
+		changed := existing.Visibility != d.Visibility
+		existing.Visibility = d.Visibility
+		if d.Visibility == domain.SettingVisibilitySecret && s.box != nil && existing.SettingValue != "" && !isEncrypted(existing.SettingValue) {
+			sealed, err := s.box.seal(existing.SettingValue)
+			if err != nil {
+				return err
+			}
+			existing.SettingValue = sealed
+			changed = true
+		}
+		if changed {
+			if err := s.repo.Update(existing); err != nil {
+				return err
+			}
+			updated = true
 		}
 	}
 	return nil
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+// fakeSettingRepo เก็บค่าในหน่วยความจำ UpdateBatch บันทึกเฉพาะค่าที่เปลี่ยนเหมือน settingRepository
+type fakeSettingRepo struct {
+	mu       sync.Mutex
+	settings map[string]domain.Setting
+	sets     []domain.SettingChangeSet
+}
+
+func newFakeSettingRepo(settings ...domain.Setting) *fakeSettingRepo {
+	repo := &fakeSettingRepo{settings: map[string]domain.Setting{}}
+	for _, s := range settings {
+		repo.settings[s.SettingName] = s
+	}
+	return repo
+}
+
+func (r *fakeSettingRepo) GetAll() ([]domain.Setting, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	settings := make([]domain.Setting, 0, len(r.settings))
+	for _, s := range r.settings {
+		settings = append(settings, s)
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].SettingName < settings[j].SettingName })
+	return settings, nil
+}
+
+func (r *fakeSettingRepo) GetByName(name string) (*domain.Setting, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.settings[name]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &s, nil
+}
+
+func (r *fakeSettingRepo) Update(setting *domain.Setting) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.settings[setting.SettingName] = *setting
+	return nil
+}
+
+func (r *fakeSettingRepo) UpdateBatch(settings []domain.Setting, changeSet *domain.SettingChangeSet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range settings {
+		current, ok := r.settings[s.SettingName]
+		if !ok || current.SettingValue == s.SettingValue {
+			continue
+		}
+		changeSet.Changes = append(changeSet.Changes, domain.SettingChange{
+			SettingName: s.SettingName,
+			OldValue:    current.SettingValue,
+			NewValue:    s.SettingValue,
+			Secret:      current.Visibility == domain.SettingVisibilitySecret,
+		})
+		current.SettingValue = s.SettingValue
+		r.settings[s.SettingName] = current
+	}
+	if len(changeSet.Changes) > 0 {
+		changeSet.ID = uint(len(r.sets) + 1)
+		r.sets = append(r.sets, *changeSet)
+	}
+	return nil
+}
+
+func (r *fakeSettingRepo) GetHistory(settingName string, limit int) ([]domain.SettingChangeSet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sets []domain.SettingChangeSet
+	for i := len(r.sets) - 1; i >= 0 && len(sets) < limit; i-- {
+		sets = append(sets, r.sets[i])
+	}
+	return sets, nil
+}
+
+func (r *fakeSettingRepo) GetChangeSet(id uint) (*domain.SettingChangeSet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id == 0 || int(id) > len(r.sets) {
+		return nil, errors.New("record not found")
+	}
+	set := r.sets[id-1]
+	return &set, nil
+}
+
+func (r *fakeSettingRepo) value(name string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.settings[name].SettingValue
+}
+
+// fakeLogService ทิ้ง Log ทั้งหมด
+type fakeLogService struct{}
+
+func (fakeLogService) LogAction(userID uint, action, description, ip, userAgent string) error {
+	return nil
+}
+
+func (fakeLogService) LogImpersonatedAction(userID, impersonatorID uint, action, description, ip, userAgent string) error {
+	return nil
+}
+
+func (fakeLogService) LogClientAction(userID uint, action, description string, client ports.ClientInfo) error {
+	return nil
+}
+
+func (fakeLogService) GetLogs(limit int) ([]domain.Log, error) { return nil, nil }
+
+func secretSetting(name, value string) domain.Setting {
+	return domain.Setting{SettingName: name, SettingValue: value, Visibility: domain.SettingVisibilitySecret}
+}
+
+func TestUpdateSettingsEncryptsSecrets(t *testing.T) {
+	repo := newFakeSettingRepo(secretSetting("smtp_password", ""))
+	service := NewSettingService(repo, fakeLogService{}, nil, "test-key")
+
+	if err := service.UpdateSettings([]domain.Setting{{SettingName: "smtp_password", SettingValue: "hunter2"}}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if stored := repo.value("smtp_password"); !isEncrypted(stored) {
+		t.Fatalf("secret stored as %q, want an encrypted value", stored)
+	}
+	if got := service.GetSettingValue("smtp_password"); got != "hunter2" {
+		t.Fatalf("GetSettingValue = %q, want the decrypted value", got)
+	}
+}
+
+func TestUpdateSettingsRejectsSecretWithoutKey(t *testing.T) {
+	repo := newFakeSettingRepo(secretSetting("smtp_password", ""))
+	service := NewSettingService(repo, fakeLogService{}, nil, "")
+
+	err := service.UpdateSettings([]domain.Setting{{SettingName: "smtp_password", SettingValue: "hunter2"}}, 1)
+	if !errors.Is(err, ports.ErrSettingsEncryptionKeyMissing) {
+		t.Fatalf("got %v, want ErrSettingsEncryptionKeyMissing", err)
+	}
+	if stored := repo.value("smtp_password"); stored != "" {
+		t.Fatalf("secret stored as %q without a key", stored)
+	}
+
+	// ล้างค่าได้แม้ไม่มีกุญแจ
+	repo.settings["smtp_password"] = secretSetting("smtp_password", "legacy plaintext")
+	if err := service.UpdateSettings([]domain.Setting{{SettingName: "smtp_password", Clear: true}}, 1); err != nil {
+		t.Fatalf("clearing a secret without a key: %v", err)
+	}
+	if stored := repo.value("smtp_password"); stored != "" {
+		t.Fatalf("secret not cleared, stored %q", stored)
+	}
+}
+
+func TestUpdateSettingsSkipsUnchangedSecret(t *testing.T) {
+	repo := newFakeSettingRepo(secretSetting("smtp_password", ""))
+	service := NewSettingService(repo, fakeLogService{}, nil, "test-key")
+
+	save := []domain.Setting{{SettingName: "smtp_password", SettingValue: "hunter2"}}
+	if err := service.UpdateSettings(save, 1); err != nil {
+		t.Fatal(err)
+	}
+	sealed := repo.value("smtp_password")
+
+	// บันทึกค่าเดิมซ้ำ: ciphertext ใหม่จะต่างจากเดิม แต่ต้องไม่เกิดประวัติใหม่
+	if err := service.UpdateSettings(save, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.sets) != 1 {
+		t.Fatalf("got %d change sets, want 1 (saving the same secret again is not a change)", len(repo.sets))
+	}
+	if repo.value("smtp_password") != sealed {
+		t.Fatal("unchanged secret was re-encrypted and rewritten")
+	}
+
+	if err := service.UpdateSettings([]domain.Setting{{SettingName: "smtp_password", SettingValue: "correct horse"}}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.sets) != 2 {
+		t.Fatalf("got %d change sets, want 2 after a real change", len(repo.sets))
+	}
+}
+
+func TestRollbackSkipsSecretWithSamePlaintext(t *testing.T) {
+	repo := newFakeSettingRepo(secretSetting("smtp_password", ""))
+	service := NewSettingService(repo, fakeLogService{}, nil, "test-key")
+
+	for _, value := range []string{"first", "second"} {
+		if err := service.UpdateSettings([]domain.Setting{{SettingName: "smtp_password", SettingValue: value}}, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// ย้อนชุดที่ 2 (second -> first) แล้วย้อนชุดที่ 2 อีกครั้ง: ค่าจริงเป็น first อยู่แล้ว
+	rollback, err := service.Rollback(2, "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rollback.Changes) != 1 || service.GetSettingValue("smtp_password") != "first" {
+		t.Fatalf("first rollback: %d changes, value %q", len(rollback.Changes), service.GetSettingValue("smtp_password"))
+	}
+	again, err := service.Rollback(2, "smtp_password", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Changes) != 0 {
+		t.Fatalf("second rollback recorded %d changes, want none", len(again.Changes))
+	}
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+	sessionTouchInterval   = time.Minute // บันทึก last seen ไม่ถี่กว่านี้
+)
+
+var errInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+type tokenService struct {
+	repo       ports.RefreshTokenRepository
+	sessions   ports.SessionRepository
+	userRepo   ports.UserRepository
+	settings   ports.SettingService
+	logService ports.LogService
+}
+
+func NewTokenService(repo ports.RefreshTokenRepository, sessions ports.SessionRepository, userRepo ports.UserRepository, settings ports.SettingService, logService ports.LogService) ports.TokenService {
+	return &tokenService{
+		repo:       repo,
+		sessions:   sessions,
+		userRepo:   userRepo,
+		settings:   settings,
+		logService: logService,
+	}
+}
+
+func (s *tokenService) Issue(user *domain.User, client ports.ClientInfo) (*ports.TokenPair, error) {
+	familyID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	// ล้าง token ที่หมดอายุไปแล้ว (ไม่ต้องรอ ไม่สำคัญถ้าล้มเหลว)
+	now := time.Now()
+	go func() {
+		s.repo.DeleteExpired(now)
+		s.sessions.DeleteExpired(now)
+	}()
+
+	// 1 Family = 1 Session ที่ผู้ใช้เห็นในรายการเครื่องที่ Login อยู่
+	if err := s.sessions.Create(&domain.Session{
+		ID:         familyID,
+		UserID:     user.ID,
+		Device:     deviceName(client.UserAgent),
+		IPAddress:  client.IP,
+		UserAgent:  client.UserAgent,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.refreshTTL()),
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.issue(user, familyID, client)
+}
+
+// Refresh: หมุน Refresh Token (ใช้ได้ครั้งเดียว) แล้วออก Access Token ใหม่
+func (s *tokenService) Refresh(refreshToken string, client ports.ClientInfo) (*ports.TokenPair, error) {
+	stored, err := s.repo.GetByHash(hashToken(refreshToken))
+	if err != nil {
+		return nil, errInvalidRefreshToken
+	}
+
+	now := time.Now()
+	if stored.UsedAt != nil || stored.RevokedAt != nil {
+		// token ที่ถูกหมุนไปแล้วถูกนำมาใช้อีก: อาจถูกขโมย เพิกถอนทั้ง Family
+		if stored.UsedAt != nil {
+			s.reuseDetected(stored, client, now)
+		}
+		return nil, errInvalidRefreshToken
+	}
+	if now.After(stored.ExpiresAt) {
+		return nil, errInvalidRefreshToken
+	}
+
+	ok, err := s.repo.MarkUsed(stored.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// มีอีก request ใช้ token นี้ไปพร้อมกัน
+		s.reuseDetected(stored, client, now)
+		return nil, errInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.GetByID(stored.UserID)
+	if err != nil || !user.IsActive() {
+		s.RevokeSession(stored.FamilyID)
+		return nil, errInvalidRefreshToken
+	}
+
+	expiresAt := now.Add(s.refreshTTL())
+	session, err := s.sessions.GetByID(stored.FamilyID)
+	switch {
+	case err != nil:
+		// Family ที่ออกก่อนมีตาราง sessions
+		err = s.sessions.Create(&domain.Session{
+			ID:         stored.FamilyID,
+			UserID:     user.ID,
+			Device:     deviceName(client.UserAgent),
+			IPAddress:  client.IP,
+			UserAgent:  client.UserAgent,
+			CreatedAt:  stored.CreatedAt,
+			LastSeenAt: now,
+			ExpiresAt:  expiresAt,
+		})
+	case session.RevokedAt != nil:
+		s.repo.RevokeFamily(stored.FamilyID, now)
+		return nil, errInvalidRefreshToken
+	default:
+		err = s.sessions.Refreshed(session.ID, deviceName(client.UserAgent), client, now, expiresAt)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issue(user, stored.FamilyID, client)
+}
+
+func (s *tokenService) reuseDetected(stored *domain.RefreshToken, client ports.ClientInfo, now time.Time) {
+	s.RevokeSession(stored.FamilyID)
+	go s.logService.LogClientAction(stored.UserID, "REFRESH_TOKEN_REUSE", fmt.Sprintf("Refresh token reused, revoked session %s", stored.FamilyID), client)
+}
+
+func (s *tokenService) Revoke(refreshToken string) (uint, error) {
+	stored, err := s.repo.GetByHash(hashToken(refreshToken))
+	if err != nil {
+		return 0, errInvalidRefreshToken
+	}
+	return stored.UserID, s.RevokeSession(stored.FamilyID)
+}
+
+// RevokeSession: Access Token ของ Session นี้ใช้ไม่ได้ทันที (ตรวจใน ValidateAccess) และ Refresh ต่อไม่ได้
+func (s *tokenService) RevokeSession(sessionID string) error {
+	now := time.Now()
+	if err := s.sessions.Revoke(sessionID, now); err != nil {
+		return err
+	}
+	return s.repo.RevokeFamily(sessionID, now)
+}
+
+// RevokeUserTokens: เพิ่ม TokenVersion ทำให้ Access Token เดิมใช้ไม่ได้ทันที และเพิกถอน Refresh Token ทั้งหมด
+func (s *tokenService) RevokeUserTokens(userID uint) error {
+	if err := s.userRepo.IncrementTokenVersion(userID); err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := s.sessions.RevokeByUser(userID, now); err != nil {
+		return err
+	}
+	return s.repo.RevokeByUser(userID, now)
+}
+
+func (s *tokenService) ParseAccessToken(tokenString string) (*ports.AccessClaims, error) {
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+	result := accessClaimsFromMap(claims)
+	if err := s.ValidateAccess(result.UserID, result.Version, result.SessionID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *tokenService) ValidateAccess(userID uint, version int, sessionID string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("user no longer exists")
+	}
+	if user.TokenVersion != version {
+		return errors.New("token has been revoked")
+	}
+	if !user.IsActive() {
+		return errors.New("account is not active")
+	}
+
+	now := time.Now()
+	session, err := s.sessions.GetByID(sessionID)
+	if err != nil || session.UserID != userID || session.RevokedAt != nil || now.After(session.ExpiresAt) {
+		return errors.New("session has been logged out")
+	}
+	// Session สวมสิทธิ์: ผู้ดูแลต้องยังเป็นผู้ดูแลที่ใช้งานได้
+	if session.ImpersonatorID != nil {
+		admin, err := s.userRepo.GetByID(*session.ImpersonatorID)
+		if err != nil || !admin.IsActive() || !domain.HasPermission(admin.Role, domain.PermUsersManage) {
+			return errors.New("impersonation session is no longer valid")
+		}
+	}
+	if err := s.sessions.Touch(sessionID, now, now.Add(-sessionTouchInterval)); err != nil {
+		log.Println("Session: failed to update last seen:", err)
+	}
+	return nil
+}
+
+// IssueImpersonation: ออกเฉพาะ Access Token (ไม่มี Refresh Token) หมดอายุพร้อม Session สวมสิทธิ์
+func (s *tokenService) IssueImpersonation(user *domain.User, impersonatorID uint, ttl time.Duration, client ports.ClientInfo) (*ports.TokenPair, *domain.Session, error) {
+	sessionID, err := randomHex(16)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	session := &domain.Session{
+		ID:             sessionID,
+		UserID:         user.ID,
+		Device:         deviceName(client.UserAgent),
+		IPAddress:      client.IP,
+		UserAgent:      client.UserAgent,
+		LastSeenAt:     now,
+		ExpiresAt:      now.Add(ttl),
+		ImpersonatorID: &impersonatorID,
+	}
+	if err := s.sessions.Create(session); err != nil {
+		return nil, nil, err
+	}
+
+	accessToken, err := signAccessToken(jwt.MapClaims{
+		"user_id":  user.ID,
+		"username": user.Username,
+		"role":     user.Role,
+		"sid":      sessionID,
+		"ver":      user.TokenVersion,
+		"imp":      impersonatorID,
+		"iat":      now.Unix(),
+		"exp":      session.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ports.TokenPair{AccessToken: accessToken, ExpiresIn: int(ttl.Seconds())}, session, nil
+}
+
+func (s *tokenService) issue(user *domain.User, familyID string, client ports.ClientInfo) (*ports.TokenPair, error) {
+	now := time.Now()
+	accessTTL := s.accessTTL()
+
+	// สร้าง JWT Token
+	accessToken, err := signAccessToken(jwt.MapClaims{
+		"user_id":  user.ID,
+		"username": user.Username,
+		"role":     user.Role,
+		"sid":      familyID,
+		"ver":      user.TokenVersion,
+		"iat":      now.Unix(),
+		"exp":      now.Add(accessTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(raw)
+
+	stored := &domain.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(s.refreshTTL()),
+		IPAddress: client.IP,
+		UserAgent: client.UserAgent,
+	}
+	if err := s.repo.Create(stored); err != nil {
+		return nil, err
+	}
+
+	return &ports.TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        int(accessTTL.Seconds()),
+		RefreshExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+// signAccessToken เซ็นลายเซ็นด้วย Secret Key (จาก .env)
+func signAccessToken(claims jwt.MapClaims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(os.Getenv("JWT_SECRET")))
+}
+
+func (s *tokenService) accessTTL() time.Duration {
+	minutes, err := strconv.Atoi(s.settings.GetSettingValue("access_token_ttl_minutes"))
+	if err != nil || minutes <= 0 {
+		return defaultAccessTokenTTL
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+func (s *tokenService) refreshTTL() time.Duration {
+	days, err := strconv.Atoi(s.settings.GetSettingValue("refresh_token_ttl_days"))
+	if err != nil || days <= 0 {
+		return defaultRefreshTokenTTL
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// accessClaimsFromMap: ตัวเลขใน JWT ถูก decode เป็น float64
+func accessClaimsFromMap(claims jwt.MapClaims) *ports.AccessClaims {
+	result := &ports.AccessClaims{}
+	if id, ok := claims["user_id"].(float64); ok {
+		result.UserID = uint(id)
+	}
+	if ver, ok := claims["ver"].(float64); ok {
+		result.Version = int(ver)
+	}
+	result.Username, _ = claims["username"].(string)
+	result.Role, _ = claims["role"].(string)
+	result.SessionID, _ = claims["sid"].(string)
+	if imp, ok := claims["imp"].(float64); ok {
+		result.ImpersonatorID = uint(imp)
+	}
+	return result
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+type fakeRefreshTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[uint]*domain.RefreshToken
+}
+
+func (r *fakeRefreshTokenRepo) Create(token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tokens == nil {
+		r.tokens = map[uint]*domain.RefreshToken{}
+	}
+	token.ID = uint(len(r.tokens) + 1)
+	token.CreatedAt = time.Now()
+	stored := *token
+	r.tokens[token.ID] = &stored
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) GetByHash(hash string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.TokenHash == hash {
+			found := *t
+			return &found, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeRefreshTokenRepo) MarkUsed(id uint, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.tokens[id]
+	if t.UsedAt != nil || t.RevokedAt != nil {
+		return false, nil
+	}
+	t.UsedAt = &at
+	return true, nil
+}
+
+func (r *fakeRefreshTokenRepo) revoke(match func(t *domain.RefreshToken) bool, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if match(t) && t.RevokedAt == nil {
+			t.RevokedAt = &at
+		}
+	}
+}
+
+func (r *fakeRefreshTokenRepo) RevokeFamily(familyID string, at time.Time) error {
+	r.revoke(func(t *domain.RefreshToken) bool { return t.FamilyID == familyID }, at)
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) RevokeByUser(userID uint, at time.Time) error {
+	r.revoke(func(t *domain.RefreshToken) bool { return t.UserID == userID }, at)
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) DeleteExpired(before time.Time) error { return nil }
+
+type fakeSessionRepo struct {
+	mu       sync.Mutex
+	sessions map[string]*domain.Session
+}
+
+func (r *fakeSessionRepo) Create(session *domain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sessions == nil {
+		r.sessions = map[string]*domain.Session{}
+	}
+	stored := *session
+	r.sessions[session.ID] = &stored
+	return nil
+}
+
+func (r *fakeSessionRepo) GetByID(id string) (*domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.sessions[id]; ok {
+		found := *s
+		return &found, nil
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeSessionRepo) GetActiveByUser(userID uint, now time.Time) ([]domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var active []domain.Session
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.RevokedAt == nil && now.Before(s.ExpiresAt) {
+			active = append(active, *s)
+		}
+	}
+	return active, nil
+}
+
+func (r *fakeSessionRepo) Touch(id string, at, since time.Time) error { return nil }
+
+func (r *fakeSessionRepo) Refreshed(id, device string, client ports.ClientInfo, at, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[id].ExpiresAt = expiresAt
+	return nil
+}
+
+func (r *fakeSessionRepo) Revoke(id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.sessions[id]; ok && s.RevokedAt == nil {
+		s.RevokedAt = &at
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) RevokeByUser(userID uint, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			s.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) DeleteExpired(before time.Time) error { return nil }
+
+func newTokenFixture(t *testing.T) (ports.TokenService, *domain.User, *fakeUserRepo) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	user := &domain.User{Username: "somchai", Role: domain.RoleUser, Status: domain.UserStatusActive}
+	users := newFakeUserRepo(user)
+	settings := NewSettingService(newFakeSettingRepo(), fakeLogService{}, nil, "")
+	service := NewTokenService(&fakeRefreshTokenRepo{}, &fakeSessionRepo{}, users, settings, fakeLogService{})
+	stored, _ := users.GetByUsername("somchai")
+	return service, stored, users
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	service, user, _ := newTokenFixture(t)
+	client := ports.ClientInfo{IP: "10.0.0.1", UserAgent: "test"}
+
+	first, err := service.Issue(user, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := service.Refresh(first.RefreshToken, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+
+	claims, err := service.ParseAccessToken(second.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != user.ID || claims.Role != domain.RoleUser || claims.SessionID == "" {
+		t.Fatalf("claims = %+v", claims)
+	}
+	// หมุนแล้วยังอยู่ Session เดิม
+	firstClaims, _ := service.ParseAccessToken(first.AccessToken)
+	if firstClaims == nil || firstClaims.SessionID != claims.SessionID {
+		t.Fatal("rotated token moved to another session")
+	}
+
+	if _, err := service.Refresh("not-a-token", client); err == nil {
+		t.Fatal("unknown refresh token accepted")
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	service, user, _ := newTokenFixture(t)
+	client := ports.ClientInfo{IP: "10.0.0.1"}
+
+	stolen, _ := service.Issue(user, client)
+	other, _ := service.Issue(user, client) // อีกเครื่องหนึ่ง (Family อื่น)
+
+	// เจ้าของใช้ต่อ ได้ token ใหม่
+	rotated, err := service.Refresh(stolen.RefreshToken, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ผู้โจมตีใช้ token เก่าซ้ำ: ถูกปฏิเสธ และทั้ง Family ถูกเพิกถอน
+	if _, err := service.Refresh(stolen.RefreshToken, ports.ClientInfo{IP: "203.0.113.9"}); err == nil {
+		t.Fatal("reused refresh token accepted")
+	}
+	if _, err := service.Refresh(rotated.RefreshToken, client); err == nil {
+		t.Fatal("token rotated from a reused token still works after reuse was detected")
+	}
+	if _, err := service.ParseAccessToken(rotated.AccessToken); err == nil {
+		t.Fatal("access token of the revoked session still validates")
+	}
+
+	// Family อื่นไม่ได้รับผล
+	if _, err := service.Refresh(other.RefreshToken, client); err != nil {
+		t.Fatalf("unrelated session was revoked: %v", err)
+	}
+}
+
+func TestRevokeLogsOutOneSession(t *testing.T) {
+	service, user, _ := newTokenFixture(t)
+	client := ports.ClientInfo{}
+
+	phone, _ := service.Issue(user, client)
+	laptop, _ := service.Issue(user, client)
+
+	userID, err := service.Revoke(phone.RefreshToken)
+	if err != nil || userID != user.ID {
+		t.Fatalf("Revoke = %d, %v", userID, err)
+	}
+	if _, err := service.Refresh(phone.RefreshToken, client); err == nil {
+		t.Fatal("logged out refresh token still works")
+	}
+	if _, err := service.ParseAccessToken(phone.AccessToken); err == nil {
+		t.Fatal("logged out access token still validates")
+	}
+	if _, err := service.ParseAccessToken(laptop.AccessToken); err != nil {
+		t.Fatalf("other session was logged out: %v", err)
+	}
+}
+
+func TestRevokeUserTokensInvalidatesEverything(t *testing.T) {
+	service, user, _ := newTokenFixture(t)
+	client := ports.ClientInfo{}
+
+	pair, _ := service.Issue(user, client)
+	if err := service.RevokeUserTokens(user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ParseAccessToken(pair.AccessToken); err == nil {
+		t.Fatal("access token still validates after RevokeUserTokens")
+	}
+	if _, err := service.Refresh(pair.RefreshToken, client); err == nil {
+		t.Fatal("refresh token still works after RevokeUserTokens")
+	}
+}
+
+func TestRefreshRejectsInactiveUser(t *testing.T) {
+	service, user, users := newTokenFixture(t)
+	pair, _ := service.Issue(user, ports.ClientInfo{})
+
+	user.Status = domain.UserStatusDisabled
+	_ = users.Update(user)
+	if _, err := service.Refresh(pair.RefreshToken, ports.ClientInfo{}); err == nil {
+		t.Fatal("disabled user refreshed a token")
+	}
+}
+
+func TestPasswordChangeRevokesTokens(t *testing.T) {
+	for name, change := range map[string]func(users *fakeUserRepo, tokens ports.TokenService, userID uint) error{
+		"own profile": func(users *fakeUserRepo, tokens ports.TokenService, userID uint) error {
+			settings := NewSettingService(newFakeSettingRepo(), fakeLogService{}, nil, "")
+			auth := NewAuthService(users, tokens, settings, nil, nil, noTwoFactor{}, &fakeThrottle{}, fakeLogService{})
+			_, err := auth.UpdateMe(userID, &domain.User{FullName: "Somchai", Password: "new-password"})
+			return err
+		},
+		"by admin": func(users *fakeUserRepo, tokens ports.TokenService, userID uint) error {
+			return NewUserService(users, fakeLogService{}, tokens).UpdateUser(userID, &domain.User{FullName: "Somchai", Role: domain.RoleUser, Password: "new-password"})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			service, user, users := newTokenFixture(t)
+			pair, _ := service.Issue(user, ports.ClientInfo{})
+			version := user.TokenVersion
+
+			if err := change(users, service, user.ID); err != nil {
+				t.Fatal(err)
+			}
+			stored, _ := users.GetByID(user.ID)
+			if stored.TokenVersion <= version {
+				t.Fatalf("TokenVersion = %d, want it bumped from %d", stored.TokenVersion, version)
+			}
+			if _, err := service.Refresh(pair.RefreshToken, ports.ClientInfo{}); err == nil {
+				t.Fatal("refresh token issued before the password change still works")
+			}
+			if _, err := service.ParseAccessToken(pair.AccessToken); err == nil {
+				t.Fatal("access token issued before the password change still validates")
+			}
+		})
+	}
+}
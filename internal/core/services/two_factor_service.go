@@ -0,0 +1,321 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+const (
+	twoFactorChallengeTTL      = 5 * time.Minute
+	twoFactorChallengeAttempts = 5
+	recoveryCodeCount          = 10
+)
+
+var (
+	errInvalidTwoFactorCode    = errors.New("invalid verification code")
+	errInvalidTwoFactorSession = errors.New("login session has expired, please log in again")
+	errTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+)
+
+type twoFactorService struct {
+	repo       ports.TwoFactorRepository
+	userRepo   ports.UserRepository
+	settings   ports.SettingService
+	throttle   ports.LoginThrottleService
+	logService ports.LogService
+}
+
+func NewTwoFactorService(repo ports.TwoFactorRepository, userRepo ports.UserRepository, settings ports.SettingService, throttle ports.LoginThrottleService, logService ports.LogService) ports.TwoFactorService {
+	return &twoFactorService{repo: repo, userRepo: userRepo, settings: settings, throttle: throttle, logService: logService}
+}
+
+func (s *twoFactorService) Status(userID uint) (*ports.TwoFactorStatus, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ports.TwoFactorStatus{Required: s.roleRequired(user.Role)}
+	if tf, err := s.repo.Get(userID); err == nil && tf.EnabledAt != nil {
+		status.Enabled = true
+		status.EnabledAt = tf.EnabledAt
+		status.RecoveryCodesRemaining, _ = s.repo.CountRecoveryCodes(userID)
+	}
+	return status, nil
+}
+
+func (s *twoFactorService) IsRequired(user *domain.User) bool {
+	return s.enabled(user.ID) || s.roleRequired(user.Role)
+}
+
+// BeginSetup: สร้าง Secret ใหม่ (ยังไม่มีผลจนกว่าจะยืนยันรหัสแรกด้วย Enable)
+func (s *twoFactorService) BeginSetup(userID uint) (*ports.TwoFactorSetup, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tf, err := s.repo.Get(userID)
+	if err == nil && tf.EnabledAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	if err != nil {
+		tf = &domain.UserTwoFactor{UserID: userID}
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	tf.Secret = secret
+	tf.LastUsedStep = 0
+	if err := s.repo.Save(tf); err != nil {
+		return nil, err
+	}
+
+	issuer := s.settings.GetSettingValue("site_name")
+	if issuer == "" {
+		issuer = "TUNorth-BRMS"
+	}
+	return &ports.TwoFactorSetup{Secret: secret, OTPAuthURL: totpURI(issuer, user.Username, secret)}, nil
+}
+
+func (s *twoFactorService) Enable(userID uint, code string, client ports.ClientInfo) ([]string, error) {
+	tf, err := s.repo.Get(userID)
+	if err != nil {
+		return nil, errors.New("start two-factor setup first")
+	}
+	if tf.EnabledAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	step, ok := verifyTOTP(tf.Secret, normalizeCode(code), time.Now())
+	if !ok {
+		return nil, errInvalidTwoFactorCode
+	}
+
+	now := time.Now()
+	tf.EnabledAt = &now
+	tf.LastUsedStep = step
+	if err := s.repo.Save(tf); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.logService.LogClientAction(userID, "2FA_ENABLED", "เปิดใช้การยืนยันตัวตนสองขั้นตอน (TOTP)", client)
+	return codes, nil
+}
+
+func (s *twoFactorService) Disable(userID uint, code string, client ports.ClientInfo) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if s.roleRequired(user.Role) {
+		return errors.New("two-factor authentication is required for your role and cannot be disabled")
+	}
+	if err := s.verifyCode(userID, code, client); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(userID); err != nil {
+		return err
+	}
+
+	go s.logService.LogClientAction(userID, "2FA_DISABLED", "ปิดการยืนยันตัวตนสองขั้นตอน", client)
+	return nil
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(userID uint, code string, client ports.ClientInfo) ([]string, error) {
+	if err := s.verifyCode(userID, code, client); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.logService.LogClientAction(userID, "2FA_RECOVERY_CODES_REGENERATED", "สร้าง Recovery Code ชุดใหม่", client)
+	return codes, nil
+}
+
+func (s *twoFactorService) Reset(userID, actorID uint, client ports.ClientInfo) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if err := s.repo.Delete(userID); err != nil {
+		return err
+	}
+
+	go s.logService.LogClientAction(actorID, "2FA_RESET", fmt.Sprintf("Reset two-factor authentication of %s (ID: %d)", user.Username, userID), client)
+	return nil
+}
+
+// StartChallenge: เรียกหลังตรวจรหัสผ่านผ่านแล้ว
+func (s *twoFactorService) StartChallenge(user *domain.User, client ports.ClientInfo) (*ports.TwoFactorRequiredError, error) {
+	token, err := randomURLToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	s.repo.DeleteExpiredChallenges(now)
+	if err := s.repo.CreateChallenge(&domain.TwoFactorChallenge{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(twoFactorChallengeTTL),
+		IPAddress: client.IP,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &ports.TwoFactorRequiredError{
+		ChallengeToken: token,
+		ExpiresIn:      int(twoFactorChallengeTTL / time.Second),
+		EnrollRequired: !s.enabled(user.ID),
+	}, nil
+}
+
+func (s *twoFactorService) ChallengeSetup(challengeToken string) (*ports.TwoFactorSetup, error) {
+	_, user, err := s.loadChallenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if s.enabled(user.ID) {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	return s.BeginSetup(user.ID)
+}
+
+func (s *twoFactorService) CompleteChallenge(challengeToken, code string, client ports.ClientInfo) (*domain.User, []string, error) {
+	challenge, user, err := s.loadChallenge(challengeToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	// รหัส 2FA ผิดนับรวมกับรหัสผ่านผิดของบัญชีเดียวกัน
+	if err := s.throttle.Check(user.Username, client.IP); err != nil {
+		return nil, nil, err
+	}
+
+	var recoveryCodes []string
+	if s.enabled(user.ID) {
+		err = s.verifyCode(user.ID, code, client)
+	} else {
+		// role ถูกบังคับแต่ยังไม่ได้ตั้งค่า: รหัสแรกจากแอปใช้ยืนยันการตั้งค่าไปพร้อมกัน
+		recoveryCodes, err = s.Enable(user.ID, code, client)
+	}
+	if err != nil {
+		s.repo.IncrementChallengeAttempts(challenge.ID)
+		if errors.Is(err, errInvalidTwoFactorCode) {
+			s.throttle.RecordFailure(user.Username, user.ID, "invalid two-factor code", client)
+		}
+		return nil, nil, err
+	}
+
+	ok, err := s.repo.ConsumeChallenge(challenge.ID, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, errInvalidTwoFactorSession
+	}
+	s.throttle.RecordSuccess(user.Username)
+	return user, recoveryCodes, nil
+}
+
+func (s *twoFactorService) loadChallenge(token string) (*domain.TwoFactorChallenge, *domain.User, error) {
+	challenge, err := s.repo.GetChallenge(hashToken(token))
+	if err != nil || challenge.UsedAt != nil || time.Now().After(challenge.ExpiresAt) {
+		return nil, nil, errInvalidTwoFactorSession
+	}
+	if challenge.Attempts >= twoFactorChallengeAttempts {
+		return nil, nil, errors.New("too many invalid codes, please log in again")
+	}
+
+	user, err := s.userRepo.GetByID(challenge.UserID)
+	if err != nil || !user.IsActive() {
+		return nil, nil, errInvalidTwoFactorSession
+	}
+	return challenge, user, nil
+}
+
+// verifyCode: รับได้ทั้งรหัส 6 หลักจากแอป และ Recovery Code (ใช้ได้ครั้งเดียว)
+func (s *twoFactorService) verifyCode(userID uint, code string, client ports.ClientInfo) error {
+	tf, err := s.repo.Get(userID)
+	if err != nil || tf.EnabledAt == nil {
+		return errTwoFactorNotEnabled
+	}
+
+	code = normalizeCode(code)
+	if step, ok := verifyTOTP(tf.Secret, code, time.Now()); ok {
+		used, err := s.repo.UseStep(userID, step)
+		if err != nil {
+			return err
+		}
+		if used {
+			return nil
+		}
+	} else if len(code) > totpDigits {
+		used, err := s.repo.UseRecoveryCode(userID, hashToken(code), time.Now())
+		if err != nil {
+			return err
+		}
+		if used {
+			go s.logService.LogClientAction(userID, "2FA_RECOVERY_CODE_USED", "ใช้ Recovery Code ยืนยันตัวตน", client)
+			return nil
+		}
+	}
+
+	go s.logService.LogClientAction(userID, "2FA_FAILED", "รหัสยืนยันตัวตนสองขั้นตอนไม่ถูกต้อง", client)
+	return errInvalidTwoFactorCode
+}
+
+func (s *twoFactorService) newRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(totpEncoding.EncodeToString(raw)) // 8 ตัวอักษร
+		codes = append(codes, code[:4]+"-"+code[4:])
+		hashes = append(hashes, hashToken(code))
+	}
+	if err := s.repo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *twoFactorService) enabled(userID uint) bool {
+	tf, err := s.repo.Get(userID)
+	return err == nil && tf.EnabledAt != nil
+}
+
+// roleRequired: two_factor_required_roles เช่น "admin,approver"
+func (s *twoFactorService) roleRequired(role string) bool {
+	for _, r := range strings.Split(s.settings.GetSettingValue("two_factor_required_roles"), ",") {
+		if strings.TrimSpace(r) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeCode: ตัดช่องว่างและขีด ให้พิมพ์ "123 456" หรือ "abcd-efgh" ได้
+func normalizeCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
@@ -0,0 +1,342 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+type fakeTwoFactorRepo struct {
+	mu         sync.Mutex
+	factors    map[uint]*domain.UserTwoFactor
+	recovery   map[string]*domain.TwoFactorRecoveryCode
+	challenges map[uint]*domain.TwoFactorChallenge
+}
+
+func newFakeTwoFactorRepo() *fakeTwoFactorRepo {
+	return &fakeTwoFactorRepo{
+		factors:    map[uint]*domain.UserTwoFactor{},
+		recovery:   map[string]*domain.TwoFactorRecoveryCode{},
+		challenges: map[uint]*domain.TwoFactorChallenge{},
+	}
+}
+
+func (r *fakeTwoFactorRepo) Get(userID uint) (*domain.UserTwoFactor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tf, ok := r.factors[userID]; ok {
+		found := *tf
+		return &found, nil
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeTwoFactorRepo) Save(tf *domain.UserTwoFactor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *tf
+	r.factors[tf.UserID] = &stored
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) Delete(userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.factors, userID)
+	for hash, code := range r.recovery {
+		if code.UserID == userID {
+			delete(r.recovery, hash)
+		}
+	}
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) UseStep(userID uint, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tf := r.factors[userID]
+	if tf == nil || step <= tf.LastUsedStep {
+		return false, nil
+	}
+	tf.LastUsedStep = step
+	return true, nil
+}
+
+func (r *fakeTwoFactorRepo) ReplaceRecoveryCodes(userID uint, hashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for hash, code := range r.recovery {
+		if code.UserID == userID {
+			delete(r.recovery, hash)
+		}
+	}
+	for _, hash := range hashes {
+		r.recovery[hash] = &domain.TwoFactorRecoveryCode{UserID: userID, CodeHash: hash}
+	}
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) UseRecoveryCode(userID uint, hash string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	code, ok := r.recovery[hash]
+	if !ok || code.UserID != userID || code.UsedAt != nil {
+		return false, nil
+	}
+	code.UsedAt = &at
+	return true, nil
+}
+
+func (r *fakeTwoFactorRepo) CountRecoveryCodes(userID uint) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for _, code := range r.recovery {
+		if code.UserID == userID && code.UsedAt == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeTwoFactorRepo) CreateChallenge(challenge *domain.TwoFactorChallenge) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	challenge.ID = uint(len(r.challenges) + 1)
+	stored := *challenge
+	r.challenges[challenge.ID] = &stored
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) GetChallenge(hash string) (*domain.TwoFactorChallenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.challenges {
+		if c.TokenHash == hash {
+			found := *c
+			return &found, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeTwoFactorRepo) IncrementChallengeAttempts(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.challenges[id].Attempts++
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) ConsumeChallenge(id uint, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := r.challenges[id]
+	if c.UsedAt != nil {
+		return false, nil
+	}
+	c.UsedAt = &at
+	return true, nil
+}
+
+func (r *fakeTwoFactorRepo) DeleteExpiredChallenges(before time.Time) error { return nil }
+
+// currentCode รหัสจากแอป Authenticator ณ เวลา at
+func currentCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return totpCode(key, at.Unix()/totpPeriod)
+}
+
+type twoFactorFixture struct {
+	service  ports.TwoFactorService
+	repo     *fakeTwoFactorRepo
+	throttle *fakeThrottle
+	user     *domain.User
+}
+
+func newTwoFactorFixture(requiredRoles string) *twoFactorFixture {
+	users := newFakeUserRepo(&domain.User{Username: "somchai", Role: domain.RoleApprover, Status: domain.UserStatusActive})
+	user, _ := users.GetByUsername("somchai")
+	settings := NewSettingService(newFakeSettingRepo(
+		domain.Setting{SettingName: "two_factor_required_roles", SettingValue: requiredRoles},
+		domain.Setting{SettingName: "site_name", SettingValue: "Booking"},
+	), fakeLogService{}, nil, "")
+	f := &twoFactorFixture{repo: newFakeTwoFactorRepo(), throttle: &fakeThrottle{}, user: user}
+	f.service = NewTwoFactorService(f.repo, users, settings, f.throttle, fakeLogService{})
+	return f
+}
+
+// enable ตั้งค่า 2FA ให้ผู้ใช้ คืน Secret และ Recovery Code
+func (f *twoFactorFixture) enable(t *testing.T) (string, []string) {
+	t.Helper()
+	setup, err := f.service.BeginSetup(f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err := f.service.Enable(f.user.ID, currentCode(t, setup.Secret, time.Now()), ports.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return setup.Secret, codes
+}
+
+func TestTOTPMatchesRFC6238(t *testing.T) {
+	// RFC 6238 Appendix B (SHA1): secret = "12345678901234567890" ตัดเหลือ 6 หลักท้าย
+	key := []byte("12345678901234567890")
+	secret := totpEncoding.EncodeToString(key)
+	for unix, want := range map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	} {
+		if got := totpCode(key, unix/totpPeriod); got != want {
+			t.Errorf("totpCode at %d = %s, want %s", unix, got, want)
+		}
+		if _, ok := verifyTOTP(secret, want, time.Unix(unix, 0)); !ok {
+			t.Errorf("verifyTOTP rejected the code at %d", unix)
+		}
+		// ยอมให้คลาดเคลื่อน 1 ช่วงเท่านั้น
+		if _, ok := verifyTOTP(secret, want, time.Unix(unix+2*totpPeriod, 0)); ok {
+			t.Errorf("verifyTOTP accepted a code two periods old at %d", unix)
+		}
+	}
+}
+
+func TestTwoFactorSetupAndDisable(t *testing.T) {
+	f := newTwoFactorFixture("")
+
+	setup, err := f.service.BeginSetup(f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.service.IsRequired(f.user) {
+		t.Fatal("2FA required before the first code was confirmed")
+	}
+	if _, err := f.service.Enable(f.user.ID, "000000", ports.ClientInfo{}); err == nil && currentCode(t, setup.Secret, time.Now()) != "000000" {
+		t.Fatal("Enable accepted a wrong code")
+	}
+
+	codes, err := f.service.Enable(f.user.ID, currentCode(t, setup.Secret, time.Now()), ports.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(codes), recoveryCodeCount)
+	}
+	status, _ := f.service.Status(f.user.ID)
+	if !status.Enabled || status.RecoveryCodesRemaining != recoveryCodeCount || !f.service.IsRequired(f.user) {
+		t.Fatalf("status after enable = %+v", status)
+	}
+
+	// ปิดด้วย Recovery Code (พิมพ์ตัวใหญ่และเว้นวรรคได้)
+	if err := f.service.Disable(f.user.ID, " "+codes[0]+" ", ports.ClientInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if f.service.IsRequired(f.user) {
+		t.Fatal("2FA still required after Disable")
+	}
+}
+
+func TestTwoFactorCodesCannotBeReplayed(t *testing.T) {
+	f := newTwoFactorFixture("")
+	secret, recovery := f.enable(t)
+
+	// รหัสที่ใช้ยืนยันการตั้งค่าไปแล้วใช้ซ้ำไม่ได้
+	code := currentCode(t, secret, time.Now())
+	if _, err := f.service.RegenerateRecoveryCodes(f.user.ID, code, ports.ClientInfo{}); !errors.Is(err, errInvalidTwoFactorCode) {
+		t.Fatalf("replayed TOTP code: got %v", err)
+	}
+
+	// Recovery Code ใช้ได้ครั้งเดียว
+	if _, err := f.service.RegenerateRecoveryCodes(f.user.ID, recovery[1], ports.ClientInfo{}); err != nil {
+		t.Fatalf("first use of a recovery code: %v", err)
+	}
+	if _, err := f.service.RegenerateRecoveryCodes(f.user.ID, recovery[1], ports.ClientInfo{}); !errors.Is(err, errInvalidTwoFactorCode) {
+		t.Fatalf("recovery code from the replaced set: got %v", err)
+	}
+}
+
+func TestTwoFactorChallenge(t *testing.T) {
+	f := newTwoFactorFixture("")
+	_, recovery := f.enable(t)
+
+	challenge, err := f.service.StartChallenge(f.user, ports.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if challenge.EnrollRequired {
+		t.Fatal("enrolment requested for a user who already has 2FA")
+	}
+
+	// รหัสผิด: นับเป็นการ Login ล้มเหลวของบัญชี
+	if _, _, err := f.service.CompleteChallenge(challenge.ChallengeToken, "12345678", ports.ClientInfo{}); !errors.Is(err, errInvalidTwoFactorCode) {
+		t.Fatalf("wrong code: got %v", err)
+	}
+	if len(f.throttle.failures) != 1 {
+		t.Fatalf("recorded %d throttle failures, want 1", len(f.throttle.failures))
+	}
+
+	user, _, err := f.service.CompleteChallenge(challenge.ChallengeToken, recovery[0], ports.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != f.user.ID {
+		t.Fatalf("challenge completed for user %d", user.ID)
+	}
+
+	// challenge ใช้ได้ครั้งเดียว
+	if _, _, err := f.service.CompleteChallenge(challenge.ChallengeToken, recovery[2], ports.ClientInfo{}); !errors.Is(err, errInvalidTwoFactorSession) {
+		t.Fatalf("reused challenge: got %v", err)
+	}
+}
+
+func TestTwoFactorChallengeAttemptLimit(t *testing.T) {
+	f := newTwoFactorFixture("")
+	_, recovery := f.enable(t)
+
+	challenge, _ := f.service.StartChallenge(f.user, ports.ClientInfo{})
+	for i := 0; i < twoFactorChallengeAttempts; i++ {
+		f.service.CompleteChallenge(challenge.ChallengeToken, "wrong-code", ports.ClientInfo{})
+	}
+	if _, _, err := f.service.CompleteChallenge(challenge.ChallengeToken, recovery[0], ports.ClientInfo{}); err == nil {
+		t.Fatal("challenge still accepted codes after the attempt limit")
+	}
+}
+
+func TestTwoFactorRequiredRole(t *testing.T) {
+	f := newTwoFactorFixture("admin, approver")
+	if !f.service.IsRequired(f.user) {
+		t.Fatal("2FA not required for a role listed in two_factor_required_roles")
+	}
+
+	// ยังไม่ได้ตั้งค่า: Login ต้องตั้งค่าให้เสร็จในขั้นตอน challenge
+	challenge, err := f.service.StartChallenge(f.user, ports.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !challenge.EnrollRequired {
+		t.Fatal("enrolment not requested")
+	}
+	setup, err := f.service.ChallengeSetup(challenge.ChallengeToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, codes, err := f.service.CompleteChallenge(challenge.ChallengeToken, currentCode(t, setup.Secret, time.Now()), ports.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes after enrolling during login", len(codes))
+	}
+
+	if err := f.service.Disable(f.user.ID, codes[0], ports.ClientInfo{}); err == nil {
+		t.Fatal("disabled 2FA for a role that requires it")
+	}
+}
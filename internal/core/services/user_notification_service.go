@@ -0,0 +1,52 @@
+package services
+
+import (
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+const maxNotificationPageSize = 100
+
+type userNotificationService struct {
+	repo ports.UserNotificationRepository
+}
+
+func NewUserNotificationService(repo ports.UserNotificationRepository) ports.UserNotificationService {
+	return &userNotificationService{repo: repo}
+}
+
+func (s *userNotificationService) GetNotifications(userID uint, unreadOnly bool, page, limit int) (*ports.UserNotificationPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > maxNotificationPageSize {
+		limit = 20
+	}
+
+	items, total, err := s.repo.ListByUser(userID, unreadOnly, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.repo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ports.UserNotificationPage{
+		Items:       items,
+		Total:       total,
+		UnreadCount: unread,
+		Page:        page,
+		Limit:       limit,
+	}, nil
+}
+
+// MarkRead: อ่านแล้ว (ถ้าอ่านไปแล้วหรือไม่ใช่ของตัวเองก็ไม่ error)
+func (s *userNotificationService) MarkRead(userID, id uint) error {
+	_, err := s.repo.MarkRead(userID, id)
+	return err
+}
+
+func (s *userNotificationService) MarkAllRead(userID uint) (int64, error) {
+	return s.repo.MarkAllRead(userID)
+}
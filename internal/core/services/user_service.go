@@ -13,12 +13,14 @@ import (
 type userService struct {
 	repo       ports.UserRepository
 	logService ports.LogService
+	tokens     ports.TokenService
 }
 
-func NewUserService(repo ports.UserRepository, logService ports.LogService) ports.UserService {
+func NewUserService(repo ports.UserRepository, logService ports.LogService, tokens ports.TokenService) ports.UserService {
 	return &userService{
 		repo:       repo,
 		logService: logService,
+		tokens:     tokens,
 	}
 }
 
@@ -64,30 +66,83 @@ func (s *userService) UpdateUser(id uint, input *domain.User) error {
 	existingUser.FullName = input.FullName
 	existingUser.Department = input.Department
 	existingUser.Tel = input.Tel
+	// Telegram / LINE ID เป็นข้อมูลส่วนตัว ผู้ใช้ตั้งเองที่ PUT /api/me
+	if input.Language != "" {
+		existingUser.Language = input.Language
+	}
 	existingUser.Email = input.Email
+	// เปลี่ยน role หรือรหัสผ่าน ต้องเพิกถอน Token เดิม (role ใน Token จะไม่ตรงกับของจริง)
+	revokeTokens := existingUser.Role != input.Role
 	existingUser.Role = input.Role // ใช้สำหรับเลื่อนขั้นเป็น admin
 
 	// ถ้ามีการส่ง Password มาใหม่ (ไม่ว่าง) ให้ Hash และเปลี่ยนใหม่
 	// ถ้าส่งมาว่าง แปลว่าไม่ต้องการเปลี่ยนรหัส
 	if input.Password != "" {
+		if !existingUser.IsLocal() {
+			return errors.New("password is managed by the directory and cannot be changed here")
+		}
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 		if err != nil {
 			return err
 		}
 		existingUser.Password = string(hashedPassword)
+		revokeTokens = true
 	}
 
 	if err := s.repo.Update(existingUser); err != nil {
 		return err
 	}
 
+	if revokeTokens {
+		if err := s.tokens.RevokeUserTokens(id); err != nil {
+			return err
+		}
+	}
+
 	// Log
 	go s.logService.LogAction(0, "UPDATE_USER", fmt.Sprintf("Updated user ID: %d", id), "", "")
-	
+
+	return nil
+}
+
+func (s *userService) UpdateStatus(id uint, status string, actorID uint) error {
+	if status != domain.UserStatusActive && status != domain.UserStatusDisabled {
+		return errors.New("status must be active or disabled")
+	}
+
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if id == actorID && status == domain.UserStatusDisabled {
+		return errors.New("you cannot disable your own account")
+	}
+
+	previous := user.Status
+	user.Status = status
+	if err := s.repo.Update(user); err != nil {
+		return err
+	}
+
+	// ระงับบัญชี: ให้ออกจากระบบทุกเครื่องทันที
+	if status == domain.UserStatusDisabled {
+		if err := s.tokens.RevokeUserTokens(id); err != nil {
+			return err
+		}
+	}
+
+	action := "ACTIVATE_USER"
+	if status == domain.UserStatusDisabled {
+		action = "DISABLE_USER"
+	}
+	go s.logService.LogAction(actorID, action, fmt.Sprintf("User ID: %d status %s -> %s", id, previous, status), "", "")
 	return nil
 }
 
 func (s *userService) DeleteUser(id uint) error {
+	if err := s.tokens.RevokeUserTokens(id); err != nil {
+		return err
+	}
 	err := s.repo.Delete(id)
 	if err == nil {
 		go s.logService.LogAction(0, "DELETE_USER", fmt.Sprintf("Deleted user ID: %d", id), "", "")
@@ -130,4 +185,4 @@ func (s *userService) InitializeDefaultAdmin() error {
 
 	fmt.Printf("Seeding Default Admin User: %s\n", adminUser.Username)
 	return s.CreateUser(adminUser)
-}
\ No newline at end of file
+}
@@ -0,0 +1,474 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+const (
+	webhookPollInterval       = 10 * time.Second
+	webhookBatchSize          = 20
+	webhookLease              = 2 * time.Minute
+	webhookTimeout            = 10 * time.Second
+	webhookMaxResponseBody    = 1024
+	defaultWebhookMaxAttempts = 8
+)
+
+// Header ที่แนบไปกับทุกคำขอ
+// ลายเซ็น = hex(HMAC-SHA256(secret, "<timestamp>.<body>")) ผู้รับควรตรวจ timestamp ไม่ให้เก่าเกินไปด้วย
+const (
+	webhookHeaderEvent     = "X-BRMS-Event"
+	webhookHeaderDelivery  = "X-BRMS-Delivery"
+	webhookHeaderTimestamp = "X-BRMS-Timestamp"
+	webhookHeaderSignature = "X-BRMS-Signature"
+)
+
+type webhookService struct {
+	repo       ports.WebhookRepository
+	settings   ports.SettingService
+	logService ports.LogService
+	client     *http.Client
+	box        *secretBox // nil = ไม่ได้ตั้ง SETTINGS_ENCRYPTION_KEY (สร้าง/หมุน secret ไม่ได้)
+}
+
+// NewWebhookService: secret ของปลายทางเข้ารหัสด้วยกุญแจเดียวกับค่าตั้งค่าลับ (SETTINGS_ENCRYPTION_KEY)
+func NewWebhookService(repo ports.WebhookRepository, settings ports.SettingService, logService ports.LogService, encryptionKey string) ports.WebhookService {
+	box, _ := newSecretBox(encryptionKey) // ไม่มีกุญแจ: NewSettingService เตือนไว้แล้วตอนเริ่มระบบ
+	return &webhookService{
+		repo:       repo,
+		settings:   settings,
+		logService: logService,
+		client:     &http.Client{Timeout: webhookTimeout},
+		box:        box,
+	}
+}
+
+func (s *webhookService) GetWebhooks() ([]domain.Webhook, error) {
+	webhooks, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i].HasSecret = webhooks[i].Secret != ""
+	}
+	return webhooks, nil
+}
+
+func (s *webhookService) GetWebhook(id uint) (*domain.Webhook, error) {
+	webhook, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	webhook.HasSecret = webhook.Secret != ""
+	return webhook, nil
+}
+
+// CreateWebhook: ถ้าไม่ได้กำหนด secret มา ระบบจะสุ่มให้ (Handler ส่งกลับในผลลัพธ์ครั้งเดียว)
+func (s *webhookService) CreateWebhook(webhook *domain.Webhook, actorID uint) error {
+	if err := validateWebhook(webhook); err != nil {
+		return err
+	}
+	if webhook.Secret == "" {
+		secret, err := randomHex(32)
+		if err != nil {
+			return err
+		}
+		webhook.Secret = secret
+	}
+	secret := webhook.Secret
+	sealed, err := s.sealSecret(secret)
+	if err != nil {
+		return err
+	}
+
+	webhook.ID = 0
+	webhook.Secret = sealed
+	err = s.repo.Create(webhook)
+	webhook.Secret = secret // Handler ส่งกลับให้ผู้ดูแลครั้งเดียว
+	if err != nil {
+		return err
+	}
+	webhook.HasSecret = true
+
+	go s.logService.LogAction(actorID, "CREATE_WEBHOOK", fmt.Sprintf("Created webhook: %s", webhook.Name), "", "")
+	return nil
+}
+
+// UpdateWebhook: secret ว่าง = ใช้ค่าเดิม
+func (s *webhookService) UpdateWebhook(id uint, input *domain.Webhook, actorID uint) error {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("webhook not found")
+	}
+
+	existing.Name = input.Name
+	existing.URL = input.URL
+	existing.Events = input.Events
+	existing.IsActive = input.IsActive
+	existing.Description = input.Description
+	if err := validateWebhook(existing); err != nil {
+		return err
+	}
+	if input.Secret != "" {
+		if existing.Secret, err = s.sealSecret(input.Secret); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.Update(existing); err != nil {
+		return err
+	}
+
+	go s.logService.LogAction(actorID, "UPDATE_WEBHOOK", fmt.Sprintf("Updated webhook ID: %d", id), "", "")
+	return nil
+}
+
+func (s *webhookService) RotateSecret(id uint, actorID uint) (string, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return "", errors.New("webhook not found")
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+	if existing.Secret, err = s.sealSecret(secret); err != nil {
+		return "", err
+	}
+	if err := s.repo.Update(existing); err != nil {
+		return "", err
+	}
+
+	go s.logService.LogAction(actorID, "ROTATE_WEBHOOK_SECRET", fmt.Sprintf("Rotated secret of webhook ID: %d", id), "", "")
+	return secret, nil
+}
+
+func (s *webhookService) DeleteWebhook(id uint, actorID uint) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return errors.New("webhook not found")
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	go s.logService.LogAction(actorID, "DELETE_WEBHOOK", fmt.Sprintf("Deleted webhook ID: %d", id), "", "")
+	return nil
+}
+
+func (s *webhookService) GetDeliveries(webhookID uint, status string, limit int) ([]domain.WebhookDelivery, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	return s.repo.ListDeliveries(webhookID, status, limit)
+}
+
+// RetryDelivery: ส่งใหม่โดยเริ่มนับจำนวนครั้งใหม่ (ใช้ event id เดิม ผู้รับจะรู้ว่าเป็นเหตุการณ์เดียวกัน)
+func (s *webhookService) RetryDelivery(id uint, actorID uint) error {
+	delivery, err := s.repo.GetDelivery(id)
+	if err != nil {
+		return errors.New("delivery not found")
+	}
+	if delivery.Status == domain.OutboxStatusSent {
+		return errors.New("delivery was already sent")
+	}
+
+	delivery.Status = domain.OutboxStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		return err
+	}
+
+	go s.logService.LogAction(actorID, "RETRY_WEBHOOK", fmt.Sprintf("Retry webhook delivery ID: %d", id), "", "")
+	return nil
+}
+
+// Test: ส่งเหตุการณ์ทดสอบทันที (ไม่ retry) เพื่อให้ผู้ดูแลเห็นผลตอบกลับของปลายทาง
+func (s *webhookService) Test(id uint, actorID uint) (*domain.WebhookDelivery, error) {
+	webhook, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("webhook not found")
+	}
+
+	deliveries, err := s.newDeliveries([]domain.Webhook{*webhook}, domain.WebhookEventTest, map[string]interface{}{
+		"webhook_id": webhook.ID,
+		"message":    "This is a test event",
+	})
+	if err != nil {
+		return nil, err
+	}
+	delivery := &deliveries[0]
+	delivery.Status = domain.OutboxStatusDead // ไม่ให้ Dispatcher หยิบไปส่งซ้ำ
+	if err := s.repo.CreateDeliveries(deliveries); err != nil {
+		return nil, err
+	}
+
+	sendErr := s.send(webhook, delivery)
+	delivery.Attempts = 1
+	s.applyResult(delivery, sendErr, 1)
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		log.Printf("Webhook: failed to update delivery %d: %v\n", delivery.ID, err)
+	}
+
+	go s.logService.LogAction(actorID, "TEST_WEBHOOK", fmt.Sprintf("Test webhook ID: %d", id), "", "")
+	return delivery, nil
+}
+
+// Publish: สร้าง delivery ให้ทุก Webhook ที่สมัครรับเหตุการณ์นี้ (Dispatcher จะส่งต่อเอง)
+func (s *webhookService) Publish(eventType string, data interface{}) {
+	webhooks, err := s.repo.GetActive()
+	if err != nil {
+		log.Println("Webhook: failed to load subscriptions:", err)
+		return
+	}
+
+	var targets []domain.Webhook
+	for _, w := range webhooks {
+		if w.Subscribes(eventType) {
+			targets = append(targets, w)
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	deliveries, err := s.newDeliveries(targets, eventType, data)
+	if err != nil {
+		log.Println("Webhook: failed to build payload:", err)
+		return
+	}
+	if err := s.repo.CreateDeliveries(deliveries); err != nil {
+		log.Println("Webhook: failed to queue deliveries:", err)
+	}
+}
+
+// newDeliveries: ทุกปลายทางได้ event id และ payload เดียวกัน
+func (s *webhookService) newDeliveries(webhooks []domain.Webhook, eventType string, data interface{}) ([]domain.WebhookDelivery, error) {
+	eventID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	body, err := json.Marshal(domain.WebhookEnvelope{ID: eventID, Event: eventType, CreatedAt: now, Data: data})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]domain.WebhookDelivery, 0, len(webhooks))
+	for _, w := range webhooks {
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			WebhookID:     w.ID,
+			EventID:       eventID,
+			EventType:     eventType,
+			Payload:       string(body),
+			Status:        domain.OutboxStatusPending,
+			NextAttemptAt: now,
+		})
+	}
+	return deliveries, nil
+}
+
+func (s *webhookService) Start(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.DispatchDue(); err != nil {
+			log.Println("Webhook dispatch error:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *webhookService) DispatchDue() (int, error) {
+	deliveries, err := s.repo.ClaimDueDeliveries(time.Now(), webhookBatchSize, webhookLease)
+	if err != nil {
+		return 0, err
+	}
+
+	maxAttempts := s.maxAttempts()
+	sent := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		webhook, err := s.repo.GetByID(delivery.WebhookID)
+		if err != nil || !webhook.IsActive {
+			// ปลายทางถูกลบหรือปิดไปแล้ว
+			delivery.Status = domain.OutboxStatusDead
+			delivery.LastError = "webhook is disabled or deleted"
+			s.repo.UpdateDelivery(delivery)
+			continue
+		}
+
+		delivery.Attempts++
+		sendErr := s.send(webhook, delivery)
+		s.applyResult(delivery, sendErr, maxAttempts)
+		if err := s.repo.UpdateDelivery(delivery); err != nil {
+			log.Printf("Webhook: failed to update delivery %d: %v\n", delivery.ID, err)
+		}
+		if sendErr == nil {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// send: POST payload พร้อมลายเซ็น แล้วเก็บผลตอบกลับไว้ใน delivery
+func (s *webhookService) send(webhook *domain.Webhook, delivery *domain.WebhookDelivery) error {
+	secret, err := s.openSecret(webhook)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TUNorth-BRMS-Webhook/1.0")
+	req.Header.Set(webhookHeaderEvent, delivery.EventType)
+	req.Header.Set(webhookHeaderDelivery, delivery.EventID)
+	req.Header.Set(webhookHeaderTimestamp, timestamp)
+	req.Header.Set(webhookHeaderSignature, "sha256="+signWebhook(secret, timestamp, delivery.Payload))
+
+	started := time.Now()
+	resp, err := s.client.Do(req)
+	delivery.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		delivery.ResponseStatus = 0
+		delivery.ResponseBody = ""
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxResponseBody))
+	delivery.ResponseStatus = resp.StatusCode
+	delivery.ResponseBody = string(body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// applyResult: ตั้งสถานะตามผลการส่ง ใช้ backoff เดียวกับ Outbox
+func (s *webhookService) applyResult(delivery *domain.WebhookDelivery, err error, maxAttempts int) {
+	now := time.Now()
+	if err == nil {
+		delivery.Status = domain.OutboxStatusSent
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	} else if delivery.Attempts >= maxAttempts {
+		delivery.Status = domain.OutboxStatusDead
+		delivery.LastError = err.Error()
+	} else {
+		delivery.Status = domain.OutboxStatusFailed
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(backoff(delivery.Attempts))
+	}
+}
+
+func (s *webhookService) maxAttempts() int {
+	n, err := strconv.Atoi(s.settings.GetSettingValue("webhook_max_attempts"))
+	if err != nil || n <= 0 {
+		return defaultWebhookMaxAttempts
+	}
+	return n
+}
+
+// SealStoredSecrets: secret ที่บันทึกก่อนมีการเข้ารหัสยังเป็นข้อความธรรมดา เข้ารหัสทับเมื่อมีกุญแจ
+func (s *webhookService) SealStoredSecrets() error {
+	if s.box == nil {
+		return nil
+	}
+	webhooks, err := s.repo.GetAll()
+	if err != nil {
+		return err
+	}
+	for i := range webhooks {
+		if webhooks[i].Secret == "" || isEncrypted(webhooks[i].Secret) {
+			continue
+		}
+		if webhooks[i].Secret, err = s.box.seal(webhooks[i].Secret); err != nil {
+			return err
+		}
+		if err := s.repo.Update(&webhooks[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sealSecret: ไม่มีกุญแจ = ปฏิเสธ (ไม่เก็บ secret เป็นข้อความธรรมดา)
+func (s *webhookService) sealSecret(secret string) (string, error) {
+	if s.box == nil {
+		return "", ports.ErrWebhookEncryptionKeyMissing
+	}
+	return s.box.seal(secret)
+}
+
+// openSecret ถอดรหัสตอนส่งเท่านั้น (ค่าเก่าที่ยังไม่เข้ารหัสใช้ได้ตามเดิม)
+func (s *webhookService) openSecret(webhook *domain.Webhook) (string, error) {
+	if !isEncrypted(webhook.Secret) {
+		return webhook.Secret, nil
+	}
+	if s.box == nil {
+		return "", errors.New("webhook secret is encrypted but SETTINGS_ENCRYPTION_KEY is not set")
+	}
+	return s.box.open(webhook.Secret)
+}
+
+func signWebhook(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func validateWebhook(webhook *domain.Webhook) error {
+	if webhook.Name == "" {
+		return errors.New("name is required")
+	}
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be a valid http(s) URL")
+	}
+	if len(webhook.Events) == 0 {
+		return errors.New("at least one event is required")
+	}
+	for _, e := range webhook.Events {
+		if e != domain.WebhookEventAll && !contains(domain.WebhookEvents, e) {
+			return fmt.Errorf("unknown event type: %s", e)
+		}
+	}
+	return nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
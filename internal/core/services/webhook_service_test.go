@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"tunorth-brms-backend/internal/core/domain"
+	"tunorth-brms-backend/internal/core/ports"
+)
+
+// fakeWebhookRepo เก็บ Webhook ในหน่วยความจำ (เมธอดที่ไม่ได้ใช้มาจาก interface ที่ฝังไว้)
+type fakeWebhookRepo struct {
+	ports.WebhookRepository
+	mu         sync.Mutex
+	webhooks   map[uint]domain.Webhook
+	deliveries []domain.WebhookDelivery
+}
+
+func newFakeWebhookRepo(webhooks ...domain.Webhook) *fakeWebhookRepo {
+	repo := &fakeWebhookRepo{webhooks: map[uint]domain.Webhook{}}
+	for _, w := range webhooks {
+		_ = repo.Create(&w)
+	}
+	return repo
+}
+
+func (r *fakeWebhookRepo) GetAll() ([]domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var webhooks []domain.Webhook
+	for _, w := range r.webhooks {
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, nil
+}
+
+func (r *fakeWebhookRepo) GetByID(id uint) (*domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w, ok := r.webhooks[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &w, nil
+}
+
+func (r *fakeWebhookRepo) Create(webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhook.ID = uint(len(r.webhooks) + 1)
+	r.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+func (r *fakeWebhookRepo) Update(webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+func (r *fakeWebhookRepo) stored(id uint) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.webhooks[id].Secret
+}
+
+func (r *fakeWebhookRepo) CreateDeliveries(deliveries []domain.WebhookDelivery) error {
+	r.deliveries = append(r.deliveries, deliveries...)
+	return nil
+}
+
+func (r *fakeWebhookRepo) UpdateDelivery(delivery *domain.WebhookDelivery) error { return nil }
+
+func newWebhookFixture(encryptionKey string, webhooks ...domain.Webhook) (ports.WebhookService, *fakeWebhookRepo) {
+	repo := newFakeWebhookRepo(webhooks...)
+	settings := NewSettingService(newFakeSettingRepo(), fakeLogService{}, nil, "")
+	return NewWebhookService(repo, settings, fakeLogService{}, encryptionKey), repo
+}
+
+// signatureServer ปลายทางที่ตอบ 200 เมื่อลายเซ็นตรงกับ secret ที่ผู้ดูแลได้รับ
+func signatureServer(t *testing.T, secret string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		want := "sha256=" + signWebhook(secret, r.Header.Get(webhookHeaderTimestamp), string(body))
+		if r.Header.Get(webhookHeaderSignature) != want {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebhookSecretStoredEncrypted(t *testing.T) {
+	service, repo := newWebhookFixture("test-key")
+
+	webhook := &domain.Webhook{Name: "ERP", URL: "https://erp.example.com/hook", Events: []string{domain.WebhookEventAll}, IsActive: true}
+	if err := service.CreateWebhook(webhook, 1); err != nil {
+		t.Fatal(err)
+	}
+	if webhook.Secret == "" || isEncrypted(webhook.Secret) {
+		t.Fatalf("create returned secret %q, want the plain secret", webhook.Secret)
+	}
+	stored := repo.stored(webhook.ID)
+	if !isEncrypted(stored) || strings.Contains(stored, webhook.Secret) {
+		t.Fatalf("stored secret = %q, want it encrypted", stored)
+	}
+
+	rotated, err := service.RotateSecret(webhook.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored := repo.stored(webhook.ID); !isEncrypted(stored) || strings.Contains(stored, rotated) {
+		t.Fatalf("stored secret after rotate = %q, want it encrypted", stored)
+	}
+
+	if err := service.UpdateWebhook(webhook.ID, &domain.Webhook{Name: "ERP", URL: "https://erp.example.com/hook", Events: []string{domain.WebhookEventAll}, IsActive: true, Secret: "chosen-secret"}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if stored := repo.stored(webhook.ID); !isEncrypted(stored) || strings.Contains(stored, "chosen-secret") {
+		t.Fatalf("stored secret after update = %q, want it encrypted", stored)
+	}
+}
+
+func TestWebhookSignsWithDecryptedSecret(t *testing.T) {
+	const secret = "shared-with-receiver"
+	service, repo := newWebhookFixture("test-key")
+	server := signatureServer(t, secret)
+
+	webhook := &domain.Webhook{Name: "ERP", URL: server.URL, Events: []string{domain.WebhookEventAll}, IsActive: true, Secret: secret}
+	if err := service.CreateWebhook(webhook, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !isEncrypted(repo.stored(webhook.ID)) {
+		t.Fatal("secret was not encrypted")
+	}
+
+	delivery, err := service.Test(webhook.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.ResponseStatus != http.StatusOK {
+		t.Fatalf("receiver answered %d (%s), want the signature to verify", delivery.ResponseStatus, delivery.LastError)
+	}
+}
+
+func TestWebhookSecretRequiresEncryptionKey(t *testing.T) {
+	service, repo := newWebhookFixture("")
+
+	err := service.CreateWebhook(&domain.Webhook{Name: "ERP", URL: "https://erp.example.com/hook", Events: []string{domain.WebhookEventAll}}, 1)
+	if !errors.Is(err, ports.ErrWebhookEncryptionKeyMissing) {
+		t.Fatalf("err = %v, want ErrWebhookEncryptionKeyMissing", err)
+	}
+	if webhooks, _ := repo.GetAll(); len(webhooks) != 0 {
+		t.Fatalf("stored %d webhooks without an encryption key", len(webhooks))
+	}
+}
+
+func TestSealStoredWebhookSecrets(t *testing.T) {
+	const secret = "legacy-plain-secret"
+	server := signatureServer(t, secret)
+	service, repo := newWebhookFixture("test-key", domain.Webhook{Name: "Legacy", URL: server.URL, Events: []string{domain.WebhookEventAll}, IsActive: true, Secret: secret})
+
+	// ค่าเก่าที่ยังไม่เข้ารหัสยังส่งได้
+	if delivery, err := service.Test(1, 1); err != nil || delivery.ResponseStatus != http.StatusOK {
+		t.Fatalf("legacy secret: delivery = %+v, err = %v", delivery, err)
+	}
+
+	if err := service.SealStoredSecrets(); err != nil {
+		t.Fatal(err)
+	}
+	if stored := repo.stored(1); !isEncrypted(stored) {
+		t.Fatalf("stored secret = %q, want it encrypted", stored)
+	}
+	if delivery, err := service.Test(1, 1); err != nil || delivery.ResponseStatus != http.StatusOK {
+		t.Fatalf("sealed secret: delivery = %+v, err = %v", delivery, err)
+	}
+}
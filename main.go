@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"strings"
 	"tunorth-brms-backend/internal/adapters/handlers/http"
 	"tunorth-brms-backend/internal/adapters/storage"
 	"tunorth-brms-backend/internal/core/domain"
@@ -39,20 +41,39 @@ func main() {
 	logService := services.NewLogService(logRepo)
 	logHandler := http.NewLogHandler(logService)
 
-	roomRepo := storage.NewRoomRepository(database.DB)
-	roomService := services.NewRoomService(roomRepo, logService)
-	roomHandler := http.NewRoomHandler(roomService)
-
 	// Settings (Admin) - Move up because injection is needed
 	settingRepo := storage.NewSettingRepository(database.DB)
-	settingService := services.NewSettingService(settingRepo, logService)
-	settingHandler := http.NewSettingHandler(settingService)
+	settingChangeBus := storage.NewSettingChangeBus(database.DB, database.DSN)
+	settingService := services.NewSettingService(settingRepo, logService, settingChangeBus, os.Getenv("SETTINGS_ENCRYPTION_KEY"))
+	cloudinaryProvider := storage.NewCloudinaryProvider(settingService)
+	settingHandler := http.NewSettingHandler(settingService, cloudinaryProvider)
 
 	// Auth (Move up for injection)
 	userRepo := storage.NewUserRepository(database.DB)
+	refreshTokenRepo := storage.NewRefreshTokenRepository(database.DB)
+	sessionRepo := storage.NewSessionRepository(database.DB)
+	tokenService := services.NewTokenService(refreshTokenRepo, sessionRepo, userRepo, settingService, logService)
+	sessionService := services.NewSessionService(sessionRepo, tokenService, userRepo, logService)
+	sessionHandler := http.NewSessionHandler(sessionService)
+	impersonationService := services.NewImpersonationService(tokenService, sessionRepo, userRepo, settingService, logService)
+	impersonationHandler := http.NewImpersonationHandler(impersonationService)
+
+	// Webhooks (ส่งเหตุการณ์ออกไปยังระบบอื่น) - ต้องสร้างก่อน Room/Booking
+	webhookRepo := storage.NewWebhookRepository(database.DB)
+	webhookService := services.NewWebhookService(webhookRepo, settingService, logService, os.Getenv("SETTINGS_ENCRYPTION_KEY"))
+	webhookHandler := http.NewWebhookHandler(webhookService)
+
+	// Live updates (Server-Sent Events) สำหรับปฏิทินและหน้าอนุมัติ
+	eventStreamService := services.NewEventStreamService()
+	eventStreamHandler := http.NewEventStreamHandler(eventStreamService, tokenService)
+	eventPublisher := services.NewMultiPublisher(webhookService, eventStreamService)
+
+	roomRepo := storage.NewRoomRepository(database.DB)
+	roomService := services.NewRoomService(roomRepo, logService, eventPublisher)
+	roomHandler := http.NewRoomHandler(roomService)
 
 	// User Management
-	userService := services.NewUserService(userRepo, logService)
+	userService := services.NewUserService(userRepo, logService, tokenService)
 	userHandler := http.NewUserHandler(userService)
 
 	// Resource
@@ -65,28 +86,103 @@ func main() {
 	reportService := services.NewReportService(reportRepo)
 	reportHandler := http.NewReportHandler(reportService)
 
-	// Notification
-	notifService := services.NewNotificationService(settingService, roomRepo, userRepo)
-
 	// --- Bookings (เพิ่มส่วนนี้) ---
 	bookingRepo := storage.NewBookingRepository(database.DB)
-	bookingService := services.NewBookingService(bookingRepo, roomRepo, settingService, userRepo, notifService, logService)
-	bookingHandler := http.NewBookingHandler(bookingService, settingService)
+	bookingService := services.NewBookingService(bookingRepo, roomRepo, settingService, userRepo, logService, eventPublisher)
+	bookingHandler := http.NewBookingHandler(bookingService, cloudinaryProvider)
+	bookingAttendeeRepo := storage.NewBookingAttendeeRepository(database.DB)
+	bookingAttendeeService := services.NewBookingAttendeeService(bookingAttendeeRepo, bookingRepo, userRepo, logService)
+	bookingAttendeeHandler := http.NewBookingAttendeeHandler(bookingAttendeeService)
+
+	// Notification (ส่งผ่าน Outbox + Dispatcher เบื้องหลัง)
+	userNotifRepo := storage.NewUserNotificationRepository(database.DB)
+	userNotifService := services.NewUserNotificationService(userNotifRepo)
+	userNotifHandler := http.NewUserNotificationHandler(userNotifService)
+	notifPrefRepo := storage.NewNotificationPreferenceRepository(database.DB)
+	notifPrefService := services.NewNotificationPreferenceService(notifPrefRepo)
+	notifPrefHandler := http.NewNotificationPreferenceHandler(notifPrefService)
+	notifTemplateRepo := storage.NewNotificationTemplateRepository(database.DB)
+	notifTemplateService := services.NewNotificationTemplateService(notifTemplateRepo, settingService, logService)
+	notifTemplateHandler := http.NewNotificationTemplateHandler(notifTemplateService)
+	chatWebhookRepo := storage.NewChatWebhookRepository(database.DB)
+	chatWebhookService := services.NewChatWebhookService(chatWebhookRepo, settingService, logService, os.Getenv("SETTINGS_ENCRYPTION_KEY"))
+	chatWebhookHandler := http.NewChatWebhookHandler(chatWebhookService)
+	outboxRepo := storage.NewOutboxRepository(database.DB)
+	notifService := services.NewNotificationService(settingService, roomRepo, userRepo, bookingRepo, userNotifRepo, outboxRepo, notifPrefService, notifTemplateService, chatWebhookService)
+	passwordResetRepo := storage.NewPasswordResetRepository(database.DB)
+	passwordResetService := services.NewPasswordResetService(passwordResetRepo, userRepo, notifService, tokenService, settingService, logService)
+	passwordResetHandler := http.NewPasswordResetHandler(passwordResetService)
+	outboxService := services.NewOutboxService(outboxRepo, notifService, settingService, logService)
+	outboxHandler := http.NewOutboxHandler(outboxService)
+	reminderService := services.NewReminderService(bookingRepo, bookingAttendeeRepo, outboxRepo, settingService)
+	digestService := services.NewDigestService(userRepo, outboxRepo, settingService)
 
 	// Auth Service
-	authService := services.NewAuthService(userRepo)
-	authHandler := http.NewAuthHandler(authService, logService, settingService)
+	emailVerificationRepo := storage.NewEmailVerificationRepository(database.DB)
+	emailVerificationService := services.NewEmailVerificationService(emailVerificationRepo, userRepo, notifService, settingService, logService)
+	emailVerificationHandler := http.NewEmailVerificationHandler(emailVerificationService)
+	ldapAuthenticator := services.NewLDAPAuthenticator(settingService)
+	directoryHandler := http.NewDirectoryHandler(ldapAuthenticator)
+	apiKeyRepo := storage.NewAPIKeyRepository(database.DB)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userRepo, settingService, logService)
+	apiKeyHandler := http.NewAPIKeyHandler(apiKeyService)
+	loginThrottleRepo := storage.NewLoginThrottleRepository(database.DB)
+	loginThrottleService := services.NewLoginThrottleService(loginThrottleRepo, settingService, logService)
+	loginThrottleHandler := http.NewLoginThrottleHandler(loginThrottleService)
+	twoFactorRepo := storage.NewTwoFactorRepository(database.DB)
+	twoFactorService := services.NewTwoFactorService(twoFactorRepo, userRepo, settingService, loginThrottleService, logService)
+	twoFactorHandler := http.NewTwoFactorHandler(twoFactorService, tokenService, logService)
+	oidcLoginRepo := storage.NewOIDCLoginRepository(database.DB)
+	oidcService := services.NewOIDCService(oidcLoginRepo, userRepo, tokenService, settingService, twoFactorService, loginThrottleService, logService)
+	oidcHandler := http.NewOIDCHandler(oidcService, logService, settingService)
+	authService := services.NewAuthService(userRepo, tokenService, settingService, emailVerificationService, ldapAuthenticator, twoFactorService, loginThrottleService, logService)
+	authHandler := http.NewAuthHandler(authService, tokenService, logService, settingService, impersonationService)
+
+	// Config Bundle (ส่งออก/นำเข้าค่าตั้งค่า ห้อง และอุปกรณ์ ระหว่าง instance)
+	configBundleRepo := storage.NewConfigBundleRepository(database.DB)
+	configBundleService := services.NewConfigBundleService(configBundleRepo, settingService, roomRepo, resRepo, logService, eventPublisher)
+	configBundleHandler := http.NewConfigBundleHandler(configBundleService)
 
 	// Auto-Migrate & Initialize Defaults
-	database.DB.AutoMigrate(&domain.Setting{}, &domain.Booking{}, &domain.Log{})
+	database.DB.AutoMigrate(&domain.Setting{}, &domain.Booking{}, &domain.Log{}, &domain.OutboxMessage{}, &domain.UserNotification{}, &domain.NotificationPreference{}, &domain.NotificationQuietHours{}, &domain.NotificationTemplate{}, &domain.Webhook{}, &domain.WebhookDelivery{}, &domain.ChatWebhook{}, &domain.RefreshToken{}, &domain.PasswordResetToken{}, &domain.EmailVerificationToken{}, &domain.OIDCLogin{}, &domain.UserTwoFactor{}, &domain.TwoFactorRecoveryCode{}, &domain.TwoFactorChallenge{}, &domain.LoginThrottle{}, &domain.APIKey{}, &domain.Session{}, &domain.SettingChangeSet{}, &domain.SettingChange{}, &domain.BookingAttendee{})
 	settingService.InitializeDefaults()
+	notifTemplateService.InitializeDefaults()
 	userService.InitializeDefaultAdmin()
+	if err := webhookService.SealStoredSecrets(); err != nil {
+		log.Println("Warning: failed to encrypt stored webhook secrets:", err)
+	}
+	if err := chatWebhookService.SealStoredURLs(); err != nil {
+		log.Println("Warning: failed to encrypt stored chat webhook URLs:", err)
+	}
+
+	// Background Workers
+	go settingService.Start(context.Background())
+	go outboxService.Start(context.Background())
+	go reminderService.Start(context.Background())
+	go digestService.Start(context.Background())
+	go webhookService.Start(context.Background())
 
 	// 4. Setup Fiber App
-	app := fiber.New(fiber.Config{
+	fiberConfig := fiber.Config{
 		// เพิ่มขีดจำกัดขนาดไฟล์เป็น 20 MB (หรือตามต้องการ)
 		BodyLimit: 20 * 1024 * 1024,
-	})
+	}
+	// อยู่หลัง Reverse Proxy (เช่น Render): ใช้ IP จาก Header เฉพาะ Request ที่มาจาก Proxy ที่ระบุใน TRUSTED_PROXIES
+	// ไม่งั้น c.IP() เป็น IP ของ Proxy สำหรับทุกคน การล็อกตาม IP และ limiter จะนับผู้ใช้ทั้งหมดรวมกัน
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		fiberConfig.ProxyHeader = fiber.HeaderXForwardedFor
+		if header := os.Getenv("PROXY_HEADER"); header != "" {
+			fiberConfig.ProxyHeader = header
+		}
+		fiberConfig.EnableTrustedProxyCheck = true
+		fiberConfig.EnableIPValidation = true
+		for _, proxy := range strings.Split(proxies, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy != "" {
+				fiberConfig.TrustedProxies = append(fiberConfig.TrustedProxies, proxy)
+			}
+		}
+	}
+	app := fiber.New(fiberConfig)
 
 	// Middleware: Logger (ดู log การยิง api) & CORS (ให้ frontend เรียกได้)
 	app.Use(logger.New())
@@ -98,67 +194,40 @@ func main() {
 	// 5. Routes Definition
 	api := app.Group("/api") // จัดกลุ่ม path ขึ้นต้นด้วย /api
 
-	// Public Settings (ไม่ต้อง Login ก็ได้ จะได้โหลด Logo ได้)
-	api.Get("/settings/public", settingHandler.GetPublicSettings)
-
 	// Middleware JWT - Init here to use in routes below
-	jwtMiddleware := jwtware.New(jwtware.Config{
-		SigningKey: jwtware.SigningKey{Key: []byte(os.Getenv("JWT_SECRET"))},
-	})
-
-	// Room Routes
-	rooms := api.Group("/rooms")
-	rooms.Post("/", roomHandler.CreateRoom)      // สร้างห้อง
-	rooms.Get("/", roomHandler.GetAllRooms)      // ดูห้องทั้งหมด
-	rooms.Get("/:id", roomHandler.GetRoom)       // ดูห้องรายตัว
-	rooms.Put("/:id", roomHandler.UpdateRoom)    // แก้ไขห้อง
-	rooms.Delete("/:id", roomHandler.DeleteRoom) // ลบห้อง
-
-	// Booking Routes
-	bookings := api.Group("/bookings")
-	bookings.Get("/", bookingHandler.GetBookings) // Public for Calendar View?
-	// Protected Booking Routes
-	bookings.Post("/", jwtMiddleware, bookingHandler.CreateBooking)
-	bookings.Patch("/:id/status", jwtMiddleware, bookingHandler.UpdateStatus)
-	bookings.Put("/:id", jwtMiddleware, bookingHandler.UpdateBooking)
-	bookings.Delete("/:id", jwtMiddleware, bookingHandler.DeleteBooking)
-
-	// Auth Routes
-	api.Post("/register", authHandler.Register)
-	api.Post("/login", authHandler.Login)
-
-	// Protected Routes (Already used jwtMiddleware inside)
-	api.Get("/me", jwtMiddleware, authHandler.GetMe)
-	api.Put("/me", jwtMiddleware, authHandler.UpdateMe)
-
-	// Settings Protected
-	api.Get("/settings", jwtMiddleware, settingHandler.GetAllSettings)
-	api.Put("/settings", jwtMiddleware, settingHandler.UpdateSettings)
-	api.Post("/settings/upload", jwtMiddleware, settingHandler.UploadImage)
-
-	// Example: Apply to other routes if needed
-	// bookings.Use(jwtMiddleware)
-
-	// User Routes
-	users := api.Group("/users")
-	users.Get("/", userHandler.GetAllUsers)
-	users.Put("/:id", userHandler.UpdateUser)
-	users.Delete("/:id", userHandler.DeleteUser)
-	users.Post("/import", userHandler.ImportUsers)
-
-	// Resource Routes
-	resources := api.Group("/resources")
-	resources.Get("/", resHandler.GetAllResources)
-	resources.Post("/", resHandler.CreateResource)
-	resources.Put("/:id", resHandler.UpdateResource)
-	resources.Delete("/:id", resHandler.DeleteResource)
-
-	// Report Routes
-	api.Get("/reports/dashboard", reportHandler.GetDashboardStats)
-
-	// Log Routes
-	api.Get("/logs", logHandler.GetLogs)
-	api.Post("/logs/test", logHandler.CreateTestLog) // For Testing
+	jwtMiddleware := http.ImpersonationAudit(logService, http.APIKeyAuth(apiKeyService, jwtware.New(jwtware.Config{
+		SigningKey:     jwtware.SigningKey{Key: []byte(os.Getenv("JWT_SECRET"))},
+		SuccessHandler: http.ActiveTokenHandler(tokenService), // ปฏิเสธ Token ที่ถูกเพิกถอนแล้ว
+	}))) // รับ API Key (X-API-Key / Bearer brms_...) แทน JWT ได้ จำกัดตาม scope ใน http.Authorize / บันทึกทุก Request ที่สวมสิทธิ์
+
+	http.RegisterRoutes(api, http.Handlers{
+		Setting:                settingHandler,
+		Room:                   roomHandler,
+		Booking:                bookingHandler,
+		BookingAttendee:        bookingAttendeeHandler,
+		EventStream:            eventStreamHandler,
+		Auth:                   authHandler,
+		OIDC:                   oidcHandler,
+		PasswordReset:          passwordResetHandler,
+		EmailVerification:      emailVerificationHandler,
+		TwoFactor:              twoFactorHandler,
+		Impersonation:          impersonationHandler,
+		Session:                sessionHandler,
+		APIKey:                 apiKeyHandler,
+		NotificationPreference: notifPrefHandler,
+		ConfigBundle:           configBundleHandler,
+		Directory:              directoryHandler,
+		User:                   userHandler,
+		LoginThrottle:          loginThrottleHandler,
+		Resource:               resHandler,
+		Report:                 reportHandler,
+		UserNotification:       userNotifHandler,
+		NotificationTemplate:   notifTemplateHandler,
+		Outbox:                 outboxHandler,
+		ChatWebhook:            chatWebhookHandler,
+		Webhook:                webhookHandler,
+		Log:                    logHandler,
+	}, jwtMiddleware) // รายการ Route ทั้งหมดอยู่ใน internal/adapters/handlers/http/routes.go
 
 	// Test Route
 	app.Get("/", func(c *fiber.Ctx) error {